@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	apikeysvc "github.com/jainabhishek5986/employee-records/pkg/services/apikey"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"github.com/spf13/cobra"
+)
+
+// APIKeyCommand will setup and return the command managing API keys in the
+// configured database
+func APIKeyCommand() *cobra.Command {
+	apiKeyCmd := cobra.Command{
+		Use:   "apikey",
+		Short: "Manage API keys of service-to-service callers",
+	}
+
+	createCmd := cobra.Command{
+		Use:   "create",
+		Short: "Create an API key, the key is only printed once",
+		Args:  cobra.NoArgs,
+		RunE:  runAPIKeyCreate,
+	}
+	createCmd.Flags().String("name", "", "name of the caller owning the key")
+	createCmd.Flags().StringSlice("scopes", []string{auth.ScopeEmployeeRead},
+		"scopes granted to the key: "+strings.Join(auth.Scopes, ", "))
+	createCmd.Flags().Duration("ttl", 0, "lifetime of the key, 0 never expires")
+	createCmd.Flags().String("tenant", "", "tenant of the key, empty uses Tenancy.DefaultTenant")
+
+	listCmd := cobra.Command{
+		Use:   "list",
+		Short: "List the API keys without their secrets",
+		Args:  cobra.NoArgs,
+		RunE:  runAPIKeyList,
+	}
+
+	revokeCmd := cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAPIKeyRevoke,
+	}
+
+	apiKeyCmd.AddCommand(&createCmd, &listCmd, &revokeCmd)
+	return &apiKeyCmd
+}
+
+// apiKeyService opens the configured database for the API key commands, it
+// fails when none is configured
+func apiKeyService(cmd *cobra.Command) (service.APIKeyService, error) {
+	cfg, err := config.Load(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := zaplogger.Configure(loggerOptions(cfg)); err != nil {
+		return nil, err
+	}
+	// the default in-memory database would vanish with the command
+	if cfg.DB.DSN == "" {
+		return nil, fmt.Errorf("DB.DSN is empty, set it to the database of the service")
+	}
+	tenant.Configure(cfg.Tenancy)
+	return apikeysvc.NewService(DBConnection(commandContext(cmd), cfg)), nil
+}
+
+func runAPIKeyCreate(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	scopes, _ := cmd.Flags().GetStringSlice("scopes")
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+	tenantID, _ := cmd.Flags().GetString("tenant")
+
+	request := global.DecodeAPIKeyPOSTRequest{Name: name, Scopes: scopes, TenantID: tenantID}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("--name is required")
+	}
+	for _, scope := range scopes {
+		if !auth.ValidScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		request.ExpiresAt = &expiresAt
+	}
+
+	svc, err := apiKeyService(cmd)
+	if err != nil {
+		return err
+	}
+	created, err := svc.CreateAPIKey(commandContext(cmd), request)
+	if err != nil {
+		return err
+	}
+	return printJSON(cmd, created)
+}
+
+func runAPIKeyList(cmd *cobra.Command, args []string) error {
+	svc, err := apiKeyService(cmd)
+	if err != nil {
+		return err
+	}
+	keys, err := svc.ListAPIKeys(commandContext(cmd))
+	if err != nil {
+		return err
+	}
+	return printJSON(cmd, keys)
+}
+
+func runAPIKeyRevoke(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid API key ID %q", args[0])
+	}
+
+	svc, err := apiKeyService(cmd)
+	if err != nil {
+		return err
+	}
+	if err := svc.RevokeAPIKey(commandContext(cmd), id); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), global.APIKeyRevokedSuccessfully)
+	return nil
+}
+
+// commandContext returns the context of the command, the commands manage
+// the keys of every tenant
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return tenant.AllTenants(ctx)
+	}
+	return tenant.AllTenants(context.Background())
+}
+
+func printJSON(cmd *cobra.Command, value interface{}) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ConfigCommand will setup and return the command checking the config
+func ConfigCommand() *cobra.Command {
+	configCmd := cobra.Command{
+		Use:   "config",
+		Short: "Inspect the service config",
+	}
+
+	validateCmd := cobra.Command{
+		Use:          "validate",
+		Short:        "Validate the config file, profile and environment without starting the service",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runConfigValidate,
+	}
+
+	configCmd.AddCommand(&validateCmd)
+	return &configCmd
+}
+
+/*
+runConfigValidate : loads the config like the service does and reports
+every invalid key
+
+Parameters
+---------
+cmd: Cobra command object
+*/
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cmd)
+	if err != nil {
+		return err
+	}
+
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		file = "defaults only"
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "config is valid (file: %s, profile: %s, env: %s)\n",
+		file, cfg.Profile, cfg.Env)
+	return nil
+}
+
+// logConfigProblems logs one line per invalid key of a config
+func logConfigProblems(err error) {
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		return
+	}
+	for _, problem := range validationErr.Problems {
+		zaplogger.Error(context.Background(), errs.InvalidConfigKeyError,
+			zap.String("key", problem.Key), zap.String("problem", problem.Message))
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/transport/http"
+	"github.com/spf13/cobra"
+)
+
+// OpenAPICommand will setup and return the command printing the OpenAPI
+// document generated from the route table
+func OpenAPICommand() *cobra.Command {
+	openAPICmd := cobra.Command{
+		Use:   "openapi",
+		Short: "Print the OpenAPI document of the API",
+		Args:  cobra.NoArgs,
+		RunE:  runOpenAPI,
+	}
+	openAPICmd.Flags().StringP("output", "o", "", "file to write the document to, defaults to stdout")
+
+	return &openAPICmd
+}
+
+/*
+runOpenAPI : generates the OpenAPI document and writes it to the output
+
+Parameters
+---------
+cmd: Cobra command object
+*/
+func runOpenAPI(cmd *cobra.Command, args []string) error {
+	// the debug lines of the routes would mix with the document on stdout
+	gin.SetMode(gin.ReleaseMode)
+	document, err := http.BuildOpenAPIDocument()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if output, _ := cmd.Flags().GetString("output"); output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
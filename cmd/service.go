@@ -2,22 +2,39 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
-	"time"
+	"syscall"
 
 	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/asyncjob"
+	"github.com/jainabhishek5986/employee-records/pkg/cache"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/features"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/jobs"
+	"github.com/jainabhishek5986/employee-records/pkg/lifecycle"
+	"github.com/jainabhishek5986/employee-records/pkg/metrics"
 	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/replica"
+	asyncjobrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/asyncjob"
+	jobrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/job"
+	"github.com/jainabhishek5986/employee-records/pkg/scheduler"
+	employeesvc "github.com/jainabhishek5986/employee-records/pkg/services/employee"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/tracing"
 	"github.com/jainabhishek5986/employee-records/pkg/transport/http"
+	"github.com/jainabhishek5986/employee-records/pkg/validation"
 	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
-	// use mysql connector
-	_ "github.com/go-sql-driver/mysql" // use mysql connector
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
@@ -37,31 +54,85 @@ func RootCommand() *cobra.Command {
 				flags`, zap.Error(err))
 	}
 
+	// sub commands
+	rootCmd.AddCommand(OpenAPICommand())
+	rootCmd.AddCommand(APIKeyCommand())
+	rootCmd.AddCommand(ConfigCommand())
+
 	return &rootCmd
 }
 
 /*
-run : This function start the process
+run : This function start the process and exits with the code of the
+lifecycle manager
 
 Parameters
 ---------
 cmd: Cobra command object
 */
 func run(cmd *cobra.Command, args []string) {
+	if code := serve(cmd); code != lifecycle.ExitOK {
+		os.Exit(code)
+	}
+}
+
+/*
+serve : sets up the components of the service and runs them until a
+shutdown signal, returning the exit code. The components are stopped in
+reverse order within Config.ShutdownTimeout - diagnostics server, API
+server, background workers and jobs, database, traces and logger.
+
+Parameters
+---------
+cmd: Cobra command object
+*/
+func serve(cmd *cobra.Command) int {
+	ctx := context.Background()
 
 	// getting Config object that have all the global
 	// configuration information
 	cfg, err := config.Load(cmd)
 	if err != nil {
-		zaplogger.Fatal(context.Background(), "Failed to load config", zap.Error(err))
+		logConfigProblems(err)
+		return startupFailure(ctx, errs.LoadConfigError, err)
+	}
+
+	// rebuild the logger from the loaded config
+	err = zaplogger.Configure(loggerOptions(cfg))
+	if err != nil {
+		return startupFailure(ctx, errs.InitiateLoggerError, err)
 	}
 
-	// create a context that we can cancel
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	manager := lifecycle.New(cfg.ShutdownTimeout)
+	// write the queued log lines before the process exits, added first so
+	// that it stops after everything else
+	manager.Append(lifecycle.Hook{
+		Name: "logger",
+		Stop: func(ctx context.Context) error {
+			return closeLogger()
+		},
+	})
+
+	// apply the configured employee validation rules
+	err = validation.SetRules(cfg.Validation)
+	if err != nil {
+		return startupFailure(ctx, "Invalid validation config", err)
+	}
+	features.Set(cfg.Features)
+	tenant.Configure(cfg.Tenancy)
+	err = cache.Configure(cfg.Cache)
+	if err != nil {
+		return startupFailure(ctx, "Invalid cache config", err)
+	}
+	subscribeReloads()
 
-	// timeout in seconds
-	const GracefulTimeout = 40000 * time.Millisecond
+	// set up tracing, a no-op unless enabled in config
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return startupFailure(ctx, "Unable to set up tracing", err)
+	}
+	// flush the pending spans
+	manager.Append(lifecycle.Hook{Name: "tracing", Stop: shutdownTracing})
 
 	// load environment variables from .env if available
 	err = godotenv.Load()
@@ -69,57 +140,162 @@ func run(cmd *cobra.Command, args []string) {
 		zaplogger.Warn(ctx, "Warning: Environment file not found")
 	}
 
-	db := DBConnection(ctx)
+	db, replicas, err := openDB(ctx, cfg)
+	if err != nil {
+		return startupFailure(ctx, "Unable to set up db", err)
+	}
+	manager.Append(lifecycle.Hook{
+		Name: "database",
+		Stop: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return errors.Join(replicas.Close(), sqlDB.Close())
+		},
+	})
 
-	waitgroup.Gwg.Add(1)
-	go func() {
-		defer waitgroup.Gwg.Done()
-		// setup http server
-		err = http.Setup(ctx, cfg, &waitgroup.Gwg, db)
-		if err != nil {
-			zaplogger.Error(ctx, "Something Went Wrong", zap.Error(err))
+	employeeService := employeesvc.NewService(db)
+
+	// background jobs of the catalog, scheduled by Jobs.Catalog
+	jobScheduler := scheduler.New(jobrepo.NewJobRepo(db), cfg.Jobs)
+	for _, job := range jobs.Catalog(db, cfg.Jobs, employeeService) {
+		if err := jobScheduler.Register(job); err != nil {
+			return startupFailure(ctx, "Invalid job catalog", err)
 		}
-	}()
-
-	// listen for C-c
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-
-	// create channel to mark status of waitgroup
-	// this is required to brutally kill application in case of
-	// timeout
-	done := make(chan struct{})
-
-	// asynchronously wait for all the go routines
-	go func() {
-		// and wait for all go routines
-		waitgroup.Gwg.Wait()
-		zaplogger.Debug(ctx, "main: all goroutines have finished.")
-		close(done)
-	}()
-
-	// wait for signal channel
-	select {
-	case <-c:
-
-		zaplogger.Debug(ctx, "main: received C-c - attempting graceful shutdown ....")
-		// tell the goroutines to stop
-		zaplogger.Debug(ctx, "main: telling goroutines to stop")
-		cancel()
+	}
+
+	// asynchronous jobs submitted through the API, run by a bounded pool
+	asyncJobPool := asyncjob.NewPool(asyncjobrepo.NewAsyncJobRepo(db), cfg.AsyncJobs)
+	// an import commits row by row, running it again creates the rows twice
+	asyncJobPool.Register(jobs.ImportEmployees, jobs.ImportEmployeesHandler(employeeService), false)
+	asyncJobPool.Register(jobs.ExportEmployees, jobs.ExportEmployeesHandler(employeeService), true)
+	asyncJobPool.Register(jobs.UpdateEmployees, jobs.UpdateEmployeesHandler(employeeService), true)
+
+	// goroutines of the process are tracked by the global wait group
+	manager.Append(lifecycle.Hook{
+		Name: "workers",
+		Start: func(ctx context.Context) error {
+			// apply runtime safe settings on config file changes and SIGHUP
+			config.Watch(ctx)
+			// reads fail over to the primary while no replica answers
+			replicas.Start(ctx)
+			waitgroup.Add(1)
+			go func() {
+				defer waitgroup.Done()
+				reloadOnSignal(ctx)
+			}()
+			// running jobs are cancelled with ctx and waited for below,
+			// interrupted asynchronous jobs go back to the queue
+			if err := asyncJobPool.Start(ctx); err != nil {
+				return err
+			}
+			return jobScheduler.Start(ctx)
+		},
+		Stop: func(ctx context.Context) error {
+			if !waitgroup.WaitWithContext(ctx, &waitgroup.Gwg) {
+				return ctx.Err()
+			}
+			return nil
+		},
+	})
+
+	apiServer, err := http.NewAPIServer(ctx, cfg, db, jobScheduler, asyncJobPool)
+	if err != nil {
+		return startupFailure(ctx, errs.APIServerStartError, err)
+	}
+	manager.Append(apiServer.Hook(manager.Fail))
+
+	if cfg.Diagnostics.Enabled {
+		manager.Append(http.NewDiagnosticsServer(cfg).Hook(manager.Fail))
+	}
+
+	code := manager.Run(ctx)
+	if code != lifecycle.ExitOK {
+		fmt.Fprintf(os.Stderr, "employee-records-service exited with code %d\n", code)
+	}
+	return code
+}
+
+// startupFailure logs why the service could not start and flushes the
+// logger
+func startupFailure(ctx context.Context, message string, err error) int {
+	zaplogger.Error(ctx, message, zap.Error(err))
+	if err := closeLogger(); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to flush logger: %v\n", err)
+	}
+	return lifecycle.ExitStartup
+}
+
+// closeLogger flushes the logger, stderr and stdout can not be synced on
+// every platform
+func closeLogger() error {
+	if err := zaplogger.Close(); err != nil && !errors.Is(err, syscall.EINVAL) {
+		return err
+	}
+	return nil
+}
+
+// subscribeReloads applies the reloadable settings owned by the process,
+// the API server subscribes its rate limits and CORS policies
+func subscribeReloads() {
+	config.Subscribe("LogConfig.ConsoleLevel", func(ctx context.Context, cfg *config.Config) error {
+		return zaplogger.SetLevel(zaplogger.OutputConsole, cfg.LogConfig.ConsoleLevel)
+	})
+	config.Subscribe("LogConfig.FileLevel", func(ctx context.Context, cfg *config.Config) error {
+		return zaplogger.SetLevel(zaplogger.OutputFile, cfg.LogConfig.FileLevel)
+	})
+	config.Subscribe("Validation", func(ctx context.Context, cfg *config.Config) error {
+		return validation.SetRules(cfg.Validation)
+	})
+	config.Subscribe("Features", func(ctx context.Context, cfg *config.Config) error {
+		features.Set(cfg.Features)
+		return nil
+	})
+	config.Subscribe("Tenancy.Tenants", func(ctx context.Context, cfg *config.Config) error {
+		tenant.Configure(cfg.Tenancy)
+		return nil
+	})
+}
+
+// reloadOnSignal reloads the config on every SIGHUP until ctx is done
+func reloadOnSignal(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
 		select {
-		case <-done:
-			zaplogger.Debug(ctx, "Go routines exited within timeout")
-		case <-time.After(GracefulTimeout):
-			zaplogger.Error(ctx, "Graceful timeout exceeded. Brutally killing the application")
+		case <-ctx.Done():
+			return
+		case <-hup:
+			// failures are logged by the reload and keep the current config
+			_ = config.Reload(ctx, config.ReloadSourceSignal)
 		}
+	}
+}
 
-	case <-done:
-		defer os.Exit(0)
+// loggerOptions maps the log config to the logger options
+func loggerOptions(cfg *config.Config) zaplogger.Options {
+	return zaplogger.Options{
+		EnableConsole:     cfg.LogConfig.EnableConsole,
+		ConsoleJSONFormat: cfg.LogConfig.ConsoleJSONFormat,
+		ConsoleLevel:      cfg.LogConfig.ConsoleLevel,
+		EnableFile:        cfg.LogConfig.EnableFile,
+		FileJSONFormat:    cfg.LogConfig.FileJSONFormat,
+		FileLevel:         cfg.LogConfig.FileLevel,
+		FileLocation:      cfg.LogConfig.FileLocation,
+		MaxSize:           cfg.LogConfig.MaxSize,
+		MaxAge:            cfg.LogConfig.MaxAge,
+		MaxBackups:        cfg.LogConfig.MaxBackups,
+		OverflowPolicy:    cfg.LogConfig.OverflowPolicy,
+		Development:       cfg.Env == global.DevEnv,
 	}
 }
 
 /*
-DBConnection: Making db connection using gorm
+DBConnection: Making db connection using gorm, panics if the database can
+not be set up
 
 Parameters
 -----------
@@ -130,15 +306,66 @@ Return
 ---------
 db: DB connection object
 */
-func DBConnection(ctx context.Context) (db *gorm.DB) {
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		zaplogger.Panic(ctx, "Unable to connect to db. Exiting", zap.Error(err))
-	}
-	err = db.AutoMigrate(models.Employee{})
+func DBConnection(ctx context.Context, cfg *config.Config) (db *gorm.DB) {
+	db, _, err := openDB(ctx, cfg)
 	if err != nil {
-		zaplogger.Panic(ctx, "Unable to run migrations to db. Exiting", zap.Error(err))
+		zaplogger.Panic(ctx, "Unable to set up db. Exiting", zap.Error(err))
 	}
 	return db
+}
+
+// dialectors open a DSN of the database driver named by DB.driver
+var dialectors = map[string]func(dsn string) gorm.Dialector{
+	"sqlite":   sqlite.Open,
+	"mysql":    mysql.Open,
+	"postgres": postgres.Open,
+}
 
+// openDB connects to the configured database and its replicas, migrates
+// and instruments it
+func openDB(ctx context.Context, cfg *config.Config) (*gorm.DB, *replica.Router, error) {
+	open, ok := dialectors[cfg.DB.Driver]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown database driver %q", cfg.DB.Driver)
+	}
+	dsn := cfg.DB.DSN
+	if dsn == "" {
+		dsn = "file::memory:?cache=shared"
+	}
+	db, err := gorm.Open(open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting: %w", err)
+	}
+	err = db.AutoMigrate(models.All()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("running migrations: %w", err)
+	}
+	// rows written before tenancy existed belong to the default tenant
+	err = tenant.Backfill(ctx, db, tenant.Default(), models.TenantModels()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("assigning tenants: %w", err)
+	}
+	// every query of a tenant table is scoped to the tenant of its context
+	err = tenant.RegisterGORM(db, models.TenantModels()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scoping tenants: %w", err)
+	}
+	// replicas are migrated by the replication of the primary
+	replicaDialectors := make([]gorm.Dialector, 0, len(cfg.DB.Replicas))
+	for _, replicaDSN := range cfg.DB.Replicas {
+		replicaDialectors = append(replicaDialectors, open(replicaDSN))
+	}
+	replicas, err := replica.Register(ctx, db, cfg.DB, replicaDialectors...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("routing reads: %w", err)
+	}
+	err = metrics.RegisterGORM(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("instrumenting: %w", err)
+	}
+	err = tracing.RegisterGORM(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tracing: %w", err)
+	}
+	return db, replicas, nil
 }
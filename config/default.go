@@ -1,6 +1,96 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultSalaryRangeKey is the SalaryRanges entry used for positions without
+// an own range
+const DefaultSalaryRangeKey = "default"
+
+// DefaultValidationConfig returns the employee validation rules used when
+// nothing is configured
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		NameMinLength: 2,
+		NameMaxLength: 100,
+		NamePattern:   `^[\p{L}\p{M}][\p{L}\p{M}' .-]*$`,
+		Positions: []string{
+			"Intern",
+			"Analyst",
+			"Developer",
+			"Engineer",
+			"Software Engineer",
+			"Senior Engineer",
+			"Senior Software Engineer",
+			"Manager",
+			"Director",
+		},
+		SalaryRanges: map[string]SalaryRange{
+			DefaultSalaryRangeKey: {Min: 1, Max: 10000000},
+			"intern":              {Min: 1, Max: 100000},
+		},
+		MaxSalaryChangePercent: 50,
+	}
+}
+
+// DefaultJobCatalog returns the schedules of the background jobs. Entries
+// of the config are completed from it key by key, see completeJobCatalog.
+func DefaultJobCatalog() map[string]JobConfig {
+	return map[string]JobConfig{
+		"purge_job_runs": {
+			Enabled: true, Schedule: "15 3 * * *", Timeout: 5 * time.Minute, Retention: 30 * 24 * time.Hour,
+		},
+		"purge_rate_limit_buckets": {
+			Enabled: true, Schedule: "@hourly", Timeout: time.Minute, Retention: 24 * time.Hour,
+		},
+		"purge_api_keys": {
+			Enabled: true, Schedule: "30 3 * * *", Timeout: 5 * time.Minute, Retention: 90 * 24 * time.Hour,
+		},
+		"purge_async_jobs": {
+			Enabled: true, Schedule: "0 4 * * *", Timeout: 10 * time.Minute, Retention: 7 * 24 * time.Hour,
+		},
+		"activate_hires": {
+			Enabled: true, Schedule: "5 0 * * *", Timeout: 10 * time.Minute,
+		},
+		"end_notice_periods": {
+			Enabled: true, Schedule: "10 0 * * *", Timeout: 10 * time.Minute,
+		},
+	}
+}
+
+// completeJobCatalog fills the keys a catalog entry leaves out from the
+// default catalog. viper replaces a default map as a whole, so without it
+// disabling one job would drop the schedule of every other job.
+func completeJobCatalog(cfg *Config) {
+	if cfg.Jobs.Catalog == nil {
+		cfg.Jobs.Catalog = make(map[string]JobConfig)
+	}
+	for name, defaults := range DefaultJobCatalog() {
+		entry, ok := cfg.Jobs.Catalog[name]
+		if !ok {
+			cfg.Jobs.Catalog[name] = defaults
+			continue
+		}
+
+		prefix := "Jobs.Catalog." + name + "."
+		if !viper.IsSet(prefix + "Enabled") {
+			entry.Enabled = defaults.Enabled
+		}
+		if entry.Schedule == "" {
+			entry.Schedule = defaults.Schedule
+		}
+		if entry.Timeout == 0 {
+			entry.Timeout = defaults.Timeout
+		}
+		if !viper.IsSet(prefix + "Retention") {
+			entry.Retention = defaults.Retention
+		}
+		cfg.Jobs.Catalog[name] = entry
+	}
+}
 
 func setDefaultConfig() {
 	viper.SetDefault("LogConfig.EnableConsole", true)
@@ -10,8 +100,84 @@ func setDefaultConfig() {
 	viper.SetDefault("LogConfig.FileJSONFormat", true)
 	viper.SetDefault("LogConfig.FileLevel", "debug")
 	viper.SetDefault("LogConfig.FileLocation", "/opt/logs/employee-records.log")
+	viper.SetDefault("LogConfig.MaxSize", 100)
+	viper.SetDefault("LogConfig.MaxAge", 10)
+	viper.SetDefault("LogConfig.MaxBackups", 1)
+	viper.SetDefault("LogConfig.OverflowPolicy", "block")
+	viper.SetDefault("DB.driver", "sqlite")
+	viper.SetDefault("DB.replicas", []string{})
+	viper.SetDefault("DB.replica_check_interval", "5s")
+	viper.SetDefault("DB.read_your_writes_window", "5s")
+	viper.SetDefault("Auth.Required", false)
+	viper.SetDefault("TLS.Enabled", false)
+	viper.SetDefault("TLS.MinVersion", "1.2")
+	viper.SetDefault("TLS.ClientAuth", "none")
+	viper.SetDefault("TLS.ClientScopes", []string{"employee:read"})
+	viper.SetDefault("TLS.ReloadInterval", "30s")
+	// no cross origin callers unless configured
+	viper.SetDefault("CORS.API.AllowedOrigins", []string{})
+	viper.SetDefault("CORS.API.AllowedMethods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"})
+	viper.SetDefault("CORS.API.AllowCredentials", false)
+	viper.SetDefault("CORS.API.MaxAge", "10m")
+	viper.SetDefault("CORS.Admin.AllowedOrigins", []string{})
+	viper.SetDefault("CORS.Admin.AllowedMethods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
+	viper.SetDefault("CORS.Admin.AllowCredentials", false)
+	viper.SetDefault("CORS.Admin.MaxAge", "10m")
+	viper.SetDefault("Admin.Token", "")
+	viper.SetDefault("Diagnostics.Enabled", false)
+	viper.SetDefault("Diagnostics.Host", "127.0.0.1")
+	viper.SetDefault("Diagnostics.Port", "6060")
+	viper.SetDefault("RateLimit.Enabled", true)
+	viper.SetDefault("RateLimit.Store", "memory")
+	viper.SetDefault("RateLimit.Default.Limit", 300)
+	viper.SetDefault("RateLimit.Default.Period", "1m")
+	viper.SetDefault("RateLimit.Routes", map[string]interface{}{
+		// bulk creation is expensive, keep it well below the default
+		"POST /api/v1/employee": map[string]interface{}{"Limit": 30, "Period": "1m"},
+	})
+	viper.SetDefault("BodyLimit.MaxBytes", 1<<20)
+	viper.SetDefault("BodyLimit.Routes", map[string]interface{}{
+		// bulk creation carries many employees
+		"POST /api/v1/employee": 4 << 20,
+		// bulk updates carry a change per employee
+		"PATCH /api/v1/employee": 4 << 20,
+		// imports run in the background and may be far larger
+		"POST /api/v1/employee/import": 32 << 20,
+	})
+	viper.SetDefault("Jobs.Enabled", true)
+	viper.SetDefault("Jobs.LeaseDuration", "30s")
+	viper.SetDefault("AsyncJobs.Workers", 4)
+	viper.SetDefault("AsyncJobs.QueueSize", 100)
+	viper.SetDefault("AsyncJobs.PollInterval", "5s")
+	viper.SetDefault("AsyncJobs.LeaseTimeout", "1m")
+	viper.SetDefault("Tenancy.Enabled", false)
+	viper.SetDefault("Tenancy.DefaultTenant", "default")
+	viper.SetDefault("Tenancy.BaseDomain", "")
+	viper.SetDefault("Tenancy.Tenants", map[string]interface{}{})
+	viper.SetDefault("Cache.Enabled", true)
+	viper.SetDefault("Cache.Backend", "lru")
+	viper.SetDefault("Cache.Size", 10000)
+	viper.SetDefault("Cache.TTL", "30s")
+	viper.SetDefault("Features", map[string]bool{})
 	viper.SetDefault("Env", "prod")
 	viper.SetDefault("Port", "9876")
+	viper.SetDefault("ShutdownTimeout", "30s")
 	viper.SetDefault("GRPCPort", "12000")
 	viper.SetDefault("Verbose", true)
+
+	viper.SetDefault("Tracing.Enabled", false)
+	viper.SetDefault("Tracing.Exporter", "otlphttp")
+	viper.SetDefault("Tracing.Endpoint", "localhost:4318")
+	viper.SetDefault("Tracing.Insecure", true)
+	viper.SetDefault("Tracing.FilePath", "employee-records-traces.json")
+	viper.SetDefault("Tracing.SampleRatio", 1.0)
+	viper.SetDefault("Tracing.ServiceName", "employee-records-service")
+
+	validation := DefaultValidationConfig()
+	viper.SetDefault("Validation.NameMinLength", validation.NameMinLength)
+	viper.SetDefault("Validation.NameMaxLength", validation.NameMaxLength)
+	viper.SetDefault("Validation.NamePattern", validation.NamePattern)
+	viper.SetDefault("Validation.Positions", validation.Positions)
+	viper.SetDefault("Validation.SalaryRanges", validation.SalaryRanges)
+	viper.SetDefault("Validation.MaxSalaryChangePercent", validation.MaxSalaryChangePercent)
 }
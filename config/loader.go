@@ -2,18 +2,39 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
-// GlobalConfig stores the config instance for global use
-var GlobalConfig *Config
+// configName is the name of the config file searched for without --config
+const configName = ".employee-records-service"
 
-// Load loads config from command instance to predefined config variables
+// Profiles are the names accepted by --profile
+var Profiles = []string{"dev", "staging", "prod"}
+
+/*
+Load : loads config from command instance to predefined config variables.
+Values are layered, later ones win - defaults, the config file, the file of
+the profile, environment variables and flags. The loaded config is
+validated, a ValidationError lists every invalid key.
+
+Parameters
+----------
+cmd: cobra command with the config and profile flags
+*/
 func Load(cmd *cobra.Command) (*Config, error) {
+	// viper is shared with the reloads
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
 	err := viper.BindPFlags(cmd.Flags())
 	if err != nil {
 		return nil, err
@@ -27,17 +48,93 @@ func Load(cmd *cobra.Command) (*Config, error) {
 	// set default configs
 	setDefaultConfig()
 
+	profile := viper.GetString("profile")
+	if profile != "" {
+		// the profile names the environment unless it is configured
+		viper.SetDefault("Env", profile)
+	}
+
 	if configFile, _ := cmd.Flags().GetString("config"); configFile != "" {
 		viper.SetConfigFile(configFile)
 	} else {
-		viper.SetConfigName(".employee-records-service")
+		viper.SetConfigName(configName)
 		viper.AddConfigPath("./")
 		viper.AddConfigPath("$HOME/.employee-records-service")
 	}
 
-	if err := viper.ReadInConfig(); err != nil {
+	cfg, err := read(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	setCurrent(cfg, profile)
+	return cfg, nil
+}
+
+// read reads the config file and the file of the profile into a validated
+// config
+func read(profile string) (*Config, error) {
+	err := viper.ReadInConfig()
+	if errors.As(err, &viper.ConfigFileNotFoundError{}) {
 		zaplogger.Warn(context.Background(), "No configuration file found. Proceeding with defaults")
+	} else if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	if profile != "" {
+		if err := mergeProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := populateConfig(new(Config))
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+/*
+mergeProfile : layers the file of the profile over the config file. It sits
+next to the config file with the profile before the extension, e.g.
+config.prod.yaml for config.yaml, or is searched like the config file
+otherwise. A profile without a file only sets the environment.
+
+Parameters
+----------
+profile: name of the profile
+*/
+func mergeProfile(profile string) error {
+	base := viper.ConfigFileUsed()
+	if base != "" {
+		extension := filepath.Ext(base)
+		viper.SetConfigFile(strings.TrimSuffix(base, extension) + "." + profile + extension)
+	} else {
+		viper.SetConfigName(configName + "." + profile)
+	}
+
+	err := viper.MergeInConfig()
+
+	// the config file stays the one read and watched
+	if base != "" {
+		viper.SetConfigFile(base)
+	} else {
+		viper.SetConfigName(configName)
+	}
+
+	if errors.As(err, &viper.ConfigFileNotFoundError{}) || errors.Is(err, os.ErrNotExist) {
+		zaplogger.Warn(context.Background(), "No configuration file found for profile",
+			zap.String("profile", profile))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading config of profile %s: %w", profile, err)
 	}
 
-	return populateConfig(new(Config))
+	zaplogger.Info(context.Background(), "Loaded configuration profile", zap.String("profile", profile))
+	return nil
 }
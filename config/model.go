@@ -1,24 +1,295 @@
 package config
 
+import "time"
+
 // Model definition for configuration
 
 // Config the application's configuration
 type Config struct {
-	Config          string
-	DB              DBConfig
-	Port            string
-	GRPCPort        string
-	SomeWeirdConfig string `json:"some-weird-config" yaml:"SomeWeirdConfig"`
-	SomeAddress     string `json:"some-address" yaml:"ProxyPass"`
-	LogFile         string
-	Env             string
-	Verbose         bool
+	Config string
+	// Profile names the override file layered over Config, e.g. prod
+	// reads .employee-records-service.prod.yaml
+	Profile  string `validate:"omitempty,oneof=dev staging prod"`
+	DB       DBConfig
+	Port     string `validate:"required,port"`
+	GRPCPort string `validate:"omitempty,port"`
+	LogFile  string
+	Env      string `validate:"oneof=dev staging prod"`
+	Verbose  bool
+	// ShutdownTimeout is the deadline shared by all components to stop
+	// once a signal is received
+	ShutdownTimeout time.Duration `validate:"gt=0"`
+	Validation      ValidationConfig
+	Tracing         TracingConfig
+	LogConfig       LogConfig
+	Admin           AdminConfig
+	Diagnostics     DiagnosticsConfig
+	RateLimit       RateLimitConfig
+	BodyLimit       BodyLimitConfig
+	Auth            AuthConfig
+	TLS             TLSConfig
+	CORS            CORSConfig
+	Jobs            JobsConfig
+	AsyncJobs       AsyncJobsConfig
+	Tenancy         TenancyConfig
+	Cache           CacheConfig
+	// Features are named feature flags, names are lower cased
+	Features map[string]bool
+	// TrustedProxies are the IPs or CIDRs of the proxies whose
+	// X-Forwarded-For sets the client IP, none by default
+	TrustedProxies []string `validate:"dive,cidr|ip"`
+}
+
+// LogConfig configures the console and file outputs of the logger
+type LogConfig struct {
+	EnableConsole     bool
+	ConsoleJSONFormat bool
+	ConsoleLevel      string `validate:"oneof=debug info warn error dpanic panic fatal"`
+	EnableFile        bool
+	FileJSONFormat    bool
+	FileLevel         string `validate:"oneof=debug info warn error dpanic panic fatal"`
+	FileLocation      string `validate:"required_if=EnableFile true"`
+	// MaxSize in megabytes, MaxAge in days
+	MaxSize    int `validate:"gte=0"`
+	MaxAge     int `validate:"gte=0"`
+	MaxBackups int `validate:"gte=0"`
+	// OverflowPolicy applies when the log channel is full - block,
+	// drop_oldest or drop_newest
+	OverflowPolicy string `validate:"oneof=block drop_oldest drop_newest"`
+}
+
+// TLSConfig configures HTTPS and client certificate verification of the
+// API server
+type TLSConfig struct {
+	Enabled  bool
+	CertFile string `validate:"required_if=Enabled true"`
+	KeyFile  string `validate:"required_if=Enabled true"`
+	// MinVersion is 1.2 or 1.3
+	MinVersion string `validate:"oneof=1.2 1.3"`
+	// CipherSuites are Go names of TLS 1.2 suites, empty uses Go defaults
+	CipherSuites []string
+	// ClientAuth is none, optional or require, the last two verify client
+	// certificates against ClientCAFile
+	ClientAuth   string `validate:"oneof=none optional require"`
+	ClientCAFile string `validate:"required_unless=ClientAuth none"`
+	// ClientScopes are granted to callers with a verified certificate
+	ClientScopes []string
+	// ReloadInterval is how often the files are checked for changes
+	ReloadInterval time.Duration `validate:"gte=0"`
+}
+
+// CORSConfig holds the cross origin policies of the route groups
+type CORSConfig struct {
+	// API applies to /api/v1
+	API CORSPolicy
+	// Admin applies to /admin
+	Admin CORSPolicy
+}
+
+// CORSPolicy configures which browser origins may call a route group
+type CORSPolicy struct {
+	// AllowedOrigins are exact origins like https://app.example.com,
+	// wildcards like https://*.example.com or regular expressions prefixed
+	// with regex:, "*" allows any origin. Empty refuses cross origin calls.
+	AllowedOrigins []string `validate:"dive,required"`
+	AllowedMethods []string `validate:"dive,required"`
+	// AllowedHeaders and ExposedHeaders are added to the headers of the
+	// service itself, like X-Request-ID and the rate limit headers
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration `validate:"gte=0"`
+}
+
+// JobsConfig configures the background job scheduler
+type JobsConfig struct {
+	Enabled bool
+	// LeaseDuration is how long the leader keeps the scheduler lease
+	// without renewing it, another instance takes over once it expired
+	LeaseDuration time.Duration `validate:"gt=0"`
+	// Catalog is keyed by job name, jobs without an enabled entry only run
+	// when triggered manually
+	Catalog map[string]JobConfig `validate:"dive"`
+}
+
+// JobConfig schedules a job of the catalog
+type JobConfig struct {
+	Enabled bool
+	// Schedule is a cron expression in UTC like "0 3 * * *", a descriptor
+	// like @daily or an interval like @every 1h
+	Schedule string `validate:"required,cron"`
+	// Timeout cancels a run that takes longer
+	Timeout time.Duration `validate:"gt=0"`
+	// Retention is the age of the rows a purge job deletes
+	Retention time.Duration `validate:"gte=0"`
+}
+
+// AsyncJobsConfig configures the worker pool of the asynchronous job API
+type AsyncJobsConfig struct {
+	// Workers is the number of jobs run at once by an instance
+	Workers int `validate:"gte=1"`
+	// QueueSize bounds the queued jobs of all instances, submissions are
+	// refused with 503 beyond it
+	QueueSize int `validate:"gte=1"`
+	// PollInterval is how often idle workers look for jobs submitted to
+	// other instances or left queued by a restart
+	PollInterval time.Duration `validate:"gt=0"`
+	// LeaseTimeout is how long a running job may go without a heartbeat of
+	// its worker before it counts as lost, workers beat every third of it
+	LeaseTimeout time.Duration `validate:"gt=0"`
+}
+
+// TenancyConfig configures the tenants served by the instance. Every
+// tenant owned row carries a tenant_id, see package tenant.
+type TenancyConfig struct {
+	// Enabled resolves the tenant of each request, otherwise every request
+	// acts for DefaultTenant
+	Enabled bool
+	// DefaultTenant owns the rows created before tenancy was enabled
+	DefaultTenant string `validate:"required,max=64"`
+	// BaseDomain checks the subdomain of the host against the tenant of the
+	// credentials, e.g. acme.example.com is tenant acme for example.com.
+	// Empty accepts any host.
+	BaseDomain string
+	// Tenants is keyed by tenant ID, requests for other tenants get 404.
+	// Tenant IDs are lower case since viper lower cases map keys.
+	Tenants map[string]TenantConfig `validate:"dive"`
+}
+
+// TenantConfig overrides settings of the instance for one tenant
+type TenantConfig struct {
+	// ClientSubjects are the subjects of verified client certificates
+	// acting for the tenant, e.g. "CN=payroll,O=Acme"
+	ClientSubjects []string
+	// Features override the feature flags of the same name
+	Features map[string]bool
+	// RateLimit replaces the default quota of RateLimit, routes with an
+	// own quota keep it
+	RateLimit *RateLimitQuota
+}
+
+// CacheConfig configures the read-through cache of the employee reads.
+// Writes invalidate the entries of the instance, entries of other instances
+// are served until their TTL runs out.
+type CacheConfig struct {
+	Enabled bool
+	// Backend keeps the entries, lru keeps them in process
+	Backend string `validate:"oneof=lru"`
+	// Size bounds the entries of the lru backend, the least recently used
+	// entry is evicted beyond it
+	Size int `validate:"gte=1"`
+	// TTL is how long an entry is served
+	TTL time.Duration `validate:"gt=0"`
+}
+
+// AuthConfig configures the authentication of the API routes
+type AuthConfig struct {
+	// Required rejects requests without credentials. Otherwise the API is
+	// open to anonymous requests until the first API key is issued, they
+	// then lack every scope
+	Required bool
+}
+
+// AdminConfig configures the /admin routes
+type AdminConfig struct {
+	// Token is the bearer token required on /admin, the routes are
+	// disabled while it is empty
+	Token string `secret:"true"`
+}
+
+// DiagnosticsConfig configures the internal pprof and runtime diagnostics
+// listener, it shares the admin token
+type DiagnosticsConfig struct {
+	Enabled bool
+	// Host defaults to localhost so that the listener is not reachable from
+	// outside the machine
+	Host string `validate:"required_if=Enabled true"`
+	Port string `validate:"omitempty,port"`
 }
 
 type DBConfig struct {
+	// Driver of the primary and the replicas, sqlite, mysql or postgres
+	Driver string `json:"driver" validate:"oneof=sqlite mysql postgres"`
+	// DSN of the primary database, empty uses a shared in-memory sqlite
+	// database that does not outlive the process
+	DSN      string `json:"dsn" validate:"required_unless=Driver sqlite"`
 	Host     string `json:"host"`
 	Port     string `json:"port"`
 	User     string `json:"user"`
-	Password string `json:"password"`
+	Password string `json:"password" secret:"true"`
 	Name     string `json:"name"`
+	// Replicas are DSNs of read-only copies of the primary database DSN.
+	// Employee reads are spread over the healthy replicas and fall back to
+	// the primary while none answers.
+	Replicas []string `json:"replicas"`
+	// ReplicaCheckInterval is how often the replicas are pinged
+	ReplicaCheckInterval time.Duration `json:"replica_check_interval" validate:"gt=0"`
+	// ReadYourWritesWindow pins the reads of a caller asking for its own
+	// writes to the primary for this long after its last write, it should
+	// exceed the replication lag
+	ReadYourWritesWindow time.Duration `json:"read_your_writes_window" validate:"gte=0"`
+}
+
+// ValidationConfig holds the domain rules applied to employee payloads
+type ValidationConfig struct {
+	NameMinLength int `validate:"gte=1"`
+	NameMaxLength int `validate:"gtefield=NameMinLength"`
+	// NamePattern is the regular expression a name must fully match
+	NamePattern string `validate:"required,regexp"`
+	// Positions is the controlled vocabulary for positions, empty allows any
+	Positions []string
+	// SalaryRanges is keyed by lower cased position, "default" applies to
+	// positions without an own range
+	SalaryRanges map[string]SalaryRange `validate:"dive"`
+	// MaxSalaryChangePercent bounds a salary update relative to the current
+	// salary unless an override reason is supplied, 0 disables the check
+	MaxSalaryChangePercent float64 `validate:"gte=0"`
+}
+
+type SalaryRange struct {
+	Min float64 `json:"min" validate:"gte=0"`
+	Max float64 `json:"max" validate:"gtefield=Min"`
+}
+
+// TracingConfig configures the OpenTelemetry trace pipeline
+type TracingConfig struct {
+	Enabled bool
+	// Exporter is one of otlphttp, stdout or file
+	Exporter string `validate:"oneof=otlphttp stdout file"`
+	// Endpoint is the host:port of the OTLP HTTP collector
+	Endpoint string
+	Insecure bool
+	// FilePath is where the file exporter writes spans
+	FilePath    string
+	SampleRatio float64 `validate:"gte=0,lte=1"`
+	ServiceName string  `validate:"required"`
+}
+
+// BodyLimitConfig bounds the size of request bodies
+type BodyLimitConfig struct {
+	// MaxBytes applies to routes without an own limit
+	MaxBytes int64 `validate:"gte=0"`
+	// Routes is keyed by method and route template, e.g.
+	// "POST /api/v1/employee"
+	Routes map[string]int64 `validate:"dive,gte=0"`
+}
+
+// RateLimitConfig configures the token bucket limiter of the API routes
+type RateLimitConfig struct {
+	Enabled bool
+	// Store is memory for a single instance or db to share the buckets
+	// between instances
+	Store string `validate:"oneof=memory db"`
+	// Default applies to routes without an own quota
+	Default RateLimitQuota
+	// Routes is keyed by method and route template, e.g.
+	// "POST /api/v1/employee"
+	Routes map[string]RateLimitQuota `validate:"dive"`
+}
+
+// RateLimitQuota allows Limit requests per Period, which is also the burst
+type RateLimitQuota struct {
+	Limit  int           `validate:"gte=0"`
+	Period time.Duration `validate:"gte=0"`
 }
@@ -1,21 +1,35 @@
 package config
 
 import (
-	"errors"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
 const tagPrefix = "viper"
 
-// populateConfig is used to parse config read through viper
+// secretFileSuffix marks the key holding the path of a file with the value
+// of a secret, e.g. DB.password_FILE or EXM_DB_PASSWORD_FILE
+const secretFileSuffix = "_FILE"
+
+// populateConfig is used to parse config read through viper. Values that
+// can not be converted to the type of their field are reported together as
+// a ValidationError.
 func populateConfig(config *Config) (*Config, error) {
-	err := recursivelySet(reflect.ValueOf(config), "")
+	problems := make([]Problem, 0)
+	err := recursivelySet(reflect.ValueOf(config), "", &problems)
 	if err != nil {
 		return nil, err
 	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	completeJobCatalog(config)
 
 	return config, nil
 }
@@ -23,15 +37,15 @@ func populateConfig(config *Config) (*Config, error) {
 // recursivelySet is used to recursively set conf read from
 // files to golang structs. Since nested values are accessed using periods
 // we need to recursively parse the values
-func recursivelySet(val reflect.Value, prefix string) error {
+func recursivelySet(val reflect.Value, prefix string, problems *[]Problem) error {
 	if val.Kind() != reflect.Ptr {
-		return errors.New("WTF")
+		return fmt.Errorf("config %q: expected a pointer to a struct, got %s", prefix, val.Kind())
 	}
 
 	// dereference
 	val = reflect.Indirect(val)
 	if val.Kind() != reflect.Struct {
-		return errors.New("FML")
+		return fmt.Errorf("config %q: expected a struct, got %s", prefix, val.Kind())
 	}
 
 	// grab the type for this instance
@@ -46,40 +60,61 @@ func recursivelySet(val reflect.Value, prefix string) error {
 		for _, tag := range tags {
 			key := prefix + tag
 
-			switch thisField.Kind() {
+			var err error
+			kind := thisField.Kind()
+			secret := kind == reflect.String && thisType.Tag.Get("secret") == "true"
+			// skip the update if tag is not set in viper, secrets may be
+			// set through their file key only
+			if kind != reflect.Struct && !secret && viper.Get(key) == nil {
+				continue
+			}
+
+			switch kind {
 			case reflect.Struct:
-				if err := recursivelySet(thisField.Addr(), key+"."); err != nil {
+				if err := recursivelySet(thisField.Addr(), key+".", problems); err != nil {
 					return err
 				}
-				// case reflect.Int:
-				// 	fallthrough
-				// case reflect.Int32:
-				// fallthrough
-			case reflect.Int64:
-				// you can only set with an int64 -> int
-				configVal := int64(viper.GetInt(key))
-				// skip the update if tag is not set in viper
-				if viper.GetInt(key) == 0 && thisField.Int() != 0 {
-					continue
-				}
-
-				thisField.SetInt(configVal)
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				err = setInt(thisField, key)
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				err = setUint(thisField, key)
 			case reflect.String:
+				if secret {
+					err = setSecret(thisField, key)
+					break
+				}
 				// skip the update if tag is not set in viper
 				if viper.GetString(key) == "" && thisField.String() != "" {
 					continue
 				}
 
 				thisField.SetString(viper.GetString(key))
+			case reflect.Float32, reflect.Float64:
+				var value float64
+				value, err = cast.ToFloat64E(viper.Get(key))
+				// skip the update if tag is not set in viper
+				if err != nil || (value == 0 && thisField.Float() != 0) {
+					break
+				}
+
+				thisField.SetFloat(value)
+			case reflect.Slice, reflect.Map:
+				err = viper.UnmarshalKey(key, thisField.Addr().Interface())
 			case reflect.Bool:
+				var value bool
+				value, err = cast.ToBoolE(viper.Get(key))
 				// skip the update if tag is not set in viper
-				if !viper.GetBool(key) && thisField.Bool() {
-					continue
+				if err != nil || (!value && thisField.Bool()) {
+					break
 				}
 
-				thisField.SetBool(viper.GetBool(key))
+				thisField.SetBool(value)
 			default:
-				return fmt.Errorf("unexpected type detected ~ aborting: %s", thisField.Kind())
+				return fmt.Errorf("config %q: unsupported type %s", key, thisField.Kind())
+			}
+
+			if err != nil {
+				*problems = append(*problems, Problem{Key: key, Message: err.Error()})
 			}
 		}
 	}
@@ -87,6 +122,80 @@ func recursivelySet(val reflect.Value, prefix string) error {
 	return nil
 }
 
+// setInt sets an integer field from viper, durations are written like 1m30s
+func setInt(field reflect.Value, key string) error {
+	var value int64
+	var err error
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		var duration time.Duration
+		duration, err = cast.ToDurationE(viper.Get(key))
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, use e.g. 30s or 1m", fmt.Sprint(viper.Get(key)))
+		}
+		value = int64(duration)
+	} else {
+		value, err = cast.ToInt64E(viper.Get(key))
+	}
+	if err != nil {
+		return err
+	}
+
+	// skip the update if tag is not set in viper
+	if value == 0 && field.Int() != 0 {
+		return nil
+	}
+	if field.OverflowInt(value) {
+		return fmt.Errorf("%d overflows %s", value, field.Type())
+	}
+
+	field.SetInt(value)
+	return nil
+}
+
+// setUint sets an unsigned integer field from viper
+func setUint(field reflect.Value, key string) error {
+	value, err := cast.ToUint64E(viper.Get(key))
+	if err != nil {
+		return err
+	}
+
+	// skip the update if tag is not set in viper
+	if value == 0 && field.Uint() != 0 {
+		return nil
+	}
+	if field.OverflowUint(value) {
+		return fmt.Errorf("%d overflows %s", value, field.Type())
+	}
+
+	field.SetUint(value)
+	return nil
+}
+
+// setSecret sets a secret from viper or from the file named by the key with
+// the _FILE suffix, which keeps the secret out of the config and environment
+func setSecret(field reflect.Value, key string) error {
+	value := viper.GetString(key)
+	path := viper.GetString(key + secretFileSuffix)
+	if path == "" {
+		if value != "" || field.String() == "" {
+			field.SetString(value)
+		}
+		return nil
+	}
+	if value != "" {
+		return fmt.Errorf("set either the value or %s, not both", key+secretFileSuffix)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", key+secretFileSuffix, err)
+	}
+
+	// files written by editors and secret mounts often end with a newline
+	field.SetString(strings.TrimRight(string(content), "\r\n"))
+	return nil
+}
+
 func getTags(field *reflect.StructField) []string {
 	// check if maybe we have a special magic tag
 	tag := field.Tag
@@ -99,7 +208,10 @@ func getTags(field *reflect.StructField) []string {
 			}
 		}
 
-		return values
+		// tags like secret do not rename the field
+		if len(values) > 0 {
+			return values
+		}
 	}
 
 	return []string{field.Name}
@@ -0,0 +1,30 @@
+package config
+
+import "reflect"
+
+// RedactedValue replaces secrets in the redacted config
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the config in which every non empty string
+// field tagged `secret:"true"` is replaced by RedactedValue, so that it can
+// be shown on the diagnostics listener or in logs
+func Redacted(cfg *Config) Config {
+	redacted := *cfg
+	redact(reflect.ValueOf(&redacted).Elem())
+	return redacted
+}
+
+func redact(val reflect.Value) {
+	vType := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			redact(field)
+		case reflect.String:
+			if vType.Field(i).Tag.Get("secret") == "true" && field.String() != "" {
+				field.SetString(RedactedValue)
+			}
+		}
+	}
+}
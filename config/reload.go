@@ -0,0 +1,285 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Reload sources for the audit line
+const (
+	ReloadSourceFile   = "file"
+	ReloadSourceSignal = "sighup"
+)
+
+// Reloadable are the keys applied without a restart, changes of other keys
+// are rejected until the next start
+var Reloadable = []string{
+	"LogConfig.ConsoleLevel",
+	"LogConfig.FileLevel",
+	"RateLimit",
+	"CORS",
+	"Features",
+	"Validation",
+	"Tenancy.Tenants",
+}
+
+// Subscriber applies a reloaded config, it is only called when a key it
+// subscribed to changed
+type Subscriber func(ctx context.Context, cfg *Config) error
+
+type subscription struct {
+	key string
+	fn  Subscriber
+}
+
+var (
+	current       atomic.Pointer[Config]
+	loadedProfile string
+
+	// reloadMu serializes reloads and guards subscriptions
+	reloadMu      sync.Mutex
+	subscriptions []subscription
+)
+
+// setCurrent stores the config loaded at startup
+func setCurrent(cfg *Config, profile string) {
+	current.Store(cfg)
+	loadedProfile = profile
+}
+
+// Current returns the config in effect, including reloaded keys
+func Current() *Config {
+	return current.Load()
+}
+
+/*
+Subscribe : registers fn to be called with the new config when key, or a
+key below it, changed on reload. Subscribers run in registration order.
+
+Parameters
+----------
+key: reloadable key, e.g. RateLimit or LogConfig.ConsoleLevel
+fn: applies the new config
+*/
+func Subscribe(key string, fn Subscriber) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	subscriptions = append(subscriptions, subscription{key: key, fn: fn})
+}
+
+/*
+Reload : reads the config files again and applies the changed reloadable
+keys. An invalid config is rejected as a whole, changes of keys that are
+not reloadable are logged with a warning and ignored. The new config is
+only published once every subscriber applied it, a failing subscriber
+restores the config in effect on the subscribers called so far. Every
+reload that changes something is logged as an audit line.
+
+Parameters
+----------
+ctx: context of the reload
+source: what triggered the reload, file or sighup
+*/
+func Reload(ctx context.Context, source string) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	old := Current()
+	if old == nil {
+		return errors.New("config is not loaded")
+	}
+
+	loaded, err := read(loadedProfile)
+	if err != nil {
+		zaplogger.Error(ctx, errs.ConfigReloadError, zap.String("source", source), zap.Error(err))
+		return err
+	}
+
+	next, changed, rejected := mergeReloadable(old, loaded)
+	if len(rejected) > 0 {
+		zaplogger.Warn(ctx, errs.ConfigNotReloadableError,
+			zap.String("source", source), zap.Strings("keys", rejected))
+	}
+	if len(changed) == 0 {
+		zaplogger.Debug(ctx, "Config unchanged", zap.String("source", source))
+		return nil
+	}
+
+	applied := make([]subscription, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		if !subscribed(s.key, changed) {
+			continue
+		}
+		// the failing subscriber is restored too, it may have applied part
+		// of the config
+		applied = append(applied, s)
+		if err := s.fn(ctx, next); err != nil {
+			zaplogger.Error(ctx, errs.ConfigReloadError, zap.String("source", source),
+				zap.String("key", s.key), zap.Error(err))
+			rollback(ctx, applied, old)
+			return fmt.Errorf("applying %s: %w", s.key, err)
+		}
+	}
+	current.Store(next)
+
+	zaplogger.Info(ctx, global.ConfigReloadedSuccessfully,
+		zap.String("source", source),
+		zap.String("file", viper.ConfigFileUsed()),
+		zap.Strings("changed", changed),
+		zap.Strings("rejected", rejected))
+	return nil
+}
+
+// rollback applies the config in effect to the subscribers, last first
+func rollback(ctx context.Context, applied []subscription, old *Config) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := applied[i].fn(ctx, old); err != nil {
+			zaplogger.Error(ctx, errs.ConfigRollbackError, zap.String("key", applied[i].key), zap.Error(err))
+		}
+	}
+}
+
+/*
+Watch : reloads the config when the config file changes until ctx is done,
+the loop is tracked by waitgroup.Gwg. The file is watched here rather than
+by viper, whose watcher reads the file outside reloadMu. The file of the
+profile is not watched, send SIGHUP after changing it.
+
+Parameters
+----------
+ctx: stops the reloads once done
+*/
+func Watch(ctx context.Context) {
+	reloadMu.Lock()
+	file := viper.ConfigFileUsed()
+	reloadMu.Unlock()
+	if file == "" {
+		return
+	}
+
+	file = filepath.Clean(file)
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		// the directory is watched, editors and config maps replace the file
+		err = watcher.Add(filepath.Dir(file))
+		if err != nil {
+			_ = watcher.Close()
+		}
+	}
+	if err != nil {
+		zaplogger.Error(ctx, errs.ConfigReloadError, zap.String("file", file), zap.Error(err))
+		return
+	}
+
+	waitgroup.Add(1)
+	go func() {
+		defer waitgroup.Done()
+		defer watcher.Close()
+
+		// a config map swaps the target of the symlinked file
+		target, _ := filepath.EvalSymlinks(file)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				current, _ := filepath.EvalSymlinks(file)
+				written := filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				if !written && (current == "" || current == target) {
+					continue
+				}
+				target = current
+				_ = Reload(ctx, ReloadSourceFile)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zaplogger.Warn(ctx, errs.ConfigReloadError, zap.String("file", file), zap.Error(err))
+			}
+		}
+	}()
+}
+
+// subscribed reports whether a subscription to key covers a changed key
+func subscribed(key string, changed []string) bool {
+	for _, c := range changed {
+		if c == key || strings.HasPrefix(c, key+".") || strings.HasPrefix(key, c+".") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+mergeReloadable : returns a copy of old with the reloadable keys of loaded,
+the changed reloadable keys and the changed keys that were rejected
+
+Parameters
+----------
+old: config in effect
+loaded: config read from the files
+*/
+func mergeReloadable(old *Config, loaded *Config) (*Config, []string, []string) {
+	next := *old
+	changed := make([]string, 0)
+	rejected := make([]string, 0)
+	mergeFields(reflect.ValueOf(&next).Elem(), reflect.ValueOf(loaded).Elem(), "", &changed, &rejected)
+	return &next, changed, rejected
+}
+
+// mergeFields copies the reloadable fields of loaded into next, recursing
+// into structs that contain reloadable keys
+func mergeFields(next reflect.Value, loaded reflect.Value, prefix string, changed *[]string, rejected *[]string) {
+	for i := 0; i < next.NumField(); i++ {
+		key := prefix + next.Type().Field(i).Name
+		nextField, loadedField := next.Field(i), loaded.Field(i)
+		if reflect.DeepEqual(nextField.Interface(), loadedField.Interface()) {
+			continue
+		}
+
+		switch {
+		case isReloadable(key):
+			nextField.Set(loadedField)
+			*changed = append(*changed, key)
+		case nextField.Kind() == reflect.Struct && containsReloadable(key):
+			mergeFields(nextField, loadedField, key+".", changed, rejected)
+		default:
+			*rejected = append(*rejected, key)
+		}
+	}
+}
+
+func isReloadable(key string) bool {
+	for _, reloadable := range Reloadable {
+		if key == reloadable {
+			return true
+		}
+	}
+	return false
+}
+
+func containsReloadable(key string) bool {
+	for _, reloadable := range Reloadable {
+		if strings.HasPrefix(reloadable, key+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "Port: \"8000\"\nRateLimit:\n  Default:\n    Limit: 100\n")
+	_, err := Load(loadCommand(t, "--config", path))
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		reloadMu.Lock()
+		subscriptions = nil
+		reloadMu.Unlock()
+	})
+	notified := make(map[string]int)
+	Subscribe("RateLimit", func(ctx context.Context, cfg *Config) error {
+		notified["RateLimit"]++
+		return nil
+	})
+	Subscribe("LogConfig.ConsoleLevel", func(ctx context.Context, cfg *Config) error {
+		notified["LogConfig.ConsoleLevel"]++
+		return nil
+	})
+	Subscribe("Features", func(ctx context.Context, cfg *Config) error {
+		notified["Features"]++
+		if cfg.Features["broken"] {
+			return errors.New("broken feature")
+		}
+		return nil
+	})
+
+	testCases := []struct {
+		name             string
+		config           string
+		expectedError    bool
+		expectedPort     string
+		expectedLimit    int
+		expectedPeriod   time.Duration
+		expectedNotified map[string]int
+	}{
+		{
+			name:             "Reloadable key is applied",
+			config:           "Port: \"8000\"\nRateLimit:\n  Default:\n    Limit: 5\n",
+			expectedPort:     "8000",
+			expectedLimit:    5,
+			expectedNotified: map[string]int{"RateLimit": 1},
+		},
+		{
+			name:             "Other keys are rejected",
+			config:           "Port: \"9000\"\nRateLimit:\n  Default:\n    Limit: 5\n    Period: 1h\n",
+			expectedPort:     "8000",
+			expectedLimit:    5,
+			expectedPeriod:   time.Hour,
+			expectedNotified: map[string]int{"RateLimit": 2},
+		},
+		{
+			name:             "Invalid config keeps the current one",
+			config:           "LogConfig:\n  ConsoleLevel: loud\nRateLimit:\n  Default:\n    Limit: 1\n",
+			expectedError:    true,
+			expectedPort:     "8000",
+			expectedLimit:    5,
+			expectedPeriod:   time.Hour,
+			expectedNotified: map[string]int{"RateLimit": 2},
+		},
+		{
+			name:             "Only subscribers of changed keys are notified",
+			config:           "RateLimit:\n  Default:\n    Limit: 5\n    Period: 1h\nLogConfig:\n  ConsoleLevel: warn\n",
+			expectedPort:     "8000",
+			expectedLimit:    5,
+			expectedPeriod:   time.Hour,
+			expectedNotified: map[string]int{"RateLimit": 2, "LogConfig.ConsoleLevel": 1},
+		},
+		{
+			name:           "Failed subscriber rolls the reload back",
+			config:         "RateLimit:\n  Default:\n    Limit: 7\n    Period: 1h\nLogConfig:\n  ConsoleLevel: warn\nFeatures:\n  broken: true\n",
+			expectedError:  true,
+			expectedPort:   "8000",
+			expectedLimit:  5,
+			expectedPeriod: time.Hour,
+			// applied and restored
+			expectedNotified: map[string]int{"RateLimit": 4, "LogConfig.ConsoleLevel": 1, "Features": 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writeFile(t, dir, "config.yaml", tc.config)
+			err := Reload(context.Background(), ReloadSourceSignal)
+
+			assert.Equal(t, tc.expectedError, err != nil)
+			assert.Equal(t, tc.expectedPort, Current().Port)
+			assert.Equal(t, tc.expectedLimit, Current().RateLimit.Default.Limit)
+			if tc.expectedPeriod != 0 {
+				assert.Equal(t, tc.expectedPeriod, Current().RateLimit.Default.Period)
+			}
+			assert.Equal(t, tc.expectedNotified, notified)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "RateLimit:\n  Default:\n    Limit: 100\n")
+	_, err := Load(loadCommand(t, "--config", path))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	Watch(ctx)
+	t.Cleanup(func() {
+		cancel()
+		waitgroup.WaitWithTimeout(&waitgroup.Gwg, time.Second)
+	})
+
+	writeFile(t, dir, "config.yaml", "RateLimit:\n  Default:\n    Limit: 5\n")
+	assert.Eventually(t, func() bool {
+		return Current().RateLimit.Default.Limit == 5
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jainabhishek5986/employee-records/pkg/cron"
+)
+
+// Problem : a config key with an invalid value
+type Problem struct {
+	Key     string
+	Message string
+}
+
+// ValidationError : reports every invalid key of a config at once
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Error lists the invalid keys, one per line
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid config, %d problem(s):", len(e.Problems)))
+	for _, problem := range e.Problems {
+		lines = append(lines, fmt.Sprintf("  - %s: %s", problem.Key, problem.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// configValidator checks the validate tags of the config structs
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	validate := validator.New()
+	// a TCP port number
+	_ = validate.RegisterValidation("port", func(fl validator.FieldLevel) bool {
+		port, err := strconv.Atoi(fl.Field().String())
+		return err == nil && port > 0 && port <= 65535
+	})
+	// a regular expression that compiles
+	_ = validate.RegisterValidation("regexp", func(fl validator.FieldLevel) bool {
+		_, err := regexp.Compile(fl.Field().String())
+		return err == nil
+	})
+	// a cron expression of a job schedule
+	_ = validate.RegisterValidation("cron", func(fl validator.FieldLevel) bool {
+		_, err := cron.Parse(fl.Field().String())
+		return err == nil
+	})
+	return validate
+}
+
+/*
+Validate : checks the loaded config against the validate tags of its
+structs and the rules spanning several keys. The returned ValidationError
+lists every invalid key.
+
+Parameters
+----------
+cfg: loaded config
+*/
+func Validate(cfg *Config) error {
+	problems := make([]Problem, 0)
+
+	err := configValidator.Struct(cfg)
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		for _, fieldError := range fieldErrors {
+			problems = append(problems, Problem{
+				// the namespace starts with the struct name, the keys do not
+				Key:     strings.TrimPrefix(fieldError.Namespace(), "Config."),
+				Message: problemMessage(fieldError),
+			})
+		}
+	} else if err != nil {
+		return err
+	}
+
+	policies := []struct {
+		key    string
+		policy CORSPolicy
+	}{{"CORS.API", cfg.CORS.API}, {"CORS.Admin", cfg.CORS.Admin}}
+	for _, p := range policies {
+		for _, origin := range p.policy.AllowedOrigins {
+			// browsers refuse credentials on a wildcard origin
+			if origin == "*" && p.policy.AllowCredentials {
+				problems = append(problems, Problem{
+					Key:     p.key + ".AllowedOrigins",
+					Message: `"*" can not be combined with AllowCredentials`,
+				})
+			}
+		}
+	}
+
+	// the tenant of a request comes from its credentials, anonymous callers
+	// would pick any tenant
+	if cfg.Tenancy.Enabled && !cfg.Auth.Required {
+		problems = append(problems, Problem{
+			Key:     "Tenancy.Enabled",
+			Message: "requires Auth.Required",
+		})
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// problemMessage describes a failed validate tag
+func problemMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return "is required when " + strings.Replace(param, " ", " is ", 1)
+	case "required_unless":
+		return "is required unless " + strings.Replace(param, " ", " is ", 1)
+	case "oneof":
+		return fmt.Sprintf("must be one of %s, got %q", strings.ReplaceAll(param, " ", ", "), fmt.Sprint(fieldError.Value()))
+	case "gt":
+		return "must be more than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lte":
+		return "must be at most " + param
+	case "gtefield":
+		return "must not be less than " + param
+	case "port":
+		return fmt.Sprintf("must be a port between 1 and 65535, got %q", fmt.Sprint(fieldError.Value()))
+	case "regexp":
+		return "must be a valid regular expression"
+	case "cron":
+		_, err := cron.Parse(fmt.Sprint(fieldError.Value()))
+		return fmt.Sprintf("must be a cron expression like \"0 3 * * *\" or @every 1h, %v", err)
+	default:
+		return "failed the " + fieldError.Tag() + " check"
+	}
+}
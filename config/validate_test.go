@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// loadCommand returns a command with the flags Load reads
+func loadCommand(t *testing.T, args ...string) *cobra.Command {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("config", "", "")
+	cmd.Flags().String("profile", "", "")
+	assert.NoError(t, cmd.Flags().Parse(args))
+	return cmd
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       string
+		expectedKeys []string
+	}{
+		{
+			name:   "Defaults are valid",
+			config: "Port: \"9876\"\n",
+		},
+		{
+			name:         "Every invalid key is reported",
+			config:       "Port: \"99999\"\nEnv: qa\nLogConfig:\n  ConsoleLevel: loud\nTracing:\n  SampleRatio: 2\n",
+			expectedKeys: []string{"Port", "Env", "LogConfig.ConsoleLevel", "Tracing.SampleRatio"},
+		},
+		{
+			name:         "Values of the wrong type",
+			config:       "RateLimit:\n  Default:\n    Limit: lots\n    Period: forever\n",
+			expectedKeys: []string{"RateLimit.Default.Limit", "RateLimit.Default.Period"},
+		},
+		{
+			name:         "Cross field rules",
+			config:       "TLS:\n  Enabled: true\n  ClientAuth: require\nValidation:\n  NameMinLength: 10\n  NameMaxLength: 5\n",
+			expectedKeys: []string{"TLS.CertFile", "TLS.KeyFile", "TLS.ClientCAFile", "Validation.NameMaxLength"},
+		},
+		{
+			name:         "Wildcard origin with credentials",
+			config:       "CORS:\n  Admin:\n    AllowedOrigins: [\"*\"]\n    AllowCredentials: true\n",
+			expectedKeys: []string{"CORS.Admin.AllowedOrigins"},
+		},
+		{
+			name:         "Invalid job schedule",
+			config:       "Jobs:\n  Catalog:\n    purge_job_runs:\n      Schedule: \"61 * * * *\"\n",
+			expectedKeys: []string{"Jobs.Catalog[purge_job_runs].Schedule"},
+		},
+		{
+			name:         "Database driver without DSN",
+			config:       "DB:\n  driver: mysql\n",
+			expectedKeys: []string{"DB.DSN"},
+		},
+		{
+			name:   "Postgres database driver",
+			config: "DB:\n  driver: postgres\n  dsn: postgres://records@db:5432/employees\n",
+		},
+		{
+			name:         "Unknown database driver",
+			config:       "DB:\n  driver: oracle\n  dsn: scott@db\n",
+			expectedKeys: []string{"DB.Driver"},
+		},
+		{
+			name:         "Invalid trusted proxy",
+			config:       "TrustedProxies: [\"10.0.0.0/8\", \"proxy\"]\n",
+			expectedKeys: []string{"TrustedProxies[1]"},
+		},
+		{
+			name:         "Tenancy without authentication",
+			config:       "Tenancy:\n  Enabled: true\n",
+			expectedKeys: []string{"Tenancy.Enabled"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "config.yaml", tc.config)
+			cfg, err := Load(loadCommand(t, "--config", path))
+
+			if len(tc.expectedKeys) == 0 {
+				assert.NoError(t, err)
+				assert.NotNil(t, cfg)
+				return
+			}
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			keys := make([]string, 0)
+			for _, problem := range validationErr.Problems {
+				keys = append(keys, problem.Key)
+			}
+			assert.ElementsMatch(t, tc.expectedKeys, keys)
+		})
+	}
+}
+
+func TestLoadJobCatalog(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.yaml",
+		"Jobs:\n  Catalog:\n    purge_api_keys:\n      Enabled: false\n    purge_job_runs:\n      Retention: 48h\n")
+	cfg, err := Load(loadCommand(t, "--config", path))
+	assert.NoError(t, err)
+
+	defaults := DefaultJobCatalog()
+	// the keys an entry leaves out keep their default
+	disabled := defaults["purge_api_keys"]
+	disabled.Enabled = false
+	retention := defaults["purge_job_runs"]
+	retention.Retention = 48 * time.Hour
+	defaults["purge_api_keys"] = disabled
+	defaults["purge_job_runs"] = retention
+	assert.Equal(t, defaults, cfg.Jobs.Catalog)
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "Port: \"8000\"\nRateLimit:\n  Store: db\n")
+	writeFile(t, dir, "config.prod.yaml", "Port: \"9000\"\n")
+
+	cfg, err := Load(loadCommand(t, "--config", path, "--profile", "prod"))
+	assert.NoError(t, err)
+	assert.Equal(t, "9000", cfg.Port)
+	assert.Equal(t, "db", cfg.RateLimit.Store)
+	assert.Equal(t, "prod", cfg.Env)
+
+	// a profile without a file only sets the environment
+	cfg, err = Load(loadCommand(t, "--config", path, "--profile", "dev"))
+	assert.NoError(t, err)
+	assert.Equal(t, "8000", cfg.Port)
+	assert.Equal(t, "dev", cfg.Env)
+
+	_, err = Load(loadCommand(t, "--config", path, "--profile", "qa"))
+	assert.Error(t, err)
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	dir := t.TempDir()
+	secret := writeFile(t, dir, "db-password", "s3cret\n")
+
+	path := writeFile(t, dir, "config.yaml", "DB:\n  password_FILE: "+secret+"\n")
+	cfg, err := Load(loadCommand(t, "--config", path))
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.DB.Password)
+
+	t.Setenv("EXM_ADMIN_TOKEN_FILE", secret)
+	cfg, err = Load(loadCommand(t, "--config", path))
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", cfg.Admin.Token)
+
+	path = writeFile(t, dir, "both.yaml", "DB:\n  password: plain\n  password_FILE: "+secret+"\n")
+	_, err = Load(loadCommand(t, "--config", path))
+	assert.Error(t, err)
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/jainabhishek5986/employee-records/cmd"
 	"github.com/jainabhishek5986/employee-records/pkg/errs"
-	"github.com/jainabhishek5986/employee-records/pkg/global"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 	"go.uber.org/zap"
 )
@@ -13,8 +12,8 @@ import (
 // Main function just executes root command `ts` and this is the entry point
 // this project structure is inspired from `cobra` package
 func main() {
-	// Initiate Logger with Log File Name
-	err := zaplogger.InitLogger(global.LogFileName)
+	// Console logger until the config is loaded, it configures the log file
+	err := zaplogger.InitLogger()
 	if err != nil {
 		zaplogger.Fatal(context.Background(), errs.InitiateLoggerError, zap.Error(err))
 	}
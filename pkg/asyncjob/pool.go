@@ -0,0 +1,485 @@
+package asyncjob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// recordTimeout bounds storing the outcome of a run, which also happens
+// when the run was interrupted by the shutdown
+const recordTimeout = 5 * time.Second
+
+// Progress reports the completed percentage of a run. It returns an error
+// once the job was cancelled, the handler should return it.
+type Progress func(percent int) error
+
+// Output : outcome of a run. Result is stored as the JSON report of the
+// job, Data is offered for download when set.
+type Output struct {
+	Result      interface{}
+	Data        []byte
+	ContentType string
+	FileName    string
+}
+
+// Handler runs a job of a kind with the payload it was submitted with
+type Handler func(ctx context.Context, payload []byte, progress Progress) (Output, error)
+
+// Pool : runs the submitted jobs on a bounded number of workers. The
+// database is the queue, so jobs survive restarts and every instance
+// works on the jobs of all instances.
+type Pool struct {
+	repo     repositories.AsyncJobRepository
+	cfg      config.AsyncJobsConfig
+	handlers map[string]Handler
+	rerun    map[string]bool
+	wake     chan struct{}
+	now      func() time.Time
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+/*
+NewPool : returns a worker pool for the async jobs config, handlers are
+added with Register before it is started
+
+Parameters
+----------
+repo: async job repository
+cfg: async jobs config
+*/
+func NewPool(repo repositories.AsyncJobRepository, cfg config.AsyncJobsConfig) *Pool {
+	return &Pool{
+		repo:     repo,
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+		rerun:    make(map[string]bool),
+		wake:     make(chan struct{}, cfg.Workers),
+		now:      func() time.Time { return time.Now().UTC() },
+		running:  make(map[string]context.CancelFunc),
+	}
+}
+
+// Register adds the handler of a job kind. A job interrupted by the
+// shutdown is queued again when rerun is set, otherwise it fails, e.g. an
+// import whose rows committed so far would be created twice.
+func (p *Pool) Register(kind string, handler Handler, rerun bool) {
+	p.handlers[kind] = handler
+	p.rerun[kind] = rerun
+}
+
+/*
+Start : starts the workers and the expiry of lost jobs, they stop with
+ctx. Workers are tracked by waitgroup.Gwg and put interrupted jobs of
+rerun kinds back in the queue.
+
+Parameters
+----------
+ctx: run context of the process
+*/
+func (p *Pool) Start(ctx context.Context) error {
+	for i := 0; i < p.cfg.Workers; i++ {
+		waitgroup.Add(1)
+		go func() {
+			defer waitgroup.Done()
+			p.work(ctx)
+		}()
+	}
+	waitgroup.Add(1)
+	go func() {
+		defer waitgroup.Done()
+		p.expire(ctx)
+	}()
+	zaplogger.Info(ctx, "Async job workers started", zap.Int("workers", p.cfg.Workers))
+	return nil
+}
+
+// work runs queued jobs until none is left, then waits for a submission
+// or the next poll
+func (p *Pool) work(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			// the queue is shared by the tenants
+			job, claimed, err := p.repo.ClaimNextAsyncJob(tenant.AllTenants(ctx), p.now())
+			if err != nil || !claimed {
+				break
+			}
+			p.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// expire releases the running jobs whose worker stopped beating every
+// PollInterval, every instance does so for the jobs of all instances
+func (p *Pool) expire(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	rerun := make([]string, 0, len(p.rerun))
+	for kind, ok := range p.rerun {
+		if ok {
+			rerun = append(rerun, kind)
+		}
+	}
+	for {
+		now := p.now()
+		expired, err := p.repo.ExpireAsyncJobs(tenant.AllTenants(ctx), now.Add(-p.cfg.LeaseTimeout), now, rerun)
+		if err == nil && expired > 0 {
+			zaplogger.Warn(ctx, errs.AsyncJobLostError, zap.Int64("expired", expired))
+			// requeued jobs are claimed right away
+			select {
+			case p.wake <- struct{}{}:
+			default:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run executes a claimed job for the tenant that submitted it and records
+// its outcome
+func (p *Pool) run(ctx context.Context, job models.AsyncJob) {
+	ctx = tenant.WithTenant(ctx, job.TenantID)
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p.mu.Lock()
+	p.running[job.ID] = cancel
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, job.ID)
+		p.mu.Unlock()
+	}()
+
+	var progressed atomic.Int64
+	stopHeartbeat := p.heartbeat(jobCtx, job.ID, &progressed)
+	output, err := p.execute(jobCtx, job, &progressed)
+	stopHeartbeat()
+
+	recordCtx, cancelRecord := context.WithTimeout(tenant.WithTenant(context.Background(), job.TenantID), recordTimeout)
+	defer cancelRecord()
+	fields := []zap.Field{zap.String("async_job_id", job.ID), zap.String("kind", job.Kind)}
+
+	// interrupted by the shutdown, another start picks it up again unless
+	// the run can not be repeated
+	if ctx.Err() != nil {
+		if p.rerun[job.Kind] {
+			_ = p.repo.RequeueAsyncJob(recordCtx, job.ID)
+			zaplogger.Warn(recordCtx, "Async job interrupted by shutdown, requeued", fields...)
+			return
+		}
+		err = errors.New(errs.AsyncJobInterruptedError)
+	}
+
+	finished := p.now()
+	job.FinishedAt = &finished
+	job.Status = models.AsyncJobStatusSucceeded
+	job.Progress = 100
+	if err != nil {
+		job.Status = models.AsyncJobStatusFailed
+		job.Error = err.Error()
+	}
+	if output.Result != nil {
+		result, marshalErr := json.Marshal(output.Result)
+		if marshalErr != nil {
+			zaplogger.Error(recordCtx, errs.StructDecodeError, append(fields, zap.Error(marshalErr))...)
+		}
+		job.Result = string(result)
+	}
+	job.Output = output.Data
+	job.OutputType = output.ContentType
+	job.OutputName = output.FileName
+
+	recorded, _ := p.repo.FinishAsyncJob(recordCtx, &job)
+	if !recorded {
+		zaplogger.Info(recordCtx, global.AsyncJobCancelledSuccessfully, fields...)
+		return
+	}
+	fields = append(fields, zap.String("status", job.Status), zap.Duration("duration", finished.Sub(*job.StartedAt)))
+	if err != nil {
+		zaplogger.Error(recordCtx, errs.AsyncJobRunFailedError, append(fields, zap.Error(err))...)
+		return
+	}
+	zaplogger.Info(recordCtx, global.AsyncJobFinishedSuccessfully, fields...)
+}
+
+// heartbeat refreshes the heartbeat of a running job every third of the
+// lease timeout until it is stopped, so that it is not expired while the
+// handler has no progress to report
+func (p *Pool) heartbeat(ctx context.Context, id string, progressed *atomic.Int64) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.cfg.LeaseTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.report(ctx, id, int(progressed.Load()))
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// report stores the progress and heartbeat of a running job
+func (p *Pool) report(ctx context.Context, id string, percent int) {
+	running, err := p.repo.UpdateAsyncJobProgress(ctx, id, percent, p.now())
+	// cancelled, possibly through another instance
+	if err == nil && !running {
+		p.cancelRunning(id)
+	}
+}
+
+// execute runs the handler of the job, a panic fails the job
+func (p *Pool) execute(ctx context.Context, job models.AsyncJob, progressed *atomic.Int64) (output Output, err error) {
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		return Output{}, fmt.Errorf("%s %q", errs.AsyncJobUnknownKindError, job.Kind)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	progress := func(percent int) error {
+		if percent > 100 {
+			percent = 100
+		}
+		if int64(percent) > progressed.Load() {
+			progressed.Store(int64(percent))
+			p.report(ctx, job.ID, percent)
+		}
+		return ctx.Err()
+	}
+	return handler(ctx, job.Payload, progress)
+}
+
+func (p *Pool) cancelRunning(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cancel, ok := p.running[id]; ok {
+		cancel()
+	}
+}
+
+/*
+SubmitJob : queues a job of a kind, payload is stored as JSON and handed
+to the handler
+
+Parameters
+----------
+ctx: request context, its caller owns the job
+kind: job kind with a registered handler
+payload: request of the job
+*/
+func (p *Pool) SubmitJob(ctx context.Context, kind string, payload interface{}) (global.AsyncJobInfo, error) {
+	if _, ok := p.handlers[kind]; !ok {
+		zaplogger.Error(ctx, errs.AsyncJobUnknownKindError, zap.String("kind", kind))
+		return global.AsyncJobInfo{}, errs.InternalErr()
+	}
+
+	// QueueSize bounds the jobs of every tenant
+	queued, err := p.repo.CountQueuedAsyncJobs(tenant.AllTenants(ctx))
+	if err != nil {
+		return global.AsyncJobInfo{}, err
+	}
+	if queued >= int64(p.cfg.QueueSize) {
+		zaplogger.Warn(ctx, errs.AsyncJobQueueFullError, zap.Int64("queued", queued))
+		return global.AsyncJobInfo{}, errs.UnavailableErr(errs.AsyncJobQueueFullError)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		zaplogger.Error(ctx, errs.StructDecodeError, zap.Error(err))
+		return global.AsyncJobInfo{}, errs.InternalErr()
+	}
+	job := models.AsyncJob{
+		ID:        newID(),
+		Kind:      kind,
+		Owner:     owner(ctx),
+		Status:    models.AsyncJobStatusQueued,
+		Payload:   data,
+		CreatedAt: p.now(),
+	}
+	if err := p.repo.CreateAsyncJob(ctx, &job); err != nil {
+		return global.AsyncJobInfo{}, err
+	}
+
+	// an idle worker picks it up right away, busy ones when they are done
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	zaplogger.Info(ctx, global.AsyncJobSubmittedSuccessfully, zap.String("async_job_id", job.ID), zap.String("kind", kind))
+	return info(job), nil
+}
+
+/*
+GetJob : returns the status of a job of the caller
+
+Parameters
+----------
+ctx: request context
+id: job ID
+*/
+func (p *Pool) GetJob(ctx context.Context, id string) (global.AsyncJobInfo, error) {
+	job, err := p.ownJob(ctx, id, p.repo.GetAsyncJob)
+	if err != nil {
+		return global.AsyncJobInfo{}, err
+	}
+	return info(job), nil
+}
+
+/*
+CancelJob : cancels a queued or running job of the caller, a running job
+stops at its next progress report
+
+Parameters
+----------
+ctx: request context
+id: job ID
+*/
+func (p *Pool) CancelJob(ctx context.Context, id string) (global.AsyncJobInfo, error) {
+	job, err := p.ownJob(ctx, id, p.repo.GetAsyncJob)
+	if err != nil {
+		return global.AsyncJobInfo{}, err
+	}
+
+	cancelled, err := p.repo.CancelAsyncJob(ctx, id, p.now())
+	if err != nil {
+		return global.AsyncJobInfo{}, err
+	}
+	if !cancelled {
+		return global.AsyncJobInfo{}, errs.ConflictErr(errs.AsyncJobFinishedError)
+	}
+	p.cancelRunning(id)
+	zaplogger.Info(ctx, global.AsyncJobCancelledSuccessfully, zap.String("async_job_id", id), zap.String("kind", job.Kind))
+
+	job, err = p.repo.GetAsyncJob(ctx, id)
+	if err != nil {
+		return global.AsyncJobInfo{}, err
+	}
+	return info(job), nil
+}
+
+/*
+GetJobOutput : returns the file produced by a succeeded job of the caller
+
+Parameters
+----------
+ctx: request context
+id: job ID
+*/
+func (p *Pool) GetJobOutput(ctx context.Context, id string) (global.FileResponse, error) {
+	job, err := p.ownJob(ctx, id, p.repo.GetAsyncJobOutput)
+	if err != nil {
+		return global.FileResponse{}, err
+	}
+	if job.Status != models.AsyncJobStatusSucceeded || job.OutputName == "" {
+		return global.FileResponse{}, errs.RequestNotProcessed(errs.AsyncJobNoOutputError)
+	}
+
+	return global.FileResponse{
+		ContentType: job.OutputType,
+		FileName:    job.OutputName,
+		Data:        job.Output,
+	}, nil
+}
+
+// ownJob loads a job, jobs of other callers are reported as missing so
+// that their IDs are not disclosed
+func (p *Pool) ownJob(ctx context.Context, id string,
+	get func(ctx context.Context, id string) (models.AsyncJob, error)) (models.AsyncJob, error) {
+
+	job, err := get(ctx, id)
+	if err != nil {
+		return job, err
+	}
+	if job.Owner != owner(ctx) {
+		zaplogger.Warn(ctx, errs.AsyncJobNoRecordFoundError, zap.String("async_job_id", id))
+		return models.AsyncJob{}, errs.NotFoundErr(errs.AsyncJobNoRecordFoundError)
+	}
+	return job, nil
+}
+
+// owner is the subject of the caller, empty for anonymous callers
+func owner(ctx context.Context) string {
+	identity, _ := auth.FromContext(ctx)
+	return identity.Subject
+}
+
+// newID returns a random job ID, it can not be guessed from other IDs
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Location is the status URL of a job
+func Location(id string) string {
+	return "/api/v1/jobs/" + id
+}
+
+func info(job models.AsyncJob) global.AsyncJobInfo {
+	jobInfo := global.AsyncJobInfo{
+		ID:         job.ID,
+		Kind:       job.Kind,
+		Status:     job.Status,
+		Progress:   job.Progress,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Result != "" {
+		jobInfo.Result = json.RawMessage(job.Result)
+	}
+	if job.Status == models.AsyncJobStatusSucceeded && job.OutputName != "" {
+		jobInfo.DownloadURL = Location(job.ID) + "/download"
+	}
+	return jobInfo
+}
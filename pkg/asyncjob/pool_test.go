@@ -0,0 +1,369 @@
+package asyncjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	asyncjobrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/asyncjob"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db := testutil.OpenDB(t, &models.AsyncJob{})
+	// jobs are run by their own goroutines, a shared cache in-memory
+	// database locks tables between connections
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func newTestPool(db *gorm.DB, queueSize int) *Pool {
+	pool := NewPool(asyncjobrepo.NewAsyncJobRepo(db), config.AsyncJobsConfig{
+		Workers:      2,
+		QueueSize:    queueSize,
+		PollInterval: 10 * time.Millisecond,
+		LeaseTimeout: time.Second,
+	})
+	pool.Register("report", func(ctx context.Context, payload []byte, progress Progress) (Output, error) {
+		if err := progress(50); err != nil {
+			return Output{}, err
+		}
+		return Output{
+			Result:      map[string]string{"payload": string(payload)},
+			Data:        []byte("id\n1\n"),
+			ContentType: "text/csv",
+			FileName:    "report.csv",
+		}, nil
+	}, true)
+	pool.Register("failing", func(ctx context.Context, payload []byte, progress Progress) (Output, error) {
+		return Output{}, errors.New("row 3 is invalid")
+	}, true)
+	blocking := func(ctx context.Context, payload []byte, progress Progress) (Output, error) {
+		for {
+			if err := progress(10); err != nil {
+				return Output{Result: map[string]int{"created": 1}}, err
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	pool.Register("blocking", blocking, false)
+	pool.Register("blocking_rerun", blocking, true)
+	return pool
+}
+
+func asCaller(subject string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{Subject: subject})
+}
+
+// waitForStatus polls the job until it reached the status
+func waitForStatus(t *testing.T, pool *Pool, ctx context.Context, id string, status string) {
+	assert.Eventually(t, func() bool {
+		job, err := pool.GetJob(ctx, id)
+		return err == nil && job.Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func assertStatusCode(t *testing.T, expected int, err error) {
+	var httpErr *errs.HTTPError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, expected, httpErr.StatusCode())
+	}
+}
+
+func TestPoolRunsSubmittedJobs(t *testing.T) {
+	db := setupTestDB(t)
+	pool := newTestPool(db, 10)
+	runCtx, stop := context.WithCancel(context.Background())
+	defer waitgroup.Gwg.Wait()
+	defer stop()
+	assert.NoError(t, pool.Start(runCtx))
+
+	owner := asCaller("apikey:1")
+	testCases := []struct {
+		name             string
+		kind             string
+		expectedStatus   string
+		expectedResult   string
+		expectedError    string
+		expectedDownload bool
+	}{
+		{
+			name:             "Succeeded job has a report and a download",
+			kind:             "report",
+			expectedStatus:   models.AsyncJobStatusSucceeded,
+			expectedResult:   `{"payload":"[1,2]"}`,
+			expectedDownload: true,
+		},
+		{
+			name:           "Failed job has an error",
+			kind:           "failing",
+			expectedStatus: models.AsyncJobStatusFailed,
+			expectedError:  "row 3 is invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			submitted, err := pool.SubmitJob(owner, tc.kind, []int{1, 2})
+			assert.NoError(t, err)
+			assert.Equal(t, models.AsyncJobStatusQueued, submitted.Status)
+
+			waitForStatus(t, pool, owner, submitted.ID, tc.expectedStatus)
+			job, err := pool.GetJob(owner, submitted.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, 100, job.Progress)
+			assert.Equal(t, tc.expectedError, job.Error)
+			assert.NotNil(t, job.FinishedAt)
+			if tc.expectedResult != "" {
+				assert.JSONEq(t, tc.expectedResult, fmt.Sprintf("%s", job.Result))
+			}
+
+			file, err := pool.GetJobOutput(owner, submitted.ID)
+			if !tc.expectedDownload {
+				assert.Empty(t, job.DownloadURL)
+				assertStatusCode(t, http.StatusUnprocessableEntity, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, Location(submitted.ID)+"/download", job.DownloadURL)
+			assert.Equal(t, "report.csv", file.FileName)
+			assert.Equal(t, "id\n1\n", string(file.Data))
+		})
+	}
+}
+
+func TestPoolCancelJob(t *testing.T) {
+	db := setupTestDB(t)
+	pool := newTestPool(db, 10)
+	runCtx, stop := context.WithCancel(context.Background())
+	defer waitgroup.Gwg.Wait()
+	defer stop()
+	assert.NoError(t, pool.Start(runCtx))
+
+	owner := asCaller("apikey:1")
+	running, err := pool.SubmitJob(owner, "blocking", nil)
+	assert.NoError(t, err)
+	waitForStatus(t, pool, owner, running.ID, models.AsyncJobStatusRunning)
+
+	testCases := []struct {
+		name           string
+		ctx            context.Context
+		expectedStatus int
+	}{
+		{
+			name:           "Job of another caller",
+			ctx:            asCaller("apikey:2"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Running job is cancelled",
+			ctx:  owner,
+		},
+		{
+			name:           "Finished job can not be cancelled",
+			ctx:            owner,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, err := pool.CancelJob(tc.ctx, running.ID)
+			if tc.expectedStatus != 0 {
+				assertStatusCode(t, tc.expectedStatus, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, models.AsyncJobStatusCancelled, job.Status)
+		})
+	}
+
+	// the worker stops at its next progress report and keeps the status
+	assert.Eventually(t, func() bool {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return len(pool.running) == 0
+	}, 2*time.Second, 5*time.Millisecond)
+	job, err := pool.GetJob(owner, running.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AsyncJobStatusCancelled, job.Status)
+}
+
+func TestPoolSubmitJob(t *testing.T) {
+	db := setupTestDB(t)
+	// not started, submitted jobs stay queued
+	pool := newTestPool(db, 1)
+	owner := asCaller("apikey:1")
+
+	testCases := []struct {
+		name           string
+		kind           string
+		expectedStatus int
+	}{
+		{
+			name: "Job is queued",
+			kind: "report",
+		},
+		{
+			name:           "Queue is full",
+			kind:           "report",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "Unknown kind",
+			kind:           "missing",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, err := pool.SubmitJob(owner, tc.kind, nil)
+			if tc.expectedStatus != 0 {
+				assertStatusCode(t, tc.expectedStatus, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, job.ID, 32)
+
+			_, err = pool.GetJob(asCaller("apikey:2"), job.ID)
+			assertStatusCode(t, http.StatusNotFound, err)
+		})
+	}
+}
+
+func TestPoolShutdown(t *testing.T) {
+	testCases := []struct {
+		name           string
+		kind           string
+		expectedStatus string
+		expectedError  string
+		expectedResult string
+	}{
+		{
+			name:           "Interrupted job of a rerun kind is queued again",
+			kind:           "blocking_rerun",
+			expectedStatus: models.AsyncJobStatusQueued,
+		},
+		{
+			name:           "Interrupted job of another kind fails with its report",
+			kind:           "blocking",
+			expectedStatus: models.AsyncJobStatusFailed,
+			expectedError:  errs.AsyncJobInterruptedError,
+			expectedResult: `{"created":1}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			pool := newTestPool(db, 10)
+			runCtx, stop := context.WithCancel(context.Background())
+			assert.NoError(t, pool.Start(runCtx))
+
+			owner := asCaller("apikey:1")
+			submitted, err := pool.SubmitJob(owner, tc.kind, nil)
+			assert.NoError(t, err)
+			waitForStatus(t, pool, owner, submitted.ID, models.AsyncJobStatusRunning)
+			stop()
+			waitgroup.Gwg.Wait()
+
+			job, err := pool.GetJob(owner, submitted.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, job.Status)
+			assert.Equal(t, tc.expectedError, job.Error)
+			if tc.expectedResult != "" {
+				assert.JSONEq(t, tc.expectedResult, fmt.Sprintf("%s", job.Result))
+			}
+		})
+	}
+}
+
+func TestPoolExpiresLostJobs(t *testing.T) {
+	testCases := []struct {
+		name           string
+		kind           string
+		expectedStatus string
+		expectedError  string
+	}{
+		{
+			name:           "Lost job of a rerun kind runs again",
+			kind:           "report",
+			expectedStatus: models.AsyncJobStatusSucceeded,
+		},
+		{
+			name:           "Lost job of another kind fails",
+			kind:           "blocking",
+			expectedStatus: models.AsyncJobStatusFailed,
+			expectedError:  errs.AsyncJobLostError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			pool := newTestPool(db, 10)
+
+			// left running by a worker that died an hour ago
+			beat := time.Now().UTC().Add(-time.Hour)
+			lost := models.AsyncJob{ID: "lost", Kind: tc.kind, Owner: "apikey:1", Status: models.AsyncJobStatusRunning,
+				CreatedAt: beat, StartedAt: &beat, HeartbeatAt: &beat}
+			assert.NoError(t, db.Create(&lost).Error)
+
+			runCtx, stop := context.WithCancel(context.Background())
+			defer waitgroup.Gwg.Wait()
+			defer stop()
+			assert.NoError(t, pool.Start(runCtx))
+
+			owner := asCaller("apikey:1")
+			waitForStatus(t, pool, owner, lost.ID, tc.expectedStatus)
+			job, err := pool.GetJob(owner, lost.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedError, job.Error)
+		})
+	}
+}
+
+func TestPoolHeartbeat(t *testing.T) {
+	db := setupTestDB(t)
+	pool := newTestPool(db, 10)
+	pool.cfg.LeaseTimeout = 30 * time.Millisecond
+	// reports no progress while it runs
+	release := make(chan struct{})
+	pool.Register("silent", func(ctx context.Context, payload []byte, progress Progress) (Output, error) {
+		<-release
+		return Output{}, nil
+	}, false)
+	runCtx, stop := context.WithCancel(context.Background())
+	defer waitgroup.Gwg.Wait()
+	defer stop()
+	assert.NoError(t, pool.Start(runCtx))
+
+	owner := asCaller("apikey:1")
+	submitted, err := pool.SubmitJob(owner, "silent", nil)
+	assert.NoError(t, err)
+	waitForStatus(t, pool, owner, submitted.ID, models.AsyncJobStatusRunning)
+
+	// several lease timeouts pass, the heartbeat keeps the job running
+	time.Sleep(150 * time.Millisecond)
+	job, err := pool.GetJob(owner, submitted.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AsyncJobStatusRunning, job.Status)
+
+	close(release)
+	waitForStatus(t, pool, owner, submitted.ID, models.AsyncJobStatusSucceeded)
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+)
+
+// Scopes granted to callers
+const (
+	ScopeEmployeeRead  = "employee:read"
+	ScopeEmployeeWrite = "employee:write"
+)
+
+// Scopes lists every known scope
+var Scopes = []string{ScopeEmployeeRead, ScopeEmployeeWrite}
+
+// Authentication methods of an identity
+const (
+	MethodAPIKey = "apikey"
+	MethodMTLS   = "mtls"
+)
+
+// Identity : the authenticated caller of a request. Every authentication
+// method, API key or client certificate, stores one in the request context
+// so that authorisation, rate limiting and logging do not depend on the
+// method.
+type Identity struct {
+	// Subject is unique per caller, e.g. apikey:12 or mtls:CN=payroll
+	Subject string
+	Method  string
+	Scopes  []string
+	// Tenant the caller belongs to, the tenant of an API key or of the
+	// certificate subject. Empty for certificates not mapped to a tenant.
+	Tenant string
+}
+
+// HasScope reports whether the identity was granted scope
+func (i Identity) HasScope(scope string) bool {
+	for _, granted := range i.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+// WithIdentity stores the identity in the context, its subject becomes the
+// user ID of the log lines
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	ctx = context.WithValue(ctx, identityKey{}, identity)
+	return reqctx.WithUserID(ctx, identity.Subject)
+}
+
+// FromContext returns the identity of the request, if authenticated
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+type openAccessKey struct{}
+
+// WithOpenAccess marks an anonymous request of the open API mode, it is
+// granted every scope
+func WithOpenAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, openAccessKey{}, true)
+}
+
+// OpenAccess reports whether the anonymous request is granted every scope
+func OpenAccess(ctx context.Context) bool {
+	open, _ := ctx.Value(openAccessKey{}).(bool)
+	return open
+}
+
+// ValidScope reports whether scope is known
+func ValidScope(scope string) bool {
+	for _, known := range Scopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+// Backends of the cache entries
+const (
+	BackendLRU = "lru"
+)
+
+/*
+Backend : keeps the cache entries, safe for concurrent use. Entries expire
+after their ttl, zero keeps them until they are evicted.
+*/
+type Backend interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+/*
+NewBackend : returns the backend named in the config
+
+Parameters
+----------
+name: lru
+size: maximum number of entries
+*/
+func NewBackend(name string, size int) (Backend, error) {
+	switch strings.ToLower(name) {
+	case "", BackendLRU:
+		return NewLRU(size), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", name)
+	}
+}
+
+/*
+Cache : read-through cache on a backend. Entries belong to a scope, e.g. a
+tenant, and are invalidated together by moving the scope to a new version,
+so that a load finishing after an invalidation can not bring stale values
+back. Concurrent misses of a key share one load.
+*/
+type Cache struct {
+	name    string
+	backend Backend
+	ttl     time.Duration
+	group   singleflight.Group
+}
+
+// New returns the named cache, the name labels its metrics and prefixes
+// its keys so that caches can share a backend
+func New(name string, backend Backend, ttl time.Duration) *Cache {
+	return &Cache{name: name, backend: backend, ttl: ttl}
+}
+
+/*
+Get : returns the value of key in scope, loading and storing it on a miss.
+Errors are not cached. A load shared by concurrent misses runs with the
+context of the first caller without its cancellation, values returned are
+shared by the callers and must not be modified.
+
+Parameters
+----------
+ctx: context of the caller
+scope: scope of the key
+key: key within the scope
+load: reads the value from the source
+*/
+func (c *Cache) Get(ctx context.Context, scope string, key string,
+	load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	fullKey := c.name + "@" + c.version(c.name) + "/" + scope + "@" + c.version(c.scopeKey(scope)) + "/" + key
+	if value, ok := c.backend.Get(fullKey); ok {
+		metrics.ObserveCacheLookup(c.name, true)
+		return value, nil
+	}
+	metrics.ObserveCacheLookup(c.name, false)
+
+	value, err, _ := c.group.Do(fullKey, func() (interface{}, error) {
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		c.backend.Set(fullKey, value, c.ttl)
+		return value, nil
+	})
+	return value, err
+}
+
+// Invalidate drops the entries of the scope
+func (c *Cache) Invalidate(scope string) {
+	c.backend.Set(versionKey(c.scopeKey(scope)), newVersion(), 0)
+}
+
+// InvalidateAll drops the entries of every scope
+func (c *Cache) InvalidateAll() {
+	c.backend.Set(versionKey(c.name), newVersion(), 0)
+}
+
+func (c *Cache) scopeKey(scope string) string {
+	return c.name + "/" + scope
+}
+
+// version returns the current version of the name, a version evicted from
+// the backend is replaced by a new one and its entries are missed
+func (c *Cache) version(name string) string {
+	if value, ok := c.backend.Get(versionKey(name)); ok {
+		return value.(string)
+	}
+	version := newVersion()
+	c.backend.Set(versionKey(name), version, 0)
+	return version
+}
+
+func versionKey(name string) string {
+	return name + "#version"
+}
+
+var versions atomic.Uint64
+
+// newVersion returns a version unique to the process and its start, so
+// that a shared backend never sees a version twice
+func newVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(versions.Add(1), 36)
+}
+
+// settings are the caches of the process on the configured backend
+type settings struct {
+	backend Backend
+	ttl     time.Duration
+	mu      sync.Mutex
+	caches  map[string]*Cache
+}
+
+var current atomic.Pointer[settings]
+
+/*
+Configure : sets up the backend of the process from the config, caching is
+disabled until it is called or while the config disables it
+
+Parameters
+----------
+cfg: cache config
+*/
+func Configure(cfg config.CacheConfig) error {
+	if !cfg.Enabled {
+		current.Store(nil)
+		return nil
+	}
+	backend, err := NewBackend(cfg.Backend, cfg.Size)
+	if err != nil {
+		return err
+	}
+	current.Store(&settings{backend: backend, ttl: cfg.TTL, caches: make(map[string]*Cache)})
+	return nil
+}
+
+// Named returns the named cache of the process, nil while caching is
+// disabled. Callers of the same name share entries, loads and
+// invalidations.
+func Named(name string) *Cache {
+	s := current.Load()
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.caches[name]; ok {
+		return c
+	}
+	c := New(name, s.backend, s.ttl)
+	s.caches[name] = c
+	return c
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lru := NewLRU(2)
+	lru.now = func() time.Time { return now }
+
+	lru.Set("a", 1, 0)
+	lru.Set("b", 2, time.Minute)
+	_, ok := lru.Get("a")
+	assert.True(t, ok)
+
+	// b is the least recently used entry
+	lru.Set("c", 3, 0)
+	_, ok = lru.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, lru.Len())
+
+	lru.Set("d", 4, time.Minute)
+	value, ok := lru.Get("d")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+
+	now = now.Add(time.Minute)
+	_, ok = lru.Get("d")
+	assert.False(t, ok)
+	assert.Equal(t, 1, lru.Len())
+}
+
+func TestCacheGet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores loaded values", func(t *testing.T) {
+		c := New("test", NewLRU(10), time.Minute)
+		loads := 0
+		load := func(context.Context) (interface{}, error) {
+			loads++
+			return loads, nil
+		}
+
+		first, err := c.Get(ctx, "a", "key", load)
+		assert.NoError(t, err)
+		second, err := c.Get(ctx, "a", "key", load)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, first)
+		assert.Equal(t, 1, second)
+
+		// other scopes have own entries
+		other, err := c.Get(ctx, "b", "key", load)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, other)
+	})
+
+	t.Run("Does not store errors", func(t *testing.T) {
+		c := New("test", NewLRU(10), time.Minute)
+		failure := errors.New("unavailable")
+
+		_, err := c.Get(ctx, "a", "key", func(context.Context) (interface{}, error) { return nil, failure })
+		assert.ErrorIs(t, err, failure)
+		value, err := c.Get(ctx, "a", "key", func(context.Context) (interface{}, error) { return "loaded", nil })
+		assert.NoError(t, err)
+		assert.Equal(t, "loaded", value)
+	})
+
+	t.Run("Concurrent misses share a load", func(t *testing.T) {
+		c := New("test", NewLRU(10), time.Minute)
+		var loads atomic.Int32
+		release := make(chan struct{})
+		load := func(context.Context) (interface{}, error) {
+			loads.Add(1)
+			<-release
+			return "loaded", nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := c.Get(ctx, "a", "key", load)
+				assert.NoError(t, err)
+				assert.Equal(t, "loaded", value)
+			}()
+		}
+		// let the callers reach the load before it returns
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), loads.Load())
+	})
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	c := New("test", NewLRU(10), time.Minute)
+	loads := 0
+	load := func(context.Context) (interface{}, error) {
+		loads++
+		return loads, nil
+	}
+	get := func(scope string) interface{} {
+		value, err := c.Get(ctx, scope, "key", load)
+		assert.NoError(t, err)
+		return value
+	}
+
+	assert.Equal(t, 1, get("a"))
+	assert.Equal(t, 2, get("b"))
+
+	c.Invalidate("a")
+	assert.Equal(t, 3, get("a"))
+	assert.Equal(t, 2, get("b"))
+
+	c.InvalidateAll()
+	assert.Equal(t, 4, get("a"))
+	assert.Equal(t, 5, get("b"))
+}
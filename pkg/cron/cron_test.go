@@ -0,0 +1,105 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// a Monday
+	from := time.Date(2024, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   time.Time
+	}{
+		{
+			name:       "Every minute",
+			expression: "* * * * *",
+			expected:   time.Date(2024, time.January, 15, 10, 8, 0, 0, time.UTC),
+		},
+		{
+			name:       "Step",
+			expression: "*/15 * * * *",
+			expected:   time.Date(2024, time.January, 15, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:       "List and range",
+			expression: "0,30 9-17 * * *",
+			expected:   time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Next day",
+			expression: "30 3 * * *",
+			expected:   time.Date(2024, time.January, 16, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Weekday name",
+			expression: "0 9 * * fri",
+			expected:   time.Date(2024, time.January, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Sunday as 7",
+			expression: "0 0 * * 7",
+			expected:   time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Day of month or day of week",
+			expression: "0 0 1 * mon",
+			expected:   time.Date(2024, time.January, 22, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Leap day",
+			expression: "0 0 29 feb *",
+			expected:   time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Descriptor",
+			expression: "@monthly",
+			expected:   time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Interval",
+			expression: "@every 90m",
+			expected:   time.Date(2024, time.January, 15, 11, 37, 30, 0, time.UTC),
+		},
+		{
+			name:       "Never matches",
+			expression: "0 0 30 feb *",
+			expected:   time.Time{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := Parse(tc.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expression string
+	}{
+		{name: "Missing field", expression: "* * * *"},
+		{name: "Out of range", expression: "60 * * * *"},
+		{name: "Reversed range", expression: "* 17-9 * * *"},
+		{name: "Zero step", expression: "*/0 * * * *"},
+		{name: "Unknown name", expression: "* * * foo *"},
+		{name: "Short interval", expression: "@every 10ms"},
+		{name: "Invalid interval", expression: "@every soon"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.expression)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package employment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+)
+
+// Employment statuses of an employee
+const (
+	StatusCandidate    = "candidate"
+	StatusOnboarding   = "onboarding"
+	StatusActive       = "active"
+	StatusOnLeave      = "on_leave"
+	StatusNoticePeriod = "notice_period"
+	StatusTerminated   = "terminated"
+	StatusRehired      = "rehired"
+)
+
+// Actions moving an employee between statuses, each has its own route
+const (
+	ActionOnboard   = "onboard"
+	ActionActivate  = "activate"
+	ActionLeave     = "leave"
+	ActionNotice    = "notice"
+	ActionTerminate = "terminate"
+	ActionRehire    = "rehire"
+)
+
+// DateLayout is the layout of the hire date and the last working day
+const DateLayout = "2006-01-02"
+
+// Violation messages of a transition request
+const (
+	RequiredMessage      = "%s is required to %s an employee"
+	DateOrderMessage     = "%s must not be before %s"
+	RehireDateMessage    = "%s must be after the termination date"
+	DateMessage          = "%s must be a date like %s"
+	UnknownStatusMessage = "%s must be a comma separated list of %s"
+	TransitionMessage    = "an employee in status %s can not be moved by %s"
+	InitialStatusMessage = "%s must be one of %s"
+)
+
+// json fields of a transition request
+const (
+	hireDateField       = "hire_date"
+	lastWorkingDayField = "last_working_day"
+	reasonField         = "reason"
+	statusField         = "employment_status"
+)
+
+/*
+Transition : an action of the state machine. It moves an employee in one
+of the From statuses to To and sets the dates of the request it requires.
+*/
+type Transition struct {
+	Action string
+	From   []string
+	To     string
+	// RequiresHireDate sets the hire date of the employee
+	RequiresHireDate bool
+	// RequiresLastWorkingDay sets the termination date of the employee
+	RequiresLastWorkingDay bool
+	RequiresReason         bool
+}
+
+// transitions of the state machine by action, every other change of the
+// status is refused
+var transitions = map[string]Transition{
+	ActionOnboard: {
+		Action: ActionOnboard, From: []string{StatusCandidate}, To: StatusOnboarding,
+		RequiresHireDate: true,
+	},
+	// activate also ends a leave and withdraws a notice
+	ActionActivate: {
+		Action: ActionActivate, From: []string{StatusOnboarding, StatusRehired, StatusOnLeave, StatusNoticePeriod},
+		To: StatusActive,
+	},
+	ActionLeave: {
+		Action: ActionLeave, From: []string{StatusActive}, To: StatusOnLeave,
+	},
+	ActionNotice: {
+		Action: ActionNotice, From: []string{StatusActive, StatusOnLeave}, To: StatusNoticePeriod,
+		RequiresLastWorkingDay: true,
+	},
+	ActionTerminate: {
+		Action: ActionTerminate, From: []string{StatusOnboarding, StatusActive, StatusOnLeave, StatusNoticePeriod},
+		To: StatusTerminated, RequiresLastWorkingDay: true, RequiresReason: true,
+	},
+	ActionRehire: {
+		Action: ActionRehire, From: []string{StatusTerminated}, To: StatusRehired,
+		RequiresHireDate: true,
+	},
+}
+
+// statuses in the order of the lifecycle
+var statuses = []string{
+	StatusCandidate, StatusOnboarding, StatusActive, StatusOnLeave,
+	StatusNoticePeriod, StatusTerminated, StatusRehired,
+}
+
+// initialStatuses are the statuses an employee may be created in
+var initialStatuses = []string{StatusCandidate, StatusOnboarding, StatusActive}
+
+// Lookup returns the transition of the action
+func Lookup(action string) (Transition, bool) {
+	transition, ok := transitions[action]
+	return transition, ok
+}
+
+// Allows reports whether the transition moves an employee in status from
+func (t Transition) Allows(from string) bool {
+	for _, status := range t.From {
+		if status == from {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Apply : checks the request against the transition and the employee and
+returns the employee in its new status with its new dates, or the
+violations keyed by the json field name
+
+Parameters
+----------
+t: transition of the request
+employee: stored employee
+request: transition request
+*/
+func (t Transition) Apply(employee models.Employee,
+	request global.DecodeEmploymentTransitionRequest) (models.Employee, map[string]string) {
+
+	violations := make(map[string]string)
+	if t.RequiresReason && strings.TrimSpace(request.Reason) == "" {
+		violations[reasonField] = fmt.Sprintf(RequiredMessage, reasonField, t.Action)
+	}
+	hireDate := requiredDate(violations, hireDateField, request.HireDate, t.RequiresHireDate, t.Action)
+	lastWorkingDay := requiredDate(violations, lastWorkingDayField, request.LastWorkingDay,
+		t.RequiresLastWorkingDay, t.Action)
+
+	switch {
+	case lastWorkingDay != nil && employee.HireDate != nil && lastWorkingDay.Before(*employee.HireDate):
+		violations[lastWorkingDayField] = fmt.Sprintf(DateOrderMessage, lastWorkingDayField, hireDateField)
+	case hireDate != nil && employee.TerminationDate != nil && !hireDate.After(*employee.TerminationDate):
+		violations[hireDateField] = fmt.Sprintf(RehireDateMessage, hireDateField)
+	}
+	if len(violations) > 0 {
+		return employee, violations
+	}
+
+	employee.EmploymentStatus = t.To
+	if hireDate != nil {
+		employee.HireDate = hireDate
+	}
+	switch {
+	case lastWorkingDay != nil:
+		employee.TerminationDate = lastWorkingDay
+	case t.To == StatusActive || t.To == StatusRehired:
+		// a withdrawn notice or a rehire ends no employment
+		employee.TerminationDate = nil
+	}
+	return employee, nil
+}
+
+// CheckNew returns the violations of the status and the hire date of a
+// new employee keyed by the json field name
+func CheckNew(employee global.DecodeEmployee) map[string]string {
+	violations := make(map[string]string)
+	if employee.EmploymentStatus != "" && !contains(initialStatuses, employee.EmploymentStatus) {
+		violations[statusField] = fmt.Sprintf(InitialStatusMessage, statusField,
+			strings.Join(initialStatuses, ", "))
+	}
+	requiredDate(violations, hireDateField, employee.HireDate, false, "")
+	return violations
+}
+
+/*
+ParseStatusFilter : parses the status query param of a listing, a comma
+separated list of statuses. Listings without it exclude the terminated
+employees, so that the total is the headcount.
+
+Parameters
+----------
+values: values of the status query param
+*/
+func ParseStatusFilter(values []string) ([]string, map[string]string) {
+	selected := make([]string, 0)
+	for _, value := range values {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.ToLower(strings.TrimSpace(status))
+			if status == "" {
+				continue
+			}
+			if !contains(statuses, status) {
+				return nil, map[string]string{"status": fmt.Sprintf(UnknownStatusMessage, "status",
+					strings.Join(statuses, ", "))}
+			}
+			selected = append(selected, status)
+		}
+	}
+	return selected, nil
+}
+
+// ParseDate parses a date of DateLayout as midnight UTC
+func ParseDate(value string) (*time.Time, error) {
+	date, err := time.ParseInLocation(DateLayout, value, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// requiredDate parses the date of the field, a missing required date is a
+// violation
+func requiredDate(violations map[string]string, field string, value string, required bool,
+	action string) *time.Time {
+
+	if value == "" {
+		if required {
+			violations[field] = fmt.Sprintf(RequiredMessage, field, action)
+		}
+		return nil
+	}
+	date, err := ParseDate(value)
+	if err != nil {
+		violations[field] = fmt.Sprintf(DateMessage, field, DateLayout)
+		return nil
+	}
+	return date
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
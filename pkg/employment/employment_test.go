@@ -0,0 +1,138 @@
+package employment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitions(t *testing.T) {
+	hired := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	left := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		action             string
+		employee           models.Employee
+		request            global.DecodeEmploymentTransitionRequest
+		allowed            bool
+		expectedStatus     string
+		expectedViolations []string
+		expectedHire       *time.Time
+		expectedEnd        *time.Time
+	}{
+		{
+			name:           "Onboard a candidate",
+			action:         ActionOnboard,
+			employee:       models.Employee{EmploymentStatus: StatusCandidate},
+			request:        global.DecodeEmploymentTransitionRequest{HireDate: "2024-01-01"},
+			allowed:        true,
+			expectedStatus: StatusOnboarding,
+			expectedHire:   &hired,
+		},
+		{
+			name:               "Onboard without a hire date",
+			action:             ActionOnboard,
+			employee:           models.Employee{EmploymentStatus: StatusCandidate},
+			allowed:            true,
+			expectedViolations: []string{"hire_date"},
+		},
+		{
+			name:     "Terminate a candidate",
+			action:   ActionTerminate,
+			employee: models.Employee{EmploymentStatus: StatusCandidate},
+		},
+		{
+			name:               "Terminate without a reason and a last working day",
+			action:             ActionTerminate,
+			employee:           models.Employee{EmploymentStatus: StatusActive},
+			request:            global.DecodeEmploymentTransitionRequest{Reason: " "},
+			allowed:            true,
+			expectedViolations: []string{"reason", "last_working_day"},
+		},
+		{
+			name:               "Terminate before the hire date",
+			action:             ActionTerminate,
+			employee:           models.Employee{EmploymentStatus: StatusActive, HireDate: &hired},
+			request:            global.DecodeEmploymentTransitionRequest{Reason: "Resigned", LastWorkingDay: "2023-12-31"},
+			allowed:            true,
+			expectedViolations: []string{"last_working_day"},
+		},
+		{
+			name:           "Terminate during the notice period",
+			action:         ActionTerminate,
+			employee:       models.Employee{EmploymentStatus: StatusNoticePeriod, HireDate: &hired},
+			request:        global.DecodeEmploymentTransitionRequest{Reason: "Resigned", LastWorkingDay: "2024-06-30"},
+			allowed:        true,
+			expectedStatus: StatusTerminated,
+			expectedHire:   &hired,
+			expectedEnd:    &left,
+		},
+		{
+			name:     "Leave while terminated",
+			action:   ActionLeave,
+			employee: models.Employee{EmploymentStatus: StatusTerminated},
+		},
+		{
+			name:           "Withdraw a notice",
+			action:         ActionActivate,
+			employee:       models.Employee{EmploymentStatus: StatusNoticePeriod, TerminationDate: &left},
+			allowed:        true,
+			expectedStatus: StatusActive,
+		},
+		{
+			name:               "Rehire before the termination date",
+			action:             ActionRehire,
+			employee:           models.Employee{EmploymentStatus: StatusTerminated, TerminationDate: &left},
+			request:            global.DecodeEmploymentTransitionRequest{HireDate: "2024-06-30"},
+			allowed:            true,
+			expectedViolations: []string{"hire_date"},
+		},
+		{
+			name:     "Rehire an active employee",
+			action:   ActionRehire,
+			employee: models.Employee{EmploymentStatus: StatusActive},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transition, ok := Lookup(tc.action)
+			assert.True(t, ok)
+			assert.Equal(t, tc.allowed, transition.Allows(tc.employee.EmploymentStatus))
+			if !tc.allowed {
+				return
+			}
+
+			next, violations := transition.Apply(tc.employee, tc.request)
+			if len(tc.expectedViolations) > 0 {
+				for _, field := range tc.expectedViolations {
+					assert.Contains(t, violations, field)
+				}
+				assert.Len(t, violations, len(tc.expectedViolations))
+				assert.Equal(t, tc.employee, next)
+				return
+			}
+			assert.Empty(t, violations)
+			assert.Equal(t, tc.expectedStatus, next.EmploymentStatus)
+			assert.Equal(t, tc.expectedHire, next.HireDate)
+			assert.Equal(t, tc.expectedEnd, next.TerminationDate)
+		})
+	}
+}
+
+func TestParseStatusFilter(t *testing.T) {
+	statuses, violations := ParseStatusFilter([]string{"active, On_Leave", "terminated"})
+	assert.Empty(t, violations)
+	assert.Equal(t, []string{StatusActive, StatusOnLeave, StatusTerminated}, statuses)
+
+	statuses, violations = ParseStatusFilter(nil)
+	assert.Empty(t, violations)
+	assert.Empty(t, statuses)
+
+	_, violations = ParseStatusFilter([]string{"active,retired"})
+	assert.Contains(t, violations, "status")
+}
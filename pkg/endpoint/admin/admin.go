@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// EndPoints : All the Admin endpoints structure
+type EndPoints struct {
+	GetLogLevel endpoint.Endpoint
+	SetLogLevel endpoint.Endpoint
+}
+
+func NewEndPoint() EndPoints {
+
+	return EndPoints{
+		GetLogLevel: makeGetLogLevel(),
+		SetLogLevel: makeSetLogLevel(),
+	}
+}
+
+func makeGetLogLevel() endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+
+		return global.SuccessGETInfo{
+			Data: zaplogger.Levels(),
+		}, nil
+	}
+}
+
+func makeSetLogLevel() endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		req, ok := request.(global.DecodeLogLevelPUTRequest)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeLogLevelPUTError)
+			return nil, errs.InternalErr()
+		}
+
+		err = zaplogger.SetLevel(req.Output, req.Level)
+		if err != nil {
+			zaplogger.Error(ctx, errs.SetLogLevelError, zap.Error(err))
+			return nil, errs.BadRequest(err.Error())
+		}
+		zaplogger.Warn(ctx, global.LogLevelUpdatedSuccessfully,
+			zap.String("output", req.Output),
+			zap.String("level", req.Level),
+		)
+
+		return global.SuccessGETInfo{
+			Data: zaplogger.Levels(),
+		}, nil
+	}
+}
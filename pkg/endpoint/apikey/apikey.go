@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+)
+
+// EndPoints : All the API Key endpoints structure
+type EndPoints struct {
+	CreateAPIKey endpoint.Endpoint
+	ListAPIKeys  endpoint.Endpoint
+	RevokeAPIKey endpoint.Endpoint
+}
+
+func NewEndPoint(svc service.APIKeyService) EndPoints {
+
+	return EndPoints{
+		CreateAPIKey: makeCreateAPIKey(svc),
+		ListAPIKeys:  makeListAPIKeys(svc),
+		RevokeAPIKey: makeRevokeAPIKey(svc),
+	}
+}
+
+func makeCreateAPIKey(svc service.APIKeyService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		req, ok := request.(global.DecodeAPIKeyPOSTRequest)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeAPIKeyStructError)
+			return nil, errs.InternalErr()
+		}
+		created, err := svc.CreateAPIKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: created,
+		}, nil
+	}
+}
+
+func makeListAPIKeys(svc service.APIKeyService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		keys, err := svc.ListAPIKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: keys,
+		}, nil
+	}
+}
+
+func makeRevokeAPIKey(svc service.APIKeyService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		req, ok := request.(int)
+		if !ok {
+			zaplogger.Error(ctx, errs.ConvertToIntError)
+			return nil, errs.InternalErr()
+		}
+		err = svc.RevokeAPIKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessInfo{
+			Message: global.APIKeyRevokedSuccessfully,
+			Type:    global.Success,
+		}, nil
+	}
+}
@@ -0,0 +1,109 @@
+package asyncjob
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/jainabhishek5986/employee-records/pkg/asyncjob"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/jobs"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+)
+
+// EndPoints : All the asynchronous job endpoints structure
+type EndPoints struct {
+	ImportEmployees   endpoint.Endpoint
+	ExportEmployees   endpoint.Endpoint
+	UpdateEmployees   endpoint.Endpoint
+	GetJob            endpoint.Endpoint
+	CancelJob         endpoint.Endpoint
+	DownloadJobOutput endpoint.Endpoint
+}
+
+func NewEndPoint(svc service.AsyncJobService) EndPoints {
+
+	return EndPoints{
+		ImportEmployees:   makeSubmitJob(svc, jobs.ImportEmployees),
+		ExportEmployees:   makeSubmitJob(svc, jobs.ExportEmployees),
+		UpdateEmployees:   makeSubmitJob(svc, jobs.UpdateEmployees),
+		GetJob:            makeGetJob(svc),
+		CancelJob:         makeCancelJob(svc),
+		DownloadJobOutput: makeDownloadJobOutput(svc),
+	}
+}
+
+// makeSubmitJob queues the decoded request as the payload of a job of
+// the kind
+func makeSubmitJob(svc service.AsyncJobService, kind string) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		job, err := svc.SubmitJob(ctx, kind, request)
+		if err != nil {
+			return nil, err
+		}
+
+		// the job runs in the background, its status is polled at the
+		// location
+		return global.SuccessAcceptedInfo{
+			Data:     job,
+			Location: asyncjob.Location(job.ID),
+		}, nil
+	}
+}
+
+func makeGetJob(svc service.AsyncJobService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		id, ok := request.(string)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeAsyncJobIDError)
+			return nil, errs.InternalErr()
+		}
+		job, err := svc.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: job,
+		}, nil
+	}
+}
+
+func makeCancelJob(svc service.AsyncJobService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		id, ok := request.(string)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeAsyncJobIDError)
+			return nil, errs.InternalErr()
+		}
+		job, err := svc.CancelJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: job,
+		}, nil
+	}
+}
+
+func makeDownloadJobOutput(svc service.AsyncJobService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		id, ok := request.(string)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeAsyncJobIDError)
+			return nil, errs.InternalErr()
+		}
+
+		return svc.GetJobOutput(ctx, id)
+	}
+}
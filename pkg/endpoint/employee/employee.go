@@ -6,7 +6,9 @@ import (
 	"github.com/go-kit/kit/endpoint"
 	"github.com/jainabhishek5986/employee-records/pkg/errs"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/metrics"
 	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/tracing"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 )
 
@@ -17,19 +19,44 @@ type EndPoints struct {
 	UpdateEmployeeByID endpoint.Endpoint
 	DeleteEmployeeByID endpoint.Endpoint
 	GetAllEmployee     endpoint.Endpoint
+	BulkUpdate         endpoint.Endpoint
+	BulkDelete         endpoint.Endpoint
+	// Employment transitions, one per action of pkg/employment
+	Onboard   endpoint.Endpoint
+	Activate  endpoint.Endpoint
+	Leave     endpoint.Endpoint
+	Notice    endpoint.Endpoint
+	Terminate endpoint.Endpoint
+	Rehire    endpoint.Endpoint
 }
 
 func NewEndPoint(svc service.EmployeeService) EndPoints {
 
 	return EndPoints{
-		CreateEmployee:     makeCreateEmployee(svc),
-		GetEmployeeByID:    makeGetEmployeeByID(svc),
-		UpdateEmployeeByID: makeUpdateEmployeeByID(svc),
-		DeleteEmployeeByID: makeDeleteEmployeeByID(svc),
-		GetAllEmployee:     makeGetAllEmployee(svc),
+		CreateEmployee:     instrument("CreateEmployee", makeCreateEmployee(svc)),
+		GetEmployeeByID:    instrument("GetEmployeeByID", makeGetEmployeeByID(svc)),
+		UpdateEmployeeByID: instrument("UpdateEmployeeByID", makeUpdateEmployeeByID(svc)),
+		DeleteEmployeeByID: instrument("DeleteEmployeeByID", makeDeleteEmployeeByID(svc)),
+		GetAllEmployee:     instrument("GetAllEmployee", makeGetAllEmployee(svc)),
+		BulkUpdate:         instrument("BulkUpdateEmployees", makeBulkUpdateEmployees(svc)),
+		BulkDelete:         instrument("BulkDeleteEmployees", makeBulkDeleteEmployees(svc)),
+		Onboard:            instrument("OnboardEmployee", makeChangeEmploymentStatus(svc)),
+		Activate:           instrument("ActivateEmployee", makeChangeEmploymentStatus(svc)),
+		Leave:              instrument("StartEmployeeLeave", makeChangeEmploymentStatus(svc)),
+		Notice:             instrument("GiveEmployeeNotice", makeChangeEmploymentStatus(svc)),
+		Terminate:          instrument("TerminateEmployee", makeChangeEmploymentStatus(svc)),
+		Rehire:             instrument("RehireEmployee", makeChangeEmploymentStatus(svc)),
 	}
 }
 
+// instrument wraps an endpoint with tracing and metrics middlewares
+func instrument(name string, ep endpoint.Endpoint) endpoint.Endpoint {
+	return endpoint.Chain(
+		tracing.TraceEndpoint(name),
+		metrics.InstrumentEndpoint(name),
+	)(ep)
+}
+
 func makeCreateEmployee(svc service.EmployeeService) endpoint.Endpoint {
 
 	return func(ctx context.Context, request interface{}) (response interface{},
@@ -138,3 +165,63 @@ func makeGetAllEmployee(svc service.EmployeeService) endpoint.Endpoint {
 		return res, err
 	}
 }
+
+func makeBulkUpdateEmployees(svc service.EmployeeService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		req, ok := request.(global.DecodeEmployeesPATCHRequest)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeEmployeesStructError)
+			return nil, errs.InternalErr()
+		}
+		result, err := svc.BulkUpdateEmployees(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: result,
+		}, nil
+	}
+}
+
+func makeBulkDeleteEmployees(svc service.EmployeeService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		req, ok := request.(global.DecodeEmployeesDELETERequest)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeEmployeesStructError)
+			return nil, errs.InternalErr()
+		}
+		result, err := svc.BulkDeleteEmployees(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: result,
+		}, nil
+	}
+}
+
+// makeChangeEmploymentStatus serves every transition, the decoder of the
+// route sets its action
+func makeChangeEmploymentStatus(svc service.EmployeeService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		req, ok := request.(global.DecodeEmploymentTransitionRequest)
+		if !ok {
+			zaplogger.Error(ctx, errs.StructDecodeError)
+			return nil, errs.InternalErr()
+		}
+		res, err := svc.ChangeEmploymentStatus(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return res, nil
+	}
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+)
+
+// EndPoints : All the job endpoints structure
+type EndPoints struct {
+	ListJobs   endpoint.Endpoint
+	TriggerJob endpoint.Endpoint
+}
+
+func NewEndPoint(svc service.JobService) EndPoints {
+
+	return EndPoints{
+		ListJobs:   makeListJobs(svc),
+		TriggerJob: makeTriggerJob(svc),
+	}
+}
+
+func makeListJobs(svc service.JobService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		jobs, err := svc.ListJobs(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return global.SuccessGETInfo{
+			Data: jobs,
+		}, nil
+	}
+}
+
+func makeTriggerJob(svc service.JobService) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (response interface{},
+		err error) {
+		name, ok := request.(string)
+		if !ok {
+			zaplogger.Error(ctx, errs.DecodeJobNameError)
+			return nil, errs.InternalErr()
+		}
+		run, err := svc.TriggerJob(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		// the run continues in the background, its outcome is listed at
+		// /admin/jobs
+		return global.SuccessAcceptedInfo{
+			Data:     run,
+			Location: "/admin/jobs",
+		}, nil
+	}
+}
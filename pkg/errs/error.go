@@ -14,6 +14,7 @@ type HTTPError struct {
 	Status      int         `json:"status"`
 	Message     interface{} `json:"message"`
 	Errors      interface{} `json:"errors,omitempty"`
+	RequestID   string      `json:"request_id,omitempty"`
 	HTTPHeaders http.Header
 }
 
@@ -50,12 +51,13 @@ func (e *HTTPError) MarshalJSON() ([]byte, error) {
 
 	// Error response structure
 	val := struct {
-		Type    string      `json:"type"`
-		Title   string      `json:"title,omitempty"`
-		Message interface{} `json:"message"`
-		Errors  interface{} `json:"errors,omitempty"`
+		Type      string      `json:"type"`
+		Title     string      `json:"title,omitempty"`
+		Message   interface{} `json:"message"`
+		Errors    interface{} `json:"errors,omitempty"`
+		RequestID string      `json:"request_id,omitempty"`
 	}{Type: e.Type, Title: e.Title, Message: e.Message,
-		Errors: e.Errors}
+		Errors: e.Errors, RequestID: e.RequestID}
 
 	return json.Marshal(val)
 }
@@ -66,15 +68,18 @@ type HTTPErr struct {
 	Message     ErrMessage
 	HTTPHeaders http.Header
 	Ms          []interface{}
+	RequestID   string
 }
 
 func (e *HTTPErr) MarshalJSON() ([]byte, error) {
 	val := struct {
-		Type    string      `json:"type"`
-		Title   string      `json:"title"`
-		Status  int         `json:"status"`
-		Message interface{} `json:"message"`
-	}{Type: "error", Title: e.Title, Status: e.Status, Message: e.Ms}
+		Type      string      `json:"type"`
+		Title     string      `json:"title"`
+		Status    int         `json:"status"`
+		Message   interface{} `json:"message"`
+		RequestID string      `json:"request_id,omitempty"`
+	}{Type: "error", Title: e.Title, Status: e.Status, Message: e.Ms,
+		RequestID: e.RequestID}
 
 	return json.Marshal(val)
 }
@@ -99,3 +104,25 @@ func (e *HTTPErr) StatusCode() int {
 	return http.StatusOK
 
 }
+
+// WithRequestID returns a copy of err carrying the request ID in its body,
+// so that clients can quote it when reporting a problem. Errors not created
+// by this package are returned as is.
+func WithRequestID(err error, requestID string) error {
+	if requestID == "" {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *HTTPError:
+		withID := *e
+		withID.RequestID = requestID
+		return &withID
+	case *HTTPErr:
+		withID := *e
+		withID.RequestID = requestID
+		return &withID
+	default:
+		return err
+	}
+}
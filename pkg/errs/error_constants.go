@@ -5,6 +5,10 @@ const (
 	BadRequestTitle          = "Bad Request Error"
 	InternalServerErrorTitle = "Internal Server Error"
 	UnathorizedErrorTitle    = "Unauthorized Error"
+	PayloadTooLargeTitle     = "Payload Too Large"
+	ConflictTitle            = "Conflict"
+	UnavailableTitle         = "Service Unavailable"
+	NotFoundTitle            = "Not Found"
 )
 
 // Error Message
@@ -20,9 +24,14 @@ const (
 	SyntaxErrorMessageDetatil          = "Please, Body Payload format is not correct"
 	InputErrorMessageDetatil           = "Please, give correct input value for the %q field"
 	MissingFieldErrorMessageDetail     = "Required params are missing"
-	BodyPayloadLimitErrorMessageDetail = "Request body must not be larger than 1MB"
+	BodyPayloadLimitErrorMessageDetail = "Request body is larger than the allowed limit"
+	EmptyBodyErrorMessageDetail        = "Request body must not be empty"
+	UnknownFieldErrorMessageDetail     = "Unknown field %q in the body payload"
+	DuplicateKeyErrorMessageDetail     = "Duplicate key %q in the body payload"
+	TrailingDataErrorMessageDetail     = "Body payload must contain a single JSON document"
 	UnprocessableEntityMessage         = "Request Not Processed"
 	TooManyRequests                    = "Too Many Requests"
+	RateLimitExceededMessage           = "Rate limit exceeded, retry after the Retry-After delay"
 )
 
 // DB Errors
@@ -30,6 +39,23 @@ const (
 	CommitTransactionError = "Transaction Commit Error"
 )
 
+// Config
+const (
+	LoadConfigError          = "Failed to load config"
+	InvalidConfigKeyError    = "Invalid config key"
+	ConfigReloadError        = "Error while reloading config"
+	ConfigNotReloadableError = "Config keys changed that are only applied on restart"
+	ConfigRollbackError      = "Error while restoring config after a failed reload"
+)
+
+// Lifecycle
+const (
+	ComponentStartError   = "Error while starting component"
+	ComponentStopError    = "Error while stopping component"
+	ComponentFailedError  = "Component failed, shutting down"
+	ShutdownDeadlineError = "Shutdown deadline exceeded"
+)
+
 // General Errors
 const (
 	StartServerError    = "Start Server Error"
@@ -51,4 +77,102 @@ const (
 	EmployeeUpdateError        = "Error while updating employee from db"
 	DeleteEmployeeError        = "Error while deleting employee from db"
 	DecodeEmployeesStructError = "Error while decoding employees struct"
+	EmployeeValidationError    = "Employee payload violates validation rules"
+	EmployeeConflictError      = "Employee position or salary changed while the update was applied"
+)
+
+// Bulk operations
+const (
+	DecodeEmployeesPATCHError  = "Error while decoding Employee PATCH request"
+	DecodeEmployeesDELETEError = "Error while decoding Employee DELETE request"
+	BulkSelectorError          = "Either updates or a filter with changes is required"
+	BulkEmptyFilterError       = "Filter must have at least one criterion"
+	BulkNoChangesError         = "Changes must set at least one field"
+	BulkTooManyEmployeesError  = "Bulk operation selects too many employees"
+	BulkRolledBackError        = "Bulk operation rolled back, no employee was changed"
+)
+
+// Admin
+const (
+	AdminDisabledError     = "Admin API is disabled"
+	AdminTokenError        = "Invalid admin token"
+	DecodeLogLevelPUTError = "Error while decoding log level PUT request"
+	SetLogLevelError       = "Error while setting log level"
+)
+
+// CORS
+const (
+	CORSPreflightRejected = "Rejected CORS preflight request"
+)
+
+// Rate limit
+const (
+	RateLimitStoreError = "Error while taking a rate limit token"
+)
+
+// API keys
+const (
+	APIKeyInvalidError       = "Invalid API key"
+	AuthenticationRequired   = "Authentication required"
+	InsufficientScopeError   = "Missing scope for this route"
+	APIKeyNoRecordFoundError = "Invalid API key ID"
+	APIKeyNewRecordError     = "Error while creating API key"
+	APIKeyFetchRecordsError  = "Error while fetching API keys"
+	APIKeyUpdateError        = "Error while updating API key"
+	APIKeyGenerateError      = "Error while generating API key"
+	DecodeAPIKeyPOSTError    = "Error while decoding API key POST request"
+	DecodeAPIKeyStructError  = "Error while decoding API key struct"
+)
+
+// Jobs
+const (
+	JobNotFoundError         = "Unknown job"
+	JobAlreadyRunningError   = "Job is already running"
+	JobDisabledError         = "Job is disabled"
+	SchedulerNotRunningError = "Scheduler is not running"
+	JobRunRecordError        = "Error while recording job run"
+	JobRunFetchError         = "Error while fetching job runs"
+	JobLeaseError            = "Error while acquiring job lease"
+	JobRunFailedError        = "Job run failed"
+	JobRunAbandonedError     = "Job run abandoned, its instance lost the job lease"
+	JobPurgeError            = "Error while purging rows"
+	DecodeJobNameError       = "Error while decoding job name"
+)
+
+// Tenancy
+const (
+	TenantRequiredError = "Credentials do not belong to a tenant"
+	TenantMismatchError = "Credentials belong to another tenant"
+	TenantNotFoundError = "Unknown tenant"
+	TenantResolveError  = "Error while resolving tenant"
+)
+
+// Async jobs
+const (
+	AsyncJobNoRecordFoundError = "Invalid job ID"
+	AsyncJobQueueFullError     = "Job queue is full, retry later"
+	AsyncJobFinishedError      = "Job already finished"
+	AsyncJobNoOutputError      = "Job has no output to download"
+	AsyncJobUnknownKindError   = "Unknown job kind"
+	AsyncJobNewRecordError     = "Error while creating job"
+	AsyncJobFetchRecordsError  = "Error while fetching job"
+	AsyncJobUpdateError        = "Error while updating job"
+	AsyncJobRunFailedError     = "Asynchronous job failed"
+	AsyncJobInterruptedError   = "Job interrupted by a shutdown, the rows processed before are kept"
+	AsyncJobLostError          = "Job lost its worker, the rows processed before are kept"
+	DecodeAsyncJobIDError      = "Error while decoding job ID"
+)
+
+// Read replicas
+const (
+	ReplicaUnhealthyError = "Replica is not answering, its reads go to the healthy replicas or the primary"
+	ReplicaOpenError      = "Error while opening replica"
+)
+
+// Employment
+const (
+	EmploymentTransitionError  = "Error while changing employment status"
+	EmploymentConflictError    = "Employment status changed while the transition was applied"
+	EmploymentValidationError  = "Employment transition request failed validation"
+	EmploymentStatusQueryError = "Invalid employment status filter"
 )
@@ -30,6 +30,18 @@ func RequestNotProcessed(message interface{}) error {
 		message)
 }
 
+// RequestNotProcessedWithErrors error response object listing the errors
+// of the individual items of a request
+func RequestNotProcessedWithErrors(message interface{}, errors interface{}) error {
+	err := &HTTPError{
+		Title:   UnprocessableEntityMessage,
+		Status:  http.StatusUnprocessableEntity,
+		Message: message,
+		Errors:  errors,
+	}
+	return err
+}
+
 // RequestRatelimitExceeded error response object
 func RequestRatelimitExceeded(message interface{}) error {
 	return ErrRes(TooManyRequests,
@@ -37,6 +49,28 @@ func RequestRatelimitExceeded(message interface{}) error {
 		message)
 }
 
+// ConflictErr error response object
+func ConflictErr(message interface{}) error {
+	return ErrRes(ConflictTitle,
+		http.StatusConflict,
+		message)
+}
+
+// NotFoundErr error response object, also returned for records of other
+// tenants so that their existence is not leaked
+func NotFoundErr(message interface{}) error {
+	return ErrRes(NotFoundTitle,
+		http.StatusNotFound,
+		message)
+}
+
+// UnavailableErr error response object
+func UnavailableErr(message interface{}) error {
+	return ErrRes(UnavailableTitle,
+		http.StatusServiceUnavailable,
+		message)
+}
+
 // Internal error with message response object
 func InternalErrWithMsg(message string) error {
 	return ErrRes(InternalServerErrorTitle,
@@ -64,11 +98,47 @@ func ErrorReqHandler(err error) error {
 	var (
 		syntaxError        *json.SyntaxError
 		unmarshalTypeError *json.UnmarshalTypeError
+		maxBytesError      *http.MaxBytesError
+		unknownFieldError  *UnknownFieldError
+		duplicateKeyError  *DuplicateKeyError
+		trailingDataError  *TrailingDataError
 	)
 
 	errMsg := make([]interface{}, 0)
 
 	switch {
+	case errors.As(err, &maxBytesError):
+		errMsg = append(errMsg, ErrMessage{
+			Key:    "ExceedLimit",
+			Detail: BodyPayloadLimitErrorMessageDetail})
+
+		return ErrResponse(PayloadTooLargeTitle,
+			http.StatusRequestEntityTooLarge, errMsg)
+
+	case errors.As(err, &unknownFieldError):
+		errMsg = append(errMsg, ErrMessage{
+			Key:    "UnknownField",
+			Detail: fmt.Sprintf(UnknownFieldErrorMessageDetail, unknownFieldError.Pointer)})
+
+		return ErrResponse(BadRequestTitle,
+			http.StatusBadRequest, errMsg)
+
+	case errors.As(err, &duplicateKeyError):
+		errMsg = append(errMsg, ErrMessage{
+			Key:    "DuplicateKey",
+			Detail: fmt.Sprintf(DuplicateKeyErrorMessageDetail, duplicateKeyError.Pointer)})
+
+		return ErrResponse(BadRequestTitle,
+			http.StatusBadRequest, errMsg)
+
+	case errors.As(err, &trailingDataError):
+		errMsg = append(errMsg, ErrMessage{
+			Key:    "TrailingData",
+			Detail: TrailingDataErrorMessageDetail})
+
+		return ErrResponse(BadRequestTitle,
+			http.StatusBadRequest, errMsg)
+
 	case errors.As(err, &syntaxError):
 		errMsg = append(errMsg, ErrMessage{
 			Key:    "SyntaxError",
@@ -106,8 +176,8 @@ func ErrorReqHandler(err error) error {
 
 	case errors.Is(err, io.EOF):
 		errMsg = append(errMsg, ErrMessage{
-			Key:    "ExceedLimit",
-			Detail: BodyPayloadLimitErrorMessageDetail})
+			Key:    "EmptyBody",
+			Detail: EmptyBodyErrorMessageDetail})
 
 		return ErrResponse(BadRequestTitle,
 			http.StatusBadRequest, errMsg)
@@ -0,0 +1,32 @@
+package errs
+
+import "fmt"
+
+// UnknownFieldError : the body payload has a key the request does not
+// define. Pointer is the RFC 6901 JSON pointer of the key.
+type UnknownFieldError struct {
+	Pointer string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("json: unknown field %q", e.Pointer)
+}
+
+// DuplicateKeyError : an object of the body payload repeats a key.
+// Pointer is the RFC 6901 JSON pointer of the repeated key.
+type DuplicateKeyError struct {
+	Pointer string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("json: duplicate key %q", e.Pointer)
+}
+
+// TrailingDataError : the body payload continues after the JSON document
+type TrailingDataError struct {
+	Offset int64
+}
+
+func (e *TrailingDataError) Error() string {
+	return fmt.Sprintf("json: unexpected data after the document at offset %d", e.Offset)
+}
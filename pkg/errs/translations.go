@@ -0,0 +1,140 @@
+package errs
+
+// messageTranslations maps the english titles and messages of this package
+// to their localized form. English is the source language and is therefore
+// not listed.
+var messageTranslations = map[string]map[string]string{
+	"de": {
+		BadRequestTitle:                    "Ungültige Anfrage",
+		InternalServerErrorTitle:           "Interner Serverfehler",
+		UnathorizedErrorTitle:              "Nicht autorisiert",
+		PayloadTooLargeTitle:               "Nutzlast zu groß",
+		InternalServerErrorMessage:         "Entschuldigung! Etwas ist schiefgelaufen",
+		PayloadShouldBeEmpty:               "Entschuldigung! Der Anfrageinhalt muss leer sein",
+		BadQueryParams:                     "Ungültige Abfrageparameter",
+		BadRequestErrorMessageDetail:       "Bitte geben Sie gültige Werte im Anfrageinhalt an",
+		SyntaxErrorMessageDetatil:          "Bitte prüfen Sie das Format des Anfrageinhalts",
+		MissingFieldErrorMessageDetail:     "Erforderliche Parameter fehlen",
+		BodyPayloadLimitErrorMessageDetail: "Der Anfrageinhalt überschreitet das erlaubte Limit",
+		EmptyBodyErrorMessageDetail:        "Der Anfrageinhalt darf nicht leer sein",
+		TrailingDataErrorMessageDetail:     "Der Anfrageinhalt muss genau ein JSON-Dokument enthalten",
+		UnprocessableEntityMessage:         "Anfrage nicht verarbeitet",
+		TooManyRequests:                    "Zu viele Anfragen",
+		RateLimitExceededMessage:           "Ratenlimit überschritten, bitte nach der Retry-After-Wartezeit erneut versuchen",
+		EmployeeNoRecordFoundError:         "Ungültige Mitarbeiter-ID",
+		ConvertToIntError:                  "Fehler bei der Umwandlung in eine Ganzzahl",
+		APIKeyInvalidError:                 "Ungültiger API-Schlüssel",
+		AuthenticationRequired:             "Authentifizierung erforderlich",
+		InsufficientScopeError:             "Fehlende Berechtigung für diese Route",
+	},
+	"fr": {
+		BadRequestTitle:                    "Requête invalide",
+		InternalServerErrorTitle:           "Erreur interne du serveur",
+		UnathorizedErrorTitle:              "Non autorisé",
+		PayloadTooLargeTitle:               "Charge utile trop volumineuse",
+		InternalServerErrorMessage:         "Désolé ! Une erreur s'est produite",
+		PayloadShouldBeEmpty:               "Désolé ! Le corps de la requête doit être vide",
+		BadQueryParams:                     "Paramètres de requête invalides",
+		BadRequestErrorMessageDetail:       "Veuillez fournir des valeurs correctes dans le corps de la requête",
+		SyntaxErrorMessageDetatil:          "Le format du corps de la requête est incorrect",
+		MissingFieldErrorMessageDetail:     "Des paramètres obligatoires sont manquants",
+		BodyPayloadLimitErrorMessageDetail: "Le corps de la requête dépasse la limite autorisée",
+		EmptyBodyErrorMessageDetail:        "Le corps de la requête ne doit pas être vide",
+		TrailingDataErrorMessageDetail:     "Le corps de la requête doit contenir un seul document JSON",
+		UnprocessableEntityMessage:         "Requête non traitée",
+		TooManyRequests:                    "Trop de requêtes",
+		RateLimitExceededMessage:           "Limite de débit dépassée, réessayez après le délai Retry-After",
+		EmployeeNoRecordFoundError:         "Identifiant d'employé invalide",
+		ConvertToIntError:                  "Erreur lors de la conversion en entier",
+		APIKeyInvalidError:                 "Clé d'API invalide",
+		AuthenticationRequired:             "Authentification requise",
+		InsufficientScopeError:             "Autorisation manquante pour cette route",
+	},
+	"es": {
+		BadRequestTitle:                    "Solicitud incorrecta",
+		InternalServerErrorTitle:           "Error interno del servidor",
+		UnathorizedErrorTitle:              "No autorizado",
+		PayloadTooLargeTitle:               "Carga demasiado grande",
+		InternalServerErrorMessage:         "¡Lo sentimos! Algo salió mal",
+		PayloadShouldBeEmpty:               "¡Lo sentimos! El cuerpo de la solicitud debe estar vacío",
+		BadQueryParams:                     "Parámetros de consulta incorrectos",
+		BadRequestErrorMessageDetail:       "Por favor, introduzca valores correctos en el cuerpo de la solicitud",
+		SyntaxErrorMessageDetatil:          "El formato del cuerpo de la solicitud no es correcto",
+		MissingFieldErrorMessageDetail:     "Faltan parámetros obligatorios",
+		BodyPayloadLimitErrorMessageDetail: "El cuerpo de la solicitud supera el límite permitido",
+		EmptyBodyErrorMessageDetail:        "El cuerpo de la solicitud no debe estar vacío",
+		TrailingDataErrorMessageDetail:     "El cuerpo de la solicitud debe contener un único documento JSON",
+		UnprocessableEntityMessage:         "Solicitud no procesada",
+		TooManyRequests:                    "Demasiadas solicitudes",
+		RateLimitExceededMessage:           "Límite de solicitudes superado, reintente tras el tiempo de Retry-After",
+		EmployeeNoRecordFoundError:         "ID de empleado no válido",
+		ConvertToIntError:                  "Error al convertir a entero",
+		APIKeyInvalidError:                 "Clave de API no válida",
+		AuthenticationRequired:             "Se requiere autenticación",
+		InsufficientScopeError:             "Falta el permiso para esta ruta",
+	},
+	"hi": {
+		BadRequestTitle:                    "अमान्य अनुरोध",
+		InternalServerErrorTitle:           "आंतरिक सर्वर त्रुटि",
+		UnathorizedErrorTitle:              "अनधिकृत",
+		PayloadTooLargeTitle:               "पेलोड बहुत बड़ा है",
+		InternalServerErrorMessage:         "क्षमा करें! कुछ गलत हो गया",
+		PayloadShouldBeEmpty:               "क्षमा करें! अनुरोध का बॉडी खाली होना चाहिए",
+		BadQueryParams:                     "अमान्य क्वेरी पैरामीटर",
+		BadRequestErrorMessageDetail:       "कृपया अनुरोध बॉडी में सही मान दें",
+		SyntaxErrorMessageDetatil:          "कृपया अनुरोध बॉडी का प्रारूप जांचें",
+		MissingFieldErrorMessageDetail:     "आवश्यक पैरामीटर अनुपस्थित हैं",
+		BodyPayloadLimitErrorMessageDetail: "अनुरोध बॉडी अनुमत सीमा से बड़ी है",
+		EmptyBodyErrorMessageDetail:        "अनुरोध बॉडी खाली नहीं होनी चाहिए",
+		TrailingDataErrorMessageDetail:     "अनुरोध बॉडी में केवल एक JSON दस्तावेज़ होना चाहिए",
+		UnprocessableEntityMessage:         "अनुरोध संसाधित नहीं हुआ",
+		TooManyRequests:                    "बहुत अधिक अनुरोध",
+		RateLimitExceededMessage:           "दर सीमा पार हो गई, Retry-After अवधि के बाद पुनः प्रयास करें",
+		EmployeeNoRecordFoundError:         "अमान्य कर्मचारी आईडी",
+		ConvertToIntError:                  "पूर्णांक में बदलने में त्रुटि",
+		APIKeyInvalidError:                 "अमान्य API कुंजी",
+		AuthenticationRequired:             "प्रमाणीकरण आवश्यक है",
+		InsufficientScopeError:             "इस रूट के लिए अनुमति नहीं है",
+	},
+}
+
+// Translate returns the localized form of an english message of this
+// package, or the message itself if no translation is known
+func Translate(locale string, message string) string {
+	if translated, ok := messageTranslations[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Localize returns a copy of err with its title and messages translated to
+// the given locale. Errors not created by this package are returned as is.
+func Localize(err error, locale string) error {
+	if _, ok := messageTranslations[locale]; !ok {
+		return err
+	}
+
+	switch e := err.(type) {
+	case *HTTPError:
+		localized := *e
+		localized.Title = Translate(locale, e.Title)
+		if message, ok := e.Message.(string); ok {
+			localized.Message = Translate(locale, message)
+		}
+		return &localized
+	case *HTTPErr:
+		localized := *e
+		localized.Title = Translate(locale, e.Title)
+		localized.Ms = make([]interface{}, 0, len(e.Ms))
+		for _, m := range e.Ms {
+			if errMessage, ok := m.(ErrMessage); ok {
+				errMessage.Detail = Translate(locale, errMessage.Detail)
+				m = errMessage
+			}
+			localized.Ms = append(localized.Ms, m)
+		}
+		return &localized
+	default:
+		return err
+	}
+}
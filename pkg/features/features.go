@@ -0,0 +1,54 @@
+package features
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+)
+
+var flags atomic.Pointer[map[string]bool]
+
+func init() {
+	Set(nil)
+}
+
+// Set atomically replaces the feature flags, names are case insensitive
+func Set(values map[string]bool) {
+	normalized := make(map[string]bool, len(values))
+	for name, enabled := range values {
+		// viper lower cases map keys
+		normalized[strings.ToLower(name)] = enabled
+	}
+	flags.Store(&normalized)
+}
+
+// Enabled reports whether the feature is switched on, unknown features are
+// off
+func Enabled(name string) bool {
+	return (*flags.Load())[strings.ToLower(name)]
+}
+
+// EnabledFor reports whether the feature is switched on for the tenant of
+// ctx, Tenancy.Tenants.<id>.Features override the instance flags
+func EnabledFor(ctx context.Context, name string) bool {
+	if overrides, ok := tenant.Overrides(ctx); ok {
+		for feature, enabled := range overrides.Features {
+			if strings.EqualFold(feature, name) {
+				return enabled
+			}
+		}
+	}
+	return Enabled(name)
+}
+
+// All returns a copy of the feature flags
+func All() map[string]bool {
+	current := *flags.Load()
+	values := make(map[string]bool, len(current))
+	for name, enabled := range current {
+		values[name] = enabled
+	}
+	return values
+}
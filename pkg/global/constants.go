@@ -1,17 +1,29 @@
 package global
 
+import "time"
+
 const (
-	MaxAPIServerStartAttempts = 10
-	MaxConnections            = 100
-	MaxLifeTime               = 3
+	MaxConnections = 100
+	MaxLifeTime    = 3
+	// ReadinessDrainDelay is how long /readyz fails before the server stops
+	// accepting connections on shutdown
+	ReadinessDrainDelay = 5 * time.Second
 )
 
 // Variable Constants
 const (
-	Success         = "Success"
-	LogFileName     = "employee-records-service.log"
-	TestLogFileName = "./../employee-records-service-unit-tests.log"
-	SQL             = "mysql"
+	Success = "Success"
+	SQL     = "mysql"
+	DevEnv  = "dev"
+)
+
+// Results of a bulk operation per employee
+const (
+	BulkStatusUpdated    = "updated"
+	BulkStatusDeleted    = "deleted"
+	BulkStatusMatched    = "matched"
+	BulkStatusFailed     = "failed"
+	BulkStatusRolledBack = "rolled_back"
 )
 
 // Global Magic numbers
@@ -25,6 +37,12 @@ const (
 	SixtyFour     = 64
 )
 
+// Admin routes
+const (
+	GetLogLevelEndpoint = "GET: /admin/log-level"
+	SetLogLevelEndpoint = "PUT: /admin/log-level"
+)
+
 // API routes
 const (
 	CreateEmployeeEndpoint  = "POST: /employee"
@@ -1,8 +1,19 @@
 package global
 
 const (
-	EmployeeCreatedSuccessfully  = "Employees created successfully"
-	EmployeeDeletedSuccessfully  = "Employee deleted successfully"
-	EmployeeUpdatedSuccessfully  = "Employee updated successfully"
-	EmployeesSuccessfullyFetched = "Employee details fetched successfully"
+	EmployeeCreatedSuccessfully      = "Employees created successfully"
+	EmployeeDeletedSuccessfully      = "Employee deleted successfully"
+	EmployeeUpdatedSuccessfully      = "Employee updated successfully"
+	EmployeesSuccessfullyFetched     = "Employee details fetched successfully"
+	EmployeesBulkUpdatedSuccessfully = "Employees bulk updated successfully"
+	EmployeesBulkDeletedSuccessfully = "Employees bulk deleted successfully"
+	LogLevelUpdatedSuccessfully      = "Log level updated successfully"
+	APIKeyCreatedSuccessfully        = "API key created successfully"
+	APIKeyRevokedSuccessfully        = "API key revoked successfully"
+	ConfigReloadedSuccessfully       = "Config reloaded"
+	JobRunStartedSuccessfully        = "Job run started"
+	JobRunFinishedSuccessfully       = "Job run finished"
+	AsyncJobSubmittedSuccessfully    = "Job submitted"
+	AsyncJobCancelledSuccessfully    = "Job cancelled"
+	AsyncJobFinishedSuccessfully     = "Asynchronous job finished"
 )
@@ -1,5 +1,7 @@
 package global
 
+import "net/http"
+
 /*
 SuccessInfo : Success message
 */
@@ -12,3 +14,26 @@ type SuccessGETInfo struct {
 	Data       interface{} `json:"data"`
 	Pagination interface{} `json:"pagination,omitempty"`
 }
+
+/*
+SuccessAcceptedInfo : asynchronous work that was accepted, it is answered
+with 202 and Location pointing at its status
+*/
+type SuccessAcceptedInfo struct {
+	Data     interface{} `json:"data"`
+	Location string      `json:"-"`
+}
+
+// StatusCode implements the go-kit StatusCoder
+func (r SuccessAcceptedInfo) StatusCode() int {
+	return http.StatusAccepted
+}
+
+// Headers implements the go-kit Headerer
+func (r SuccessAcceptedInfo) Headers() http.Header {
+	headers := http.Header{}
+	if r.Location != "" {
+		headers.Set("Location", r.Location)
+	}
+	return headers
+}
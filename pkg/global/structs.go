@@ -1,18 +1,209 @@
 package global
 
+import "time"
+
 type DecodeEmployeesPOSTRequest struct {
 	Employees []DecodeEmployee `json:"employees" validate:"required"`
 }
 
 type DecodeEmployeePUTRequest struct {
 	ID       int      `json:"id"`
-	Name     *string  `json:"name"`
-	Position *string  `json:"position"`
-	Salary   *float64 `json:"salary"`
+	Name     *string  `json:"name" validate:"omitempty,trimspace,employee_name"`
+	Position *string  `json:"position" validate:"omitempty,trimspace,position"`
+	Salary   *float64 `json:"salary" validate:"omitempty,salary"`
+	// SalaryOverrideReason lifts the maximum salary change rule
+	SalaryOverrideReason *string `json:"salary_override_reason" validate:"omitempty,trimspace"`
 }
 
 type DecodeEmployee struct {
-	Name     string  `json:"name" validate:"required"`
-	Position string  `json:"position" validate:"required"`
-	Salary   float64 `json:"salary" validate:"required"`
+	Name     string  `json:"name" validate:"required,trimspace,employee_name"`
+	Position string  `json:"position" validate:"required,trimspace,position"`
+	Salary   float64 `json:"salary" validate:"required,salary"`
+	// EmploymentStatus is optional, employees are created active by default
+	EmploymentStatus string `json:"employment_status" validate:"omitempty,oneof=candidate onboarding active"`
+	HireDate         string `json:"hire_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// DecodeEmploymentTransitionRequest : moves an employee to the next
+// employment status, Action is taken from the route
+type DecodeEmploymentTransitionRequest struct {
+	ID     int    `json:"-"`
+	Action string `json:"-"`
+	Reason string `json:"reason" validate:"omitempty,trimspace,max=500"`
+	// HireDate is required to onboard and rehire
+	HireDate string `json:"hire_date" validate:"omitempty,datetime=2006-01-02"`
+	// LastWorkingDay is required to give notice and terminate
+	LastWorkingDay string `json:"last_working_day" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type DecodeLogLevelPUTRequest struct {
+	// Output is console or file, empty changes both
+	Output string `json:"output" validate:"omitempty,oneof=console file"`
+	Level  string `json:"level" validate:"required,oneof=debug info warn error"`
+}
+
+type DecodeAPIKeyPOSTRequest struct {
+	Name   string   `json:"name" validate:"required,trimspace,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=employee:read employee:write"`
+	// ExpiresAt is optional, keys without it never expire
+	ExpiresAt *time.Time `json:"expires_at"`
+	// TenantID is optional, keys without it belong to the default tenant
+	TenantID string `json:"tenant_id" validate:"omitempty,max=64"`
+}
+
+// APIKeyInfo : API key as listed, without its secret
+type APIKeyInfo struct {
+	ID         int        `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+// APIKeyCreated : a new API key, Key is only ever shown here
+type APIKeyCreated struct {
+	APIKeyInfo
+	Key string `json:"key"`
+}
+
+// JobsInfo : the scheduler and the jobs of its catalog
+type JobsInfo struct {
+	// Holder identifies this instance in leases and job runs
+	Holder string    `json:"holder"`
+	Leader bool      `json:"leader"`
+	Jobs   []JobInfo `json:"jobs"`
+}
+
+// JobInfo : a job of the catalog with its latest runs
+type JobInfo struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Enabled     bool         `json:"enabled"`
+	Schedule    string       `json:"schedule,omitempty"`
+	Timeout     string       `json:"timeout"`
+	NextRun     *time.Time   `json:"next_run"`
+	Runs        []JobRunInfo `json:"runs"`
+}
+
+// JobRunInfo : a run of a job
+type JobRunInfo struct {
+	ID         int        `json:"id"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	Holder     string     `json:"holder"`
+	Status     string     `json:"status"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// AsyncJobInfo : status of an asynchronous job, Result is the JSON report
+// of a finished run
+type AsyncJobInfo struct {
+	ID          string      `json:"id"`
+	Kind        string      `json:"kind"`
+	Status      string      `json:"status"`
+	Progress    int         `json:"progress"`
+	Result      interface{} `json:"result,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	DownloadURL string      `json:"download_url,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	StartedAt   *time.Time  `json:"started_at"`
+	FinishedAt  *time.Time  `json:"finished_at"`
+}
+
+// FileResponse : a file sent as the response body instead of JSON
+type FileResponse struct {
+	ContentType string
+	FileName    string
+	Data        []byte
+}
+
+// EmployeeFilter : selects the employees of a bulk operation, the set
+// criteria are combined
+type EmployeeFilter struct {
+	IDs       []int    `json:"ids" validate:"omitempty,dive,gt=0"`
+	Name      *string  `json:"name" validate:"omitempty,trimspace"`
+	Position  *string  `json:"position" validate:"omitempty,trimspace"`
+	SalaryMin *float64 `json:"salary_min" validate:"omitempty,gte=0"`
+	SalaryMax *float64 `json:"salary_max" validate:"omitempty,gte=0"`
+}
+
+// Empty reports whether the filter would select every employee
+func (f EmployeeFilter) Empty() bool {
+	return len(f.IDs) == 0 && f.Name == nil && f.Position == nil &&
+		f.SalaryMin == nil && f.SalaryMax == nil
+}
+
+// EmployeeChanges : fields changed by a bulk update, the same rules as a
+// PUT apply
+type EmployeeChanges struct {
+	Name     *string  `json:"name" validate:"omitempty,trimspace,employee_name"`
+	Position *string  `json:"position" validate:"omitempty,trimspace,position"`
+	Salary   *float64 `json:"salary" validate:"omitempty,salary"`
+	// SalaryOverrideReason lifts the maximum salary change rule
+	SalaryOverrideReason *string `json:"salary_override_reason" validate:"omitempty,trimspace"`
+}
+
+// Empty reports whether no field is changed
+func (c EmployeeChanges) Empty() bool {
+	return c.Name == nil && c.Position == nil && c.Salary == nil
+}
+
+// ForEmployee returns the changes as the PUT request of an employee
+func (c EmployeeChanges) ForEmployee(id int) DecodeEmployeePUTRequest {
+	return DecodeEmployeePUTRequest{
+		ID:                   id,
+		Name:                 c.Name,
+		Position:             c.Position,
+		Salary:               c.Salary,
+		SalaryOverrideReason: c.SalaryOverrideReason,
+	}
+}
+
+type DecodeEmployeeChange struct {
+	ID      int             `json:"id" validate:"required,gt=0"`
+	Changes EmployeeChanges `json:"changes"`
+}
+
+// DecodeEmployeesPATCHRequest : either a list of changes per ID or a
+// filter with the changes applied to every employee it selects
+type DecodeEmployeesPATCHRequest struct {
+	Updates []DecodeEmployeeChange `json:"updates" validate:"omitempty,unique=ID,dive"`
+	Filter  *EmployeeFilter        `json:"filter"`
+	Changes *EmployeeChanges       `json:"changes"`
+	// DryRun reports the employees that would change without changing them
+	DryRun bool `json:"dry_run"`
+	// Atomic applies the changes to every employee or to none
+	Atomic bool `json:"atomic"`
+}
+
+type DecodeEmployeesDELETERequest struct {
+	Filter EmployeeFilter
+	DryRun bool
+	Atomic bool
+}
+
+// BulkResult : outcome of a bulk operation with the result per employee
+type BulkResult struct {
+	DryRun    bool             `json:"dry_run"`
+	Atomic    bool             `json:"atomic"`
+	Matched   int              `json:"matched"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// BulkItemResult : outcome of a bulk operation for one employee, a
+// failed one carries the status code the single request would answer
+type BulkItemResult struct {
+	ID     int         `json:"id"`
+	Status string      `json:"status"`
+	Code   int         `json:"code,omitempty"`
+	Error  interface{} `json:"error,omitempty"`
 }
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"gorm.io/gorm"
+)
+
+// DBCheck pings the database without touching any table
+func DBCheck(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// LoggerCheck fails while the logger pipeline is not able to take entries
+func LoggerCheck() Check {
+	return func(ctx context.Context) error {
+		return zaplogger.Healthy()
+	}
+}
+
+// MigrationsCheck reports the tables and columns of the models that are
+// missing from the database
+func MigrationsCheck(db *gorm.DB, models ...interface{}) Check {
+	return func(ctx context.Context) error {
+		migrator := db.WithContext(ctx).Migrator()
+		pending := make([]string, 0)
+
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			if !migrator.HasTable(model) {
+				pending = append(pending, "table "+stmt.Schema.Table)
+				continue
+			}
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" || field.IgnoreMigration {
+					continue
+				}
+				if !migrator.HasColumn(model, field.DBName) {
+					pending = append(pending, fmt.Sprintf("column %s.%s", stmt.Schema.Table, field.DBName))
+				}
+			}
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check statuses
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// checkTimeout bounds every single check so a hung dependency can't hang
+// the probe
+const checkTimeout = 2 * time.Second
+
+// Check reports the health of a single dependency
+type Check func(ctx context.Context) error
+
+// CheckResult : outcome of a single check
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report : response body of the probe endpoints
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+/*
+Checker : serves the liveness, readiness and startup probes. The service is
+ready once started, while every readiness check passes and until shutdown
+begins.
+*/
+type Checker struct {
+	mu              sync.RWMutex
+	readinessChecks []namedCheck
+	started         atomic.Bool
+	shuttingDown    atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// AddReadinessCheck adds a check that has to pass for the service to be ready
+func (c *Checker) AddReadinessCheck(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readinessChecks = append(c.readinessChecks, namedCheck{name: name, check: check})
+}
+
+// MarkStarted flags the end of the startup sequence
+func (c *Checker) MarkStarted() {
+	c.started.Store(true)
+}
+
+// MarkShuttingDown makes the readiness probe fail so load balancers drain
+// the instance before the server stops accepting connections
+func (c *Checker) MarkShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// RegisterRoutes registers /healthz, /readyz and /startupz on the router
+func (c *Checker) RegisterRoutes(router gin.IRoutes) {
+	router.GET("/healthz", c.liveness)
+	router.GET("/readyz", c.readiness)
+	router.GET("/startupz", c.startup)
+}
+
+// liveness only reports that the process is able to serve requests
+func (c *Checker) liveness(g *gin.Context) {
+	g.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+func (c *Checker) startup(g *gin.Context) {
+	if !c.started.Load() {
+		g.JSON(http.StatusServiceUnavailable, Report{Status: StatusFail})
+		return
+	}
+	g.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+func (c *Checker) readiness(g *gin.Context) {
+	report := c.Readiness(g.Request.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	g.JSON(status, report)
+}
+
+// Readiness runs every readiness check concurrently and aggregates them
+func (c *Checker) Readiness(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.readinessChecks...)
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks)+2)}
+	report.Checks["startup"] = flagResult(c.started.Load(), "startup not finished")
+	report.Checks["shutdown"] = flagResult(!c.shuttingDown.Load(), "shutting down")
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, nc := range checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			result := run(ctx, nc.check)
+			mu.Lock()
+			report.Checks[nc.name] = result
+			mu.Unlock()
+		}(nc)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func flagResult(ok bool, message string) CheckResult {
+	if ok {
+		return CheckResult{Status: StatusOK}
+	}
+	return CheckResult{Status: StatusFail, Error: message}
+}
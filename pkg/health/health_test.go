@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	// migrated by the test, readiness fails before
+	return testutil.OpenDB(t)
+}
+
+func TestReadiness(t *testing.T) {
+	db := setupTestDB(t)
+	checker := NewChecker()
+	checker.AddReadinessCheck("db", DBCheck(db))
+	checker.AddReadinessCheck("migrations", MigrationsCheck(db, models.All()...))
+
+	ctx := context.Background()
+
+	// not started and not migrated
+	report := checker.Readiness(ctx)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["db"].Status)
+	assert.Equal(t, StatusFail, report.Checks["startup"].Status)
+	assert.Contains(t, report.Checks["migrations"].Error, "table employees")
+
+	assert.NoError(t, db.AutoMigrate(models.All()...))
+	checker.MarkStarted()
+	report = checker.Readiness(ctx)
+	assert.Equal(t, StatusOK, report.Status, report)
+
+	checker.MarkShuttingDown()
+	report = checker.Readiness(ctx)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusFail, report.Checks["shutdown"].Status)
+}
+
+func TestReadinessReportsFailingCheck(t *testing.T) {
+	checker := NewChecker()
+	checker.MarkStarted()
+	checker.AddReadinessCheck("broken", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	report := checker.Readiness(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["broken"].Error)
+}
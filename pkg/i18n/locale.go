@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is used whenever the caller does not ask for a supported locale
+const DefaultLocale = "en"
+
+// SupportedLocales lists every locale the service can answer in. The first
+// entry is the fallback used by the Accept-Language matcher.
+var SupportedLocales = []string{DefaultLocale, "de", "fr", "es", "hi"}
+
+type localeKey struct{}
+
+var matcher = newMatcher()
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, 0, len(SupportedLocales))
+	for _, locale := range SupportedLocales {
+		tags = append(tags, language.MustParse(locale))
+	}
+	return language.NewMatcher(tags)
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header value
+func Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return DefaultLocale
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return SupportedLocales[index]
+}
+
+// WithLocale stores the negotiated locale in the context
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored in the context or the default locale
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return DefaultLocale
+	}
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
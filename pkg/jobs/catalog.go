@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/ratelimit"
+	apikeyrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/apikey"
+	asyncjobrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/asyncjob"
+	employeerepo "github.com/jainabhishek5986/employee-records/pkg/repositories/employee"
+	jobrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/job"
+	"github.com/jainabhishek5986/employee-records/pkg/scheduler"
+	services "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"gorm.io/gorm"
+)
+
+// Names of the jobs in the catalog, they key Jobs.Catalog in the config
+const (
+	PurgeJobRuns          = "purge_job_runs"
+	PurgeRateLimitBuckets = "purge_rate_limit_buckets"
+	PurgeAPIKeys          = "purge_api_keys"
+	PurgeAsyncJobs        = "purge_async_jobs"
+)
+
+// defaultRetention applies to purge jobs configured without a retention,
+// so that a missing key never purges every row
+const defaultRetention = 30 * 24 * time.Hour
+
+/*
+Catalog : returns the background jobs of the service. The Jobs.Catalog
+config schedules them, a job without an enabled entry only runs when
+triggered at /admin/jobs.
+
+Parameters
+----------
+db: Database connection
+cfg: jobs config
+employees: employee service applying the lifecycle transitions
+*/
+func Catalog(db *gorm.DB, cfg config.JobsConfig, employees services.EmployeeService) []scheduler.Job {
+	var (
+		jobRepo      = jobrepo.NewJobRepo(db)
+		apiKeyRepo   = apikeyrepo.NewAPIKeyRepo(db)
+		bucketStore  = ratelimit.NewDBStore(db)
+		asyncJobRepo = asyncjobrepo.NewAsyncJobRepo(db)
+		employeeRepo = employeerepo.NewEmployeeRepo(db)
+	)
+
+	return []scheduler.Job{
+		purge(cfg, PurgeJobRuns, "Deletes the history of finished job runs",
+			jobRepo.PurgeJobRuns),
+		purge(cfg, PurgeRateLimitBuckets, "Deletes rate limit buckets of clients gone quiet",
+			bucketStore.Purge),
+		purge(cfg, PurgeAPIKeys, "Deletes API keys revoked or expired for the retention",
+			apiKeyRepo.PurgeAPIKeys),
+		purge(cfg, PurgeAsyncJobs, "Deletes asynchronous jobs and their downloads finished for the retention",
+			asyncJobRepo.PurgeAsyncJobs),
+		activateHires(employeeRepo.FindEmployeesDue, employees.ChangeEmploymentStatus),
+		endNoticePeriods(employeeRepo.FindEmployeesDue, employees.ChangeEmploymentStatus),
+	}
+}
+
+// purge builds a job deleting the rows older than the retention of its
+// catalog entry
+func purge(cfg config.JobsConfig, name string, description string,
+	purgeBefore func(ctx context.Context, before time.Time) (int64, error)) scheduler.Job {
+
+	return scheduler.Job{
+		Name:        name,
+		Description: description,
+		Run: func(ctx context.Context) (string, error) {
+			retention := cfg.Catalog[name].Retention
+			if retention <= 0 {
+				retention = defaultRetention
+			}
+			// the retention applies to the rows of every tenant
+			purged, err := purgeBefore(tenant.AllTenants(ctx), time.Now().UTC().Add(-retention))
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("purged %d row(s) older than %s", purged, retention), nil
+		},
+	}
+}
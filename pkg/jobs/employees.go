@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/asyncjob"
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	services "github.com/jainabhishek5986/employee-records/pkg/services"
+)
+
+// Kinds of the asynchronous jobs submitted through /api/v1
+const (
+	ImportEmployees = "import_employees"
+	ExportEmployees = "export_employees"
+	UpdateEmployees = "update_employees"
+)
+
+// exportPageSize is the number of employees read per query of an export
+const exportPageSize = 500
+
+// ImportReport : result of an import, rows are created one by one so
+// that an invalid row does not fail the others
+type ImportReport struct {
+	Created int           `json:"created"`
+	Failed  int           `json:"failed"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError : reason a row of an import was not created
+type ImportError struct {
+	Index int         `json:"index"`
+	Name  string      `json:"name"`
+	Error interface{} `json:"error"`
+}
+
+// ExportReport : result of an export, the rows are in the download
+type ExportReport struct {
+	Exported int `json:"exported"`
+}
+
+/*
+ImportEmployeesHandler : returns the handler creating the employees of a
+DecodeEmployeesPOSTRequest payload
+
+Parameters
+----------
+svc: employee service
+*/
+func ImportEmployeesHandler(svc services.EmployeeService) asyncjob.Handler {
+	return func(ctx context.Context, payload []byte, progress asyncjob.Progress) (asyncjob.Output, error) {
+		var request global.DecodeEmployeesPOSTRequest
+		if err := json.Unmarshal(payload, &request); err != nil {
+			return asyncjob.Output{}, fmt.Errorf("%s: %w", errs.StructDecodeError, err)
+		}
+
+		report := ImportReport{}
+		total := len(request.Employees)
+		for index, employee := range request.Employees {
+			row := global.DecodeEmployeesPOSTRequest{Employees: []global.DecodeEmployee{employee}}
+			if err := svc.CreateEmployee(ctx, row); err != nil {
+				report.Failed++
+				report.Errors = append(report.Errors, ImportError{
+					Index: index,
+					Name:  employee.Name,
+					Error: reason(err),
+				})
+			} else {
+				report.Created++
+			}
+			if err := progress((index + 1) * 100 / total); err != nil {
+				return asyncjob.Output{Result: report}, err
+			}
+		}
+
+		return asyncjob.Output{Result: report}, nil
+	}
+}
+
+/*
+ExportEmployeesHandler : returns the handler writing the employees to a
+CSV file, the payload holds the query params of the listing
+
+Parameters
+----------
+svc: employee service
+*/
+func ExportEmployeesHandler(svc services.EmployeeService) asyncjob.Handler {
+	return func(ctx context.Context, payload []byte, progress asyncjob.Progress) (asyncjob.Output, error) {
+		queryParams := make(map[string][]string)
+		if err := json.Unmarshal(payload, &queryParams); err != nil {
+			return asyncjob.Output{}, fmt.Errorf("%s: %w", errs.StructDecodeError, err)
+		}
+		queryParams["per_page"] = []string{strconv.Itoa(exportPageSize)}
+
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		_ = writer.Write([]string{"id", "name", "position", "salary", "employment_status", "hire_date",
+			"termination_date", "created_at", "updated_at"})
+
+		report := ExportReport{}
+		for page, lastPage := 1, 1; page <= lastPage; page++ {
+			queryParams["page"] = []string{strconv.Itoa(page)}
+			response, err := svc.GetAllEmployee(ctx, queryParams)
+			if err != nil {
+				return asyncjob.Output{}, err
+			}
+			employees, _ := response.Data.([]models.Employee)
+			for _, employee := range employees {
+				_ = writer.Write([]string{
+					strconv.Itoa(employee.ID),
+					employee.Name,
+					employee.Position,
+					strconv.FormatFloat(employee.Salary, 'f', -1, 64),
+					employee.EmploymentStatus,
+					formatDate(employee.HireDate),
+					formatDate(employee.TerminationDate),
+					formatTime(employee.CreatedAt),
+					formatTime(employee.UpdatedAt),
+				})
+			}
+			report.Exported += len(employees)
+
+			if pagination, ok := response.Pagination.(map[string]int); ok {
+				lastPage = pagination["last_page"]
+			}
+			if lastPage > 0 {
+				if err := progress(page * 100 / lastPage); err != nil {
+					return asyncjob.Output{}, err
+				}
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return asyncjob.Output{}, err
+		}
+		return asyncjob.Output{
+			Result:      report,
+			Data:        buf.Bytes(),
+			ContentType: "text/csv",
+			FileName:    "employees.csv",
+		}, nil
+	}
+}
+
+/*
+UpdateEmployeesHandler : returns the handler applying a
+DecodeEmployeesPATCHRequest payload, the report is the result of the
+bulk update
+
+Parameters
+----------
+svc: employee service
+*/
+func UpdateEmployeesHandler(svc services.EmployeeService) asyncjob.Handler {
+	return func(ctx context.Context, payload []byte, progress asyncjob.Progress) (asyncjob.Output, error) {
+		var request global.DecodeEmployeesPATCHRequest
+		if err := json.Unmarshal(payload, &request); err != nil {
+			return asyncjob.Output{}, fmt.Errorf("%s: %w", errs.StructDecodeError, err)
+		}
+
+		// the updates share one transaction, there is no progress to
+		// report before it ends
+		result, err := svc.BulkUpdateEmployees(ctx, request)
+		if err != nil {
+			// an atomic update rolled back still reports the failed rows
+			if result.Results != nil {
+				return asyncjob.Output{Result: result}, err
+			}
+			return asyncjob.Output{}, err
+		}
+		return asyncjob.Output{Result: result}, nil
+	}
+}
+
+// reason is the message of an error for the report of a job, the
+// validation messages of an HTTP error are kept as they are
+func reason(err error) interface{} {
+	var httpErr *errs.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Message
+	}
+	return err.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatDate writes the dates of the employment lifecycle as they are
+// submitted
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(employment.DateLayout)
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/scheduler"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+)
+
+// Names of the jobs moving employees along the employment lifecycle
+const (
+	ActivateHires    = "activate_hires"
+	EndNoticePeriods = "end_notice_periods"
+)
+
+// dueBatchSize is the number of employees read per query of a lifecycle job
+const dueBatchSize = 500
+
+// noticeEndedReason is the reason of the terminations of end_notice_periods
+const noticeEndedReason = "Notice period ended"
+
+// dueEmployees finds the employees in one of the statuses whose date
+// column, hire_date or termination_date, is before the time
+type dueEmployees func(ctx context.Context, statuses []string, dateColumn string, before time.Time,
+	afterID int, limit int) ([]models.Employee, error)
+
+// transitionEmployee applies a transition request, it is the
+// ChangeEmploymentStatus of the employee service
+type transitionEmployee func(ctx context.Context,
+	request global.DecodeEmploymentTransitionRequest) (global.SuccessGETInfo, error)
+
+// activateHires builds the job activating the onboarding and rehired
+// employees whose hire date has come
+func activateHires(find dueEmployees, change transitionEmployee) scheduler.Job {
+	return lifecycle(ActivateHires, "Activates onboarding and rehired employees on their hire date",
+		"activated", find, change,
+		[]string{employment.StatusOnboarding, employment.StatusRehired}, "hire_date",
+		// the hire date is the first working day
+		func(today time.Time) time.Time { return today.AddDate(0, 0, 1) },
+		func(employee models.Employee) global.DecodeEmploymentTransitionRequest {
+			return global.DecodeEmploymentTransitionRequest{ID: employee.ID, Action: employment.ActionActivate}
+		},
+	)
+}
+
+// endNoticePeriods builds the job terminating the employees whose last
+// working day has passed
+func endNoticePeriods(find dueEmployees, change transitionEmployee) scheduler.Job {
+	return lifecycle(EndNoticePeriods, "Terminates employees in their notice period after the last working day",
+		"terminated", find, change,
+		[]string{employment.StatusNoticePeriod}, "termination_date",
+		// the last working day is still worked
+		func(today time.Time) time.Time { return today },
+		func(employee models.Employee) global.DecodeEmploymentTransitionRequest {
+			return global.DecodeEmploymentTransitionRequest{
+				ID:             employee.ID,
+				Action:         employment.ActionTerminate,
+				Reason:         noticeEndedReason,
+				LastWorkingDay: employee.TerminationDate.UTC().Format(employment.DateLayout),
+			}
+		},
+	)
+}
+
+/*
+lifecycle : builds a job applying a transition to every employee in one of
+the statuses whose date column is before the cutoff. Each transition goes
+through the employee service in the tenant of the employee, so it records
+its event like a request would, with the job as the actor.
+
+Parameters
+----------
+name: name of the job
+description: description of the job
+verb: past tense of the transition in the result
+find: lookup of the due employees
+change: transition of one employee
+statuses: statuses the transition moves employees from
+dateColumn: date column compared to the cutoff
+cutoff: returns the cutoff from the start of the current day in UTC
+request: returns the transition request of an employee
+*/
+func lifecycle(name string, description string, verb string, find dueEmployees, change transitionEmployee,
+	statuses []string, dateColumn string, cutoff func(today time.Time) time.Time,
+	request func(employee models.Employee) global.DecodeEmploymentTransitionRequest) scheduler.Job {
+
+	return scheduler.Job{
+		Name:        name,
+		Description: description,
+		Run: func(ctx context.Context) (string, error) {
+			before := cutoff(time.Now().UTC().Truncate(24 * time.Hour))
+			actor := reqctx.WithUserID(ctx, "job:"+name)
+
+			var changed, skipped, failed int
+			var lastErr error
+			// batches follow the id, so the employees left in the statuses by
+			// a failure are not read again
+			afterID := 0
+			for {
+				batch, err := find(tenant.AllTenants(ctx), statuses, dateColumn, before, afterID, dueBatchSize)
+				if err != nil {
+					return "", err
+				}
+				for _, employee := range batch {
+					afterID = employee.ID
+					_, err := change(tenant.WithTenant(actor, employee.TenantID), request(employee))
+					switch {
+					case err == nil:
+						changed++
+					case isConflict(err):
+						// moved by a request since it was read
+						skipped++
+					default:
+						failed++
+						lastErr = err
+					}
+				}
+				if len(batch) < dueBatchSize {
+					break
+				}
+				if err := ctx.Err(); err != nil {
+					return "", err
+				}
+			}
+
+			result := fmt.Sprintf("%s %d employee(s), skipped %d", verb, changed, skipped)
+			if failed > 0 {
+				return "", fmt.Errorf("%s, failed %d: %w", result, failed, lastErr)
+			}
+			return result, nil
+		},
+	}
+}
+
+// isConflict reports whether the service refused the transition because
+// of the current status of the employee
+func isConflict(err error) bool {
+	var httpErr *errs.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode() == http.StatusConflict
+}
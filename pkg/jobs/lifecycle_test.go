@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndNoticePeriods(t *testing.T) {
+	lastWorkingDay := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	due := []models.Employee{
+		{ID: 1, TenantID: "acme", EmploymentStatus: employment.StatusNoticePeriod, TerminationDate: &lastWorkingDay},
+		{ID: 2, TenantID: "globex", EmploymentStatus: employment.StatusNoticePeriod, TerminationDate: &lastWorkingDay},
+		{ID: 3, TenantID: "acme", EmploymentStatus: employment.StatusNoticePeriod, TerminationDate: &lastWorkingDay},
+	}
+	find := func(ctx context.Context, statuses []string, dateColumn string, before time.Time,
+		afterID int, limit int) ([]models.Employee, error) {
+
+		assert.True(t, tenant.IsAllTenants(ctx))
+		assert.Equal(t, []string{employment.StatusNoticePeriod}, statuses)
+		assert.Equal(t, "termination_date", dateColumn)
+		assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour), before)
+		found := make([]models.Employee, 0)
+		for _, employee := range due {
+			if employee.ID > afterID && len(found) < limit {
+				found = append(found, employee)
+			}
+		}
+		return found, nil
+	}
+
+	testCases := []struct {
+		name           string
+		errors         map[int]error
+		expectedResult string
+		expectedError  string
+	}{
+		{
+			name:           "Every employee terminated",
+			expectedResult: "terminated 3 employee(s), skipped 0",
+		},
+		{
+			name:           "Employee moved by a request is skipped",
+			errors:         map[int]error{2: errs.ConflictErr(errs.EmploymentConflictError)},
+			expectedResult: "terminated 2 employee(s), skipped 1",
+		},
+		{
+			name:          "Failure fails the run after the others",
+			errors:        map[int]error{1: errors.New("database is locked")},
+			expectedError: "terminated 2 employee(s), skipped 0, failed 1: database is locked",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []global.DecodeEmploymentTransitionRequest
+			change := func(ctx context.Context,
+				request global.DecodeEmploymentTransitionRequest) (global.SuccessGETInfo, error) {
+
+				id, _ := tenant.FromContext(ctx)
+				assert.Equal(t, due[request.ID-1].TenantID, id)
+				assert.Equal(t, "job:"+EndNoticePeriods, reqctx.UserID(ctx))
+				requests = append(requests, request)
+				return global.SuccessGETInfo{}, tc.errors[request.ID]
+			}
+
+			result, err := endNoticePeriods(find, change).Run(context.Background())
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result)
+			}
+			if assert.Len(t, requests, len(due)) {
+				assert.Equal(t, global.DecodeEmploymentTransitionRequest{
+					ID:             1,
+					Action:         employment.ActionTerminate,
+					Reason:         noticeEndedReason,
+					LastWorkingDay: "2024-06-30",
+				}, requests[0])
+			}
+		})
+	}
+}
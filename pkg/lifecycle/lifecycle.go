@@ -0,0 +1,177 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// Exit codes of the process
+const (
+	// ExitOK is a clean shutdown after a signal
+	ExitOK = 0
+	// ExitFailure is a component that failed while running or stopping
+	ExitFailure = 1
+	// ExitStartup is an invalid config or a component that failed to start,
+	// e.g. because its port is taken
+	ExitStartup = 2
+	// ExitShutdownTimeout is a shutdown that exceeded the deadline or was
+	// forced by a second signal
+	ExitShutdownTimeout = 3
+)
+
+// Signals start the graceful shutdown, a second one forces it
+var Signals = []os.Signal{syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT}
+
+// Hook : a component of the process. Start returns once the component is
+// running, long running work continues in goroutines until ctx is done.
+// Stop returns once the component stopped or its ctx expired.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager : starts hooks in order and stops the started ones in reverse
+// order within a single shutdown deadline
+type Manager struct {
+	hooks    []Hook
+	deadline time.Duration
+	failures chan error
+	signals  chan os.Signal
+}
+
+/*
+New : returns a manager stopping all hooks within the deadline
+
+Parameters
+----------
+deadline: time all stop hooks share once the shutdown starts
+*/
+func New(deadline time.Duration) *Manager {
+	return &Manager{
+		deadline: deadline,
+		failures: make(chan error, 1),
+		signals:  make(chan os.Signal, 2),
+	}
+}
+
+// Append adds a hook, it starts after and stops before the hooks added so
+// far
+func (m *Manager) Append(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Fail reports a component that stopped working, which shuts the process
+// down with ExitFailure. Only the first failure is kept.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failures <- err:
+	default:
+	}
+}
+
+/*
+Run : starts the hooks and blocks until a signal, a failure or the end of
+ctx, then stops the started hooks. It returns the exit code of the process.
+
+Parameters
+----------
+ctx: parent context of the hooks
+*/
+func (m *Manager) Run(ctx context.Context) int {
+	signal.Notify(m.signals, Signals...)
+	defer signal.Stop(m.signals)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	started, err := m.start(runCtx)
+	if err != nil {
+		cancel()
+		m.stop(started)
+		return ExitStartup
+	}
+
+	code := ExitOK
+	select {
+	case sig := <-m.signals:
+		zaplogger.Info(ctx, "Received signal, shutting down", zap.String("signal", sig.String()))
+	case err := <-m.failures:
+		zaplogger.Error(ctx, errs.ComponentFailedError, zap.Error(err))
+		code = ExitFailure
+	case <-ctx.Done():
+		zaplogger.Info(ctx, "Context done, shutting down")
+	}
+
+	// background work of the hooks ends with the run context
+	cancel()
+	if stopCode := m.stop(started); stopCode != ExitOK {
+		return stopCode
+	}
+	return code
+}
+
+// start runs the start hooks in order and returns the started ones
+func (m *Manager) start(ctx context.Context) ([]Hook, error) {
+	started := make([]Hook, 0, len(m.hooks))
+	for _, hook := range m.hooks {
+		if hook.Start != nil {
+			if err := hook.Start(ctx); err != nil {
+				zaplogger.Error(ctx, errs.ComponentStartError, zap.String("component", hook.Name), zap.Error(err))
+				return started, fmt.Errorf("starting %s: %w", hook.Name, err)
+			}
+		}
+		zaplogger.Debug(ctx, "Component started", zap.String("component", hook.Name))
+		started = append(started, hook)
+	}
+	return started, nil
+}
+
+// stop runs the stop hooks in reverse order, they share the deadline and a
+// second signal cancels it
+func (m *Manager) stop(started []Hook) int {
+	ctx, cancel := context.WithTimeout(context.Background(), m.deadline)
+	defer cancel()
+
+	go func() {
+		select {
+		case sig := <-m.signals:
+			zaplogger.Warn(ctx, "Received second signal, forcing shutdown", zap.String("signal", sig.String()))
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	code := ExitOK
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		if hook.Stop == nil {
+			continue
+		}
+		err := hook.Stop(ctx)
+		switch {
+		case err == nil:
+			zaplogger.Debug(context.Background(), "Component stopped", zap.String("component", hook.Name))
+		case ctx.Err() != nil:
+			// later hooks still get to release what they can
+			zaplogger.Error(context.Background(), errs.ShutdownDeadlineError,
+				zap.String("component", hook.Name), zap.Duration("deadline", m.deadline))
+			code = ExitShutdownTimeout
+		default:
+			zaplogger.Error(context.Background(), errs.ComponentStopError,
+				zap.String("component", hook.Name), zap.Error(err))
+			if code == ExitOK {
+				code = ExitFailure
+			}
+		}
+	}
+	return code
+}
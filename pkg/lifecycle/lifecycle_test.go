@@ -0,0 +1,94 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerRun(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failStart     string
+		failRunning   bool
+		slowStop      string
+		expectedCode  int
+		expectedCalls []string
+	}{
+		{
+			name:         "Hooks stop in reverse order",
+			expectedCode: ExitOK,
+			expectedCalls: []string{
+				"start db", "start api", "start diagnostics",
+				"stop diagnostics", "stop api", "stop db",
+			},
+		},
+		{
+			name:          "Start failure stops the started hooks",
+			failStart:     "api",
+			expectedCode:  ExitStartup,
+			expectedCalls: []string{"start db", "start api", "stop db"},
+		},
+		{
+			name:         "Failure while running",
+			failRunning:  true,
+			expectedCode: ExitFailure,
+			expectedCalls: []string{
+				"start db", "start api", "start diagnostics",
+				"stop diagnostics", "stop api", "stop db",
+			},
+		},
+		{
+			name:         "Shutdown deadline exceeded",
+			slowStop:     "api",
+			expectedCode: ExitShutdownTimeout,
+			expectedCalls: []string{
+				"start db", "start api", "start diagnostics",
+				"stop diagnostics", "stop api", "stop db",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := make([]string, 0)
+			manager := New(50 * time.Millisecond)
+			for _, name := range []string{"db", "api", "diagnostics"} {
+				name := name
+				manager.Append(Hook{
+					Name: name,
+					Start: func(ctx context.Context) error {
+						calls = append(calls, "start "+name)
+						if name == tc.failStart {
+							return errors.New("address already in use")
+						}
+						return nil
+					},
+					Stop: func(ctx context.Context) error {
+						calls = append(calls, "stop "+name)
+						if name == tc.slowStop {
+							<-ctx.Done()
+							return ctx.Err()
+						}
+						return nil
+					},
+				})
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.failRunning {
+				manager.Fail(errors.New("serve failed"))
+			} else {
+				cancel()
+			}
+			code := manager.Run(ctx)
+			cancel()
+
+			assert.Equal(t, tc.expectedCode, code)
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package metrics
+
+// Results of a cache lookup
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// ObserveCacheLookup counts a lookup of the named cache by its result
+func ObserveCacheLookup(cache string, hit bool) {
+	result := CacheMiss
+	if hit {
+		result = CacheHit
+	}
+	cacheLookups.WithLabelValues(cache, result).Inc()
+}
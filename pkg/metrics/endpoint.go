@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// InstrumentEndpoint returns a go-kit middleware recording call count,
+// outcome and latency of the named endpoint
+func InstrumentEndpoint(name string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(start time.Time) {
+				endpointDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+				endpointRequests.WithLabelValues(name, strconv.FormatBool(err == nil)).Inc()
+			}(time.Now())
+
+			return next(ctx, request)
+		}
+	}
+}
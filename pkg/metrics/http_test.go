@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGinMiddlewareLabelsRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(GinMiddleware())
+	router.GET("/api/v1/employee/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/employee/1", "/api/v1/employee/2", "/does-not-exist"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(
+		httpRequests.WithLabelValues(http.MethodGet, "/api/v1/employee/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(
+		httpInFlight.WithLabelValues(http.MethodGet, "/api/v1/employee/:id")))
+}
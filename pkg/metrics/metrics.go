@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"runtime"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace prefixes every metric exported by the service
+const Namespace = "employee_records"
+
+// Registry holds every collector of the service and is served on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by route template, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served by route template and method.",
+	}, []string{"method", "route"})
+
+	endpointRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "endpoint",
+		Name:      "requests_total",
+		Help:      "Number of go-kit endpoint calls by endpoint and outcome.",
+	}, []string{"endpoint", "success"})
+
+	endpointDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "endpoint",
+		Name:      "duration_seconds",
+		Help:      "Latency of go-kit endpoint calls by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of GORM queries by operation and table.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "table"})
+
+	dbQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Number of failed GORM queries by operation and table.",
+	}, []string{"operation", "table"})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Number of cache lookups by cache and result, hit or miss.",
+	}, []string{"cache", "result"})
+
+	loggerQueueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "logger",
+		Name:      "queue_depth",
+		Help:      "Number of log entries waiting in the zaplogger channel.",
+	}, func() float64 {
+		return float64(zaplogger.QueueDepth())
+	})
+
+	loggerDropped = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "logger",
+		Name:      "dropped_total",
+		Help:      "Number of log entries discarded by the zaplogger overflow policy.",
+	}, func() float64 {
+		return float64(zaplogger.Dropped())
+	})
+
+	buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "build_info",
+		Help:      "Build information of the running binary, always 1.",
+	}, []string{"version", "goversion"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		endpointRequests,
+		endpointDuration,
+		dbQueryDuration,
+		dbQueryErrors,
+		cacheLookups,
+		loggerQueueDepth,
+		loggerDropped,
+		buildInfo,
+	)
+
+	buildInfo.WithLabelValues(global.BinaryVersion, runtime.Version()).Set(1)
+}
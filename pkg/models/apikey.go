@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey - credentials of a service-to-service caller. Only the SHA-256
+// hash of the secret is stored, Prefix identifies the key in lookups and
+// listings. Callers of the key act for its tenant.
+type APIKey struct {
+	ID         int        `json:"id"`
+	TenantID   string     `json:"tenant_id" gorm:"size:64;index"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;size:32"`
+	Hash       string     `json:"-" gorm:"size:64"`
+	Scopes     string     `json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+func (m *APIKey) GetTableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the scopes granted to the key
+func (m *APIKey) ScopeList() []string {
+	if m.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(m.Scopes, ",")
+}
+
+// Active reports whether the key may be used at now
+func (m *APIKey) Active(now time.Time) bool {
+	if m.RevokedAt != nil {
+		return false
+	}
+	return m.ExpiresAt == nil || now.Before(*m.ExpiresAt)
+}
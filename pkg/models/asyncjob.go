@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Asynchronous job statuses, queued and running jobs can be cancelled
+const (
+	AsyncJobStatusQueued    = "queued"
+	AsyncJobStatusRunning   = "running"
+	AsyncJobStatusSucceeded = "succeeded"
+	AsyncJobStatusFailed    = "failed"
+	AsyncJobStatusCancelled = "cancelled"
+)
+
+// AsyncJob - a long running operation submitted through the API. Payload
+// holds the submitted request, Result the JSON report of the run and
+// Output the file offered for download, e.g. an export.
+type AsyncJob struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:32"`
+	TenantID   string     `json:"-" gorm:"size:64;index"`
+	Kind       string     `json:"kind" gorm:"size:64"`
+	Owner      string     `json:"-" gorm:"index;size:255"`
+	Status     string     `json:"status" gorm:"index;size:16"`
+	Progress   int        `json:"progress"`
+	Payload    []byte     `json:"-"`
+	Result     string     `json:"-"`
+	Error      string     `json:"error,omitempty"`
+	Output     []byte     `json:"-"`
+	OutputType string     `json:"-" gorm:"size:255"`
+	OutputName string     `json:"-" gorm:"size:255"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	// HeartbeatAt is refreshed by the worker of a running job, it stops
+	// once the worker died
+	HeartbeatAt *time.Time `json:"-" gorm:"index"`
+}
+
+func (m *AsyncJob) GetTableName() string {
+	return "async_jobs"
+}
+
+// Finished reports whether the job reached a final status
+func (m *AsyncJob) Finished() bool {
+	switch m.Status {
+	case AsyncJobStatusSucceeded, AsyncJobStatusFailed, AsyncJobStatusCancelled:
+		return true
+	}
+	return false
+}
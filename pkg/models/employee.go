@@ -4,15 +4,40 @@ import "time"
 
 // Attachments - It stores all the attachements.
 type Employee struct {
-	ID        int        `json:"id"`
-	Name      string     `json:"name"`
-	Position  string     `json:"position"`
-	Salary    float64    `json:"salary"`
-	CreatedAt *time.Time `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
+	ID       int     `json:"id"`
+	TenantID string  `json:"-" gorm:"size:64;index"`
+	Name     string  `json:"name"`
+	Position string  `json:"position"`
+	Salary   float64 `json:"salary"`
+	// EmploymentStatus moves through the transitions of pkg/employment
+	EmploymentStatus string     `json:"employment_status" gorm:"size:32;index;default:active"`
+	HireDate         *time.Time `json:"hire_date"`
+	// TerminationDate is the last working day of a notice or termination
+	TerminationDate *time.Time `json:"termination_date"`
+	CreatedAt       *time.Time `json:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at"`
 }
 
 func (m *Employee) GetTableName() string {
 	return "employees"
 }
+
+// All returns every model migrated on startup, a new model belongs to
+// either TenantModels or GlobalModels
+func All() []interface{} {
+	return append(TenantModels(), GlobalModels()...)
+}
+
+// TenantModels returns the models whose rows belong to a tenant, their
+// TenantID field scopes them through the tenant callbacks
+func TenantModels() []interface{} {
+	return []interface{}{&Employee{}, &APIKey{}, &AsyncJob{}, &EmploymentEvent{}}
+}
+
+// GlobalModels returns the models of the whole instance, they have no
+// TenantID and the tenant callbacks leave them alone. Rate limit buckets
+// are keyed by caller, job runs and leases belong to the scheduler.
+func GlobalModels() []interface{} {
+	return []interface{}{&RateLimitBucket{}, &JobRun{}, &JobLease{}}
+}
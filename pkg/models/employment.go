@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// EmploymentEvent - audit event of a transition of the employment status
+// of an employee, written with the transition. EffectiveDate is the hire
+// date or the last working day set by the transition, if any.
+type EmploymentEvent struct {
+	ID            int        `json:"id"`
+	TenantID      string     `json:"-" gorm:"size:64;index"`
+	EmployeeID    int        `json:"employee_id" gorm:"index"`
+	Action        string     `json:"action" gorm:"size:32"`
+	FromStatus    string     `json:"from_status" gorm:"size:32"`
+	ToStatus      string     `json:"to_status" gorm:"size:32"`
+	Reason        string     `json:"reason"`
+	EffectiveDate *time.Time `json:"effective_date"`
+	Actor         string     `json:"actor" gorm:"size:255"`
+	RequestID     string     `json:"request_id" gorm:"size:64"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"index"`
+}
+
+func (m *EmploymentEvent) GetTableName() string {
+	return "employment_events"
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Job run triggers and statuses
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// JobRun - history of the background job runs, Holder is the instance
+// that ran the job. Jobs run for every tenant, so runs have no tenant.
+type JobRun struct {
+	ID         int        `json:"id"`
+	Job        string     `json:"job" gorm:"index;size:64"`
+	Trigger    string     `json:"trigger" gorm:"size:16"`
+	Holder     string     `json:"holder" gorm:"size:255"`
+	Status     string     `json:"status" gorm:"size:16"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at" gorm:"index"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func (m *JobRun) GetTableName() string {
+	return "job_runs"
+}
+
+// JobLease - a named lease held by one instance until ExpiresAt, used to
+// elect the scheduler leader and to keep runs of a job from overlapping.
+// Leases are held for the whole instance, they have no tenant.
+type JobLease struct {
+	Name      string    `json:"name" gorm:"primaryKey;size:128"`
+	Holder    string    `json:"holder" gorm:"size:255"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (m *JobLease) GetTableName() string {
+	return "job_leases"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelsDeclareTheirTenancy(t *testing.T) {
+	hasTenantID := func(model interface{}) bool {
+		_, ok := reflect.TypeOf(model).Elem().FieldByName("TenantID")
+		return ok
+	}
+
+	for _, model := range TenantModels() {
+		assert.True(t, hasTenantID(model), "tenant model %T has no TenantID", model)
+	}
+	for _, model := range GlobalModels() {
+		assert.False(t, hasTenantID(model), "global model %T has a TenantID", model)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RateLimitBucket - token bucket state shared by the service instances.
+// The tenant of the caller prefixes BucketKey, see rateLimitClient, so
+// buckets have no TenantID and are purged for every tenant at once.
+type RateLimitBucket struct {
+	BucketKey  string    `json:"bucket_key" gorm:"primaryKey;size:255"`
+	Tokens     float64   `json:"tokens"`
+	RefilledAt time.Time `json:"refilled_at"`
+}
+
+func (m *RateLimitBucket) GetTableName() string {
+	return "rate_limit_buckets"
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DBStore : keeps the buckets in the database so that every instance
+// applies the same quota to a client
+type DBStore struct {
+	db *gorm.DB
+}
+
+// NewDBStore returns a store using the rate_limit_buckets table
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// Take implements Store, the bucket row is locked for the read-modify-write
+func (s *DBStore) Take(ctx context.Context, key string, quota config.RateLimitQuota,
+	now time.Time) (result Result, err error) {
+
+	var b models.RateLimitBucket
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Table(b.GetTableName()).
+			Where("bucket_key = ?", key).
+			Take(&b).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			b = models.RateLimitBucket{BucketKey: key, Tokens: float64(quota.Limit), RefilledAt: now}
+		} else if err != nil {
+			return err
+		}
+
+		b.Tokens, result = take(b.Tokens, b.RefilledAt, quota, now)
+		b.RefilledAt = now
+
+		return tx.Table(b.GetTableName()).
+			Clauses(clause.OnConflict{UpdateAll: true}).
+			Create(&b).Error
+	})
+
+	return result, err
+}
+
+// Purge deletes the buckets not refilled since the given time, they would
+// be full again on their next use
+func (s *DBStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var b models.RateLimitBucket
+	res := s.db.WithContext(ctx).Table(b.GetTableName()).
+		Where("refilled_at < ?", before).
+		Delete(&b)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"gorm.io/gorm"
+)
+
+// Stores of the bucket state
+const (
+	StoreMemory = "memory"
+	StoreDB     = "db"
+)
+
+// defaultScope names the bucket shared by the routes without an own quota
+const defaultScope = "default"
+
+// Result of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Limit is the capacity of the bucket
+	Limit int
+	// Remaining is the number of whole tokens left
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token, zero when allowed
+	RetryAfter time.Duration
+}
+
+/*
+Store : keeps the state of the token buckets. Take refills the bucket of
+key for the time elapsed since its last use and takes one token from it.
+*/
+type Store interface {
+	Take(ctx context.Context, key string, quota config.RateLimitQuota, now time.Time) (Result, error)
+}
+
+/*
+NewStore : returns the bucket store named in the config
+
+Parameters
+----------
+name: memory or db
+db: Database connection, used by the db store
+*/
+func NewStore(name string, db *gorm.DB) (Store, error) {
+	switch strings.ToLower(name) {
+	case "", StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreDB:
+		return NewDBStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", name)
+	}
+}
+
+// quotas is the config of the limiter with lower cased route keys
+type quotas struct {
+	enabled      bool
+	defaultQuota config.RateLimitQuota
+	routes       map[string]config.RateLimitQuota
+}
+
+// Limiter : applies the configured quotas to the buckets of a store
+type Limiter struct {
+	store  Store
+	quotas atomic.Pointer[quotas]
+	now    func() time.Time
+}
+
+// NewLimiter returns a limiter applying cfg with buckets kept in store
+func NewLimiter(store Store, cfg config.RateLimitConfig) *Limiter {
+	limiter := &Limiter{store: store, now: time.Now}
+	limiter.SetConfig(cfg)
+	return limiter
+}
+
+// SetConfig atomically replaces the quotas, the bucket state is kept
+func (l *Limiter) SetConfig(cfg config.RateLimitConfig) {
+	routes := make(map[string]config.RateLimitQuota, len(cfg.Routes))
+	for route, quota := range cfg.Routes {
+		// viper lower cases map keys
+		routes[strings.ToLower(route)] = quota
+	}
+	l.quotas.Store(&quotas{
+		enabled:      cfg.Enabled,
+		defaultQuota: cfg.Default,
+		routes:       routes,
+	})
+}
+
+// Enabled reports whether requests are limited at all
+func (l *Limiter) Enabled() bool {
+	return l.quotas.Load().enabled
+}
+
+/*
+Allow : takes a token from the bucket of the client for the route. Routes
+with an own quota have their own bucket, the others share the default one,
+which a tenant of ctx may override.
+
+Parameters
+----------
+ctx: request context
+client: identity of the caller, e.g. user:<id> or ip:<address>
+method: HTTP method
+route: route template of the request
+*/
+func (l *Limiter) Allow(ctx context.Context, client string, method string, route string) (Result, error) {
+	current := l.quotas.Load()
+	scope := strings.ToLower(method + " " + route)
+	quota, ok := current.routes[scope]
+	if !ok {
+		scope, quota = defaultScope, current.defaultQuota
+		if overrides, ok := tenant.Overrides(ctx); ok && overrides.RateLimit != nil {
+			quota = *overrides.RateLimit
+		}
+	}
+	if quota.Limit <= 0 || quota.Period <= 0 {
+		// an empty quota does not limit
+		return Result{Allowed: true, Limit: quota.Limit}, nil
+	}
+
+	return l.store.Take(ctx, scope+"|"+client, quota, l.now())
+}
+
+// take applies the token bucket algorithm to a bucket last refilled at
+// updated with tokens left. Missing buckets are full.
+func take(tokens float64, updated time.Time, quota config.RateLimitQuota,
+	now time.Time) (float64, Result) {
+
+	capacity := float64(quota.Limit)
+	// tokens per second
+	rate := capacity / quota.Period.Seconds()
+
+	if elapsed := now.Sub(updated).Seconds(); elapsed > 0 {
+		tokens = math.Min(capacity, tokens+elapsed*rate)
+	}
+
+	result := Result{Limit: quota.Limit}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((capacity - tokens) / rate)
+
+	return tokens, result
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	return testutil.OpenDB(t, &models.RateLimitBucket{})
+}
+
+func TestLimiter(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitQuota{Limit: 3, Period: time.Minute},
+		Routes: map[string]config.RateLimitQuota{
+			"POST /api/v1/employee": {Limit: 1, Period: time.Minute},
+		},
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type request struct {
+		client  string
+		method  string
+		route   string
+		elapsed time.Duration
+	}
+	testCases := []struct {
+		name               string
+		requests           []request
+		expectedAllowed    bool
+		expectedRemaining  int
+		expectedRetryAfter time.Duration
+	}{
+		{
+			name:              "Full bucket allows",
+			requests:          []request{{"ip:1", "GET", "/api/v1/employee", 0}},
+			expectedAllowed:   true,
+			expectedRemaining: 2,
+		},
+		{
+			name: "Empty bucket rejects until the next token",
+			requests: []request{
+				{"ip:1", "GET", "/api/v1/employee", 0},
+				{"ip:1", "GET", "/api/v1/employee/:id", 0},
+				{"ip:1", "GET", "/api/v1/employee", 0},
+				{"ip:1", "GET", "/api/v1/employee", 0},
+			},
+			expectedAllowed:    false,
+			expectedRetryAfter: 20 * time.Second,
+		},
+		{
+			name: "Bucket refills over time",
+			requests: []request{
+				{"ip:1", "GET", "/api/v1/employee", 0},
+				{"ip:1", "GET", "/api/v1/employee", 0},
+				{"ip:1", "GET", "/api/v1/employee", 0},
+				{"ip:1", "GET", "/api/v1/employee", 20 * time.Second},
+			},
+			expectedAllowed:   true,
+			expectedRemaining: 0,
+		},
+		{
+			name: "Route quota has its own bucket",
+			requests: []request{
+				{"ip:1", "GET", "/api/v1/employee", 0},
+				{"ip:1", "POST", "/api/v1/employee", 0},
+				{"ip:1", "POST", "/api/v1/employee", 0},
+			},
+			expectedAllowed:    false,
+			expectedRetryAfter: time.Minute,
+		},
+		{
+			name: "Clients have separate buckets",
+			requests: []request{
+				{"ip:1", "POST", "/api/v1/employee", 0},
+				{"user:2", "POST", "/api/v1/employee", 0},
+			},
+			expectedAllowed:   true,
+			expectedRemaining: 0,
+		},
+	}
+
+	stores := map[string]func(t *testing.T) Store{
+		StoreMemory: func(t *testing.T) Store { return NewMemoryStore() },
+		StoreDB:     func(t *testing.T) Store { return NewDBStore(setupTestDB(t)) },
+	}
+
+	for storeName, newStore := range stores {
+		for _, tc := range testCases {
+			t.Run(storeName+" "+tc.name, func(t *testing.T) {
+				limiter := NewLimiter(newStore(t), cfg)
+				now := start
+
+				var result Result
+				var err error
+				for _, req := range tc.requests {
+					now = now.Add(req.elapsed)
+					limiter.now = func() time.Time { return now }
+					result, err = limiter.Allow(context.Background(), req.client, req.method, req.route)
+					assert.NoError(t, err)
+				}
+
+				assert.Equal(t, tc.expectedAllowed, result.Allowed)
+				assert.Equal(t, tc.expectedRemaining, result.Remaining)
+				assert.InDelta(t, tc.expectedRetryAfter, result.RetryAfter, float64(time.Millisecond))
+			})
+		}
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	quota := config.RateLimitQuota{Limit: 2, Period: time.Second}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := store.Take(context.Background(), "a", quota, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+
+	// a is full again by the time b is taken after the sweep interval
+	_, err = store.Take(context.Background(), "b", quota, now.Add(sweepInterval))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+}
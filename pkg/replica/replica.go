@@ -0,0 +1,300 @@
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type readsKey struct{}
+
+type callerKey struct{}
+
+type consistentKey struct{}
+
+// caller is the client a request acts for, see WithCaller
+type caller struct {
+	key            string
+	readYourWrites bool
+}
+
+// reads keeps the replica chosen for the statements of a Reads context
+type reads struct {
+	mu      sync.Mutex
+	replica *member
+}
+
+// Reads marks the statements of the context as reads that tolerate the
+// replication lag, e.g. the employee lookups, so that they may be served
+// by a replica. The statements of the context stay on the replica chosen
+// first while it is healthy, so that e.g. a count and a page agree.
+func Reads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readsKey{}, &reads{})
+}
+
+// WithCaller stores the client of a request. Its writes are remembered for
+// Config.ReadYourWritesWindow, and when readYourWrites is set its reads go
+// to the primary within that window.
+func WithCaller(ctx context.Context, key string, readYourWrites bool) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller{key: key, readYourWrites: readYourWrites})
+}
+
+// Consistent marks the reads of the context as needing the writes of their
+// tenant, they go to the primary for Config.ReadYourWritesWindow after a
+// write of the tenant on this instance. Cache fills use it, so that an
+// entry invalidated by a write is not refilled from a lagging replica.
+func Consistent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentKey{}, true)
+}
+
+// member is a replica and the result of its last health check
+type member struct {
+	name    string
+	pool    *sql.DB
+	healthy atomic.Bool
+}
+
+/*
+Router : sends the reads marked with Reads to a healthy replica, every
+other statement, statements in transactions and reads pinned to recent
+writes go to the primary. Replicas are pinged every
+Config.ReplicaCheckInterval, reads fail over to the primary while no
+replica answers.
+*/
+type Router struct {
+	primary  gorm.ConnPool
+	replicas []*member
+	next     atomic.Uint64
+	interval time.Duration
+	window   time.Duration
+	now      func() time.Time
+
+	mu sync.Mutex
+	// writes holds the last write of callers and tenants
+	writes map[string]time.Time
+}
+
+/*
+Register : opens the replicas and installs callbacks routing the reads of
+db to them and recording the writes of callers. The replicas are checked
+once before it returns. Without replicas every statement stays on the
+primary and no callback is installed.
+
+Parameters
+----------
+ctx: context of the start up
+db: connection to the primary database
+cfg: database config
+replicas: dialectors of the replicas, in the order of Config.Replicas
+*/
+func Register(ctx context.Context, db *gorm.DB, cfg config.DBConfig, replicas ...gorm.Dialector) (*Router, error) {
+	router := &Router{
+		primary:  db.ConnPool,
+		interval: cfg.ReplicaCheckInterval,
+		window:   cfg.ReadYourWritesWindow,
+		now:      time.Now,
+		writes:   make(map[string]time.Time),
+	}
+	for index, dialector := range replicas {
+		// replicas are only read through the pool, the callbacks of db
+		// run on the primary session
+		replica, err := gorm.Open(dialector, &gorm.Config{})
+		if err != nil {
+			_ = router.Close()
+			return nil, fmt.Errorf("%s %d: %w", errs.ReplicaOpenError, index+1, err)
+		}
+		pool, err := replica.DB()
+		if err != nil {
+			_ = router.Close()
+			return nil, fmt.Errorf("%s %d: %w", errs.ReplicaOpenError, index+1, err)
+		}
+		router.replicas = append(router.replicas, &member{name: fmt.Sprintf("replica-%d", index+1), pool: pool})
+	}
+	// writes are only forgotten by the health checks of the replicas
+	if len(router.replicas) == 0 {
+		return router, nil
+	}
+	router.check(ctx)
+
+	callback := db.Callback()
+	err := errors.Join(
+		callback.Query().Before("gorm:query").Register("replica:query", router.route),
+		callback.Row().Before("gorm:row").Register("replica:row", router.route),
+		callback.Create().After("gorm:create").Register("replica:create", router.record),
+		callback.Update().After("gorm:update").Register("replica:update", router.record),
+		callback.Delete().After("gorm:delete").Register("replica:delete", router.record),
+	)
+	if err != nil {
+		_ = router.Close()
+		return nil, err
+	}
+	return router, nil
+}
+
+/*
+Start : checks the replicas every interval until ctx ends, the loop is
+tracked by waitgroup.Gwg
+
+Parameters
+----------
+ctx: run context of the process
+*/
+func (r *Router) Start(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	waitgroup.Add(1)
+	go func() {
+		defer waitgroup.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.check(ctx)
+				r.forget()
+			}
+		}
+	}()
+	zaplogger.Info(ctx, "Replica health checks started", zap.Int("replicas", len(r.replicas)))
+}
+
+// Close closes the replica pools
+func (r *Router) Close() error {
+	var err error
+	for _, replica := range r.replicas {
+		err = errors.Join(err, replica.pool.Close())
+	}
+	return err
+}
+
+// Healthy returns the health of the replicas by name
+func (r *Router) Healthy() map[string]bool {
+	health := make(map[string]bool, len(r.replicas))
+	for _, replica := range r.replicas {
+		health[replica.name] = replica.healthy.Load()
+	}
+	return health
+}
+
+// check pings every replica and logs the changes of its health
+func (r *Router) check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, r.interval)
+		err := replica.pool.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			zaplogger.Info(ctx, "Replica is answering, it serves reads", zap.String("replica", replica.name))
+		} else {
+			zaplogger.Warn(ctx, errs.ReplicaUnhealthyError, zap.String("replica", replica.name), zap.Error(err))
+		}
+	}
+}
+
+// route sends a read to the replica of its context or the next healthy
+// replica, round robin
+func (r *Router) route(db *gorm.DB) {
+	stmt := db.Statement
+	session, ok := stmt.Context.Value(readsKey{}).(*reads)
+	// transactions and prepared sessions keep their connection
+	if !ok || stmt.ConnPool != r.primary || r.pinned(stmt.Context) {
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.replica == nil || !session.replica.healthy.Load() {
+		session.replica = r.pick()
+	}
+	if session.replica != nil {
+		stmt.ConnPool = session.replica.pool
+	}
+}
+
+// pick returns the next healthy replica, nil while none is healthy
+func (r *Router) pick() *member {
+	start := r.next.Add(1)
+	for offset := range r.replicas {
+		replica := r.replicas[(int(start)+offset)%len(r.replicas)]
+		if replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// record remembers the time of a successful write of the caller and the
+// tenant
+func (r *Router) record(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if db.Error != nil || r.window <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if c, ok := ctx.Value(callerKey{}).(caller); ok {
+		r.writes["caller:"+c.key] = now
+	}
+	if tenantID := reqctx.TenantID(ctx); tenantID != "" {
+		r.writes["tenant:"+tenantID] = now
+	}
+}
+
+// pinned reports whether the caller asked to read its writes and wrote
+// within the window, or the read is Consistent and its tenant wrote within
+// the window
+func (r *Router) pinned(ctx context.Context) bool {
+	keys := make([]string, 0, 2)
+	if c, ok := ctx.Value(callerKey{}).(caller); ok && c.readYourWrites {
+		keys = append(keys, "caller:"+c.key)
+	}
+	if consistent, _ := ctx.Value(consistentKey{}).(bool); consistent {
+		keys = append(keys, "tenant:"+reqctx.TenantID(ctx))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	for _, key := range keys {
+		if written, ok := r.writes[key]; ok && now.Sub(written) < r.window {
+			return true
+		}
+	}
+	return false
+}
+
+// forget drops the writes older than the window, so that memory stays
+// bounded by the recently writing callers and tenants
+func (r *Router) forget() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	for key, written := range r.writes {
+		if now.Sub(written) >= r.window {
+			delete(r.writes, key)
+		}
+	}
+}
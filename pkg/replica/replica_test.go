@@ -0,0 +1,137 @@
+package replica
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// note tells the databases apart, each file holds a note naming it
+type note struct {
+	ID   int
+	Text string
+}
+
+// openFile creates a SQLite file holding a note with its name
+func openFile(t *testing.T, name string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name+".db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm db, %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatalf("failed to migrate schema, %v", err)
+	}
+	db.Create(&note{ID: 1, Text: name})
+	return db
+}
+
+func setupRouter(t *testing.T) (*gorm.DB, *Router) {
+	primary := openFile(t, "primary")
+	replicas := []gorm.Dialector{
+		openFile(t, "replica-1").Dialector,
+		openFile(t, "replica-2").Dialector,
+	}
+
+	router, err := Register(context.Background(), primary, config.DBConfig{
+		ReplicaCheckInterval: time.Second,
+		ReadYourWritesWindow: time.Minute,
+	}, replicas...)
+	if err != nil {
+		t.Fatalf("failed to register router, %v", err)
+	}
+	t.Cleanup(func() { _ = router.Close() })
+	return primary, router
+}
+
+// servedBy returns the name of the database answering the read
+func servedBy(t *testing.T, db *gorm.DB) string {
+	var n note
+	assert.NoError(t, db.First(&n, 1).Error)
+	return n.Text
+}
+
+func TestRouter(t *testing.T) {
+	t.Run("Reads go to the replicas", func(t *testing.T) {
+		db, _ := setupRouter(t)
+		ctx := context.Background()
+
+		assert.Equal(t, "primary", servedBy(t, db.WithContext(ctx)))
+		first := servedBy(t, db.WithContext(Reads(ctx)))
+		second := servedBy(t, db.WithContext(Reads(ctx)))
+		assert.ElementsMatch(t, []string{"replica-1", "replica-2"}, []string{first, second})
+
+		// the statements of a context stay on its replica
+		reads := db.WithContext(Reads(ctx))
+		assert.Equal(t, servedBy(t, reads), servedBy(t, reads))
+
+		// transactions keep their connection
+		_ = db.WithContext(Reads(ctx)).Transaction(func(tx *gorm.DB) error {
+			assert.Equal(t, "primary", servedBy(t, tx))
+			return nil
+		})
+	})
+
+	t.Run("Reads fail over to the primary", func(t *testing.T) {
+		db, router := setupRouter(t)
+		ctx := context.Background()
+
+		_ = router.replicas[0].pool.Close()
+		router.check(ctx)
+		assert.Equal(t, map[string]bool{"replica-1": false, "replica-2": true}, router.Healthy())
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, "replica-2", servedBy(t, db.WithContext(Reads(ctx))))
+		}
+
+		_ = router.replicas[1].pool.Close()
+		router.check(ctx)
+		assert.Equal(t, "primary", servedBy(t, db.WithContext(Reads(ctx))))
+	})
+
+	t.Run("Reads are pinned to recent writes", func(t *testing.T) {
+		db, router := setupRouter(t)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		router.now = func() time.Time { return now }
+		ctx := reqctx.WithTenantID(context.Background(), "acme")
+		writer := WithCaller(ctx, "user:1", true)
+
+		assert.NotEqual(t, "primary", servedBy(t, db.WithContext(Reads(writer))))
+		assert.NoError(t, db.WithContext(writer).Create(&note{ID: 2, Text: "written"}).Error)
+
+		assert.Equal(t, "primary", servedBy(t, db.WithContext(Reads(writer))))
+		// other callers do not ask for the writes
+		assert.NotEqual(t, "primary", servedBy(t, db.WithContext(Reads(WithCaller(ctx, "user:1", false)))))
+		assert.NotEqual(t, "primary", servedBy(t, db.WithContext(Reads(WithCaller(ctx, "user:2", true)))))
+		// consistent reads of the tenant see the writes
+		assert.Equal(t, "primary", servedBy(t, db.WithContext(Reads(Consistent(ctx)))))
+		other := reqctx.WithTenantID(context.Background(), "globex")
+		assert.NotEqual(t, "primary", servedBy(t, db.WithContext(Reads(Consistent(other)))))
+
+		now = now.Add(time.Minute)
+		assert.NotEqual(t, "primary", servedBy(t, db.WithContext(Reads(writer))))
+		router.forget()
+		assert.Empty(t, router.writes)
+	})
+
+	t.Run("Writes are not recorded without replicas", func(t *testing.T) {
+		db := openFile(t, "primary")
+		router, err := Register(context.Background(), db, config.DBConfig{
+			ReplicaCheckInterval: time.Second,
+			ReadYourWritesWindow: time.Minute,
+		})
+		assert.NoError(t, err)
+		ctx := WithCaller(reqctx.WithTenantID(context.Background(), "acme"), "user:1", true)
+
+		assert.NoError(t, db.WithContext(ctx).Create(&note{ID: 2, Text: "written"}).Error)
+		assert.Empty(t, router.writes)
+		assert.Equal(t, "primary", servedBy(t, db.WithContext(Reads(ctx))))
+	})
+}
@@ -0,0 +1,126 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) repositories.APIKeyRepository {
+	return &Repository{db: db}
+}
+
+// CreateAPIKey
+func (repo *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	err := repo.db.WithContext(ctx).Table(key.GetTableName()).Create(key).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyNewRecordError, zap.Error(err))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
+
+// ListAPIKeys
+func (repo *Repository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var key models.APIKey
+	keys := make([]models.APIKey, 0)
+
+	err := repo.db.WithContext(ctx).Table(key.GetTableName()).Order("id").Find(&keys).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyFetchRecordsError, zap.Error(err))
+		return nil, errs.InternalErr()
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeyByPrefix
+func (repo *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (models.APIKey, error) {
+	var key models.APIKey
+
+	err := repo.db.WithContext(ctx).Table(key.GetTableName()).Where("prefix = ?", prefix).Take(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return key, errs.RequestNotProcessed(errs.APIKeyNoRecordFoundError)
+	}
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyFetchRecordsError, zap.Error(err))
+		return key, errs.InternalErr()
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKey
+func (repo *Repository) RevokeAPIKey(ctx context.Context, id int, at time.Time) error {
+	var key models.APIKey
+
+	res := repo.db.WithContext(ctx).Table(key.GetTableName()).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.APIKeyUpdateError, zap.Error(res.Error), zap.Int("api_key_id", id))
+		return errs.InternalErr()
+	}
+	if res.RowsAffected == 0 {
+		zaplogger.Error(ctx, errs.APIKeyNoRecordFoundError, zap.Int("api_key_id", id))
+		return errs.RequestNotProcessed(errs.APIKeyNoRecordFoundError)
+	}
+
+	return nil
+}
+
+// TouchAPIKey records the last use of a key
+func (repo *Repository) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+	var key models.APIKey
+
+	err := repo.db.WithContext(ctx).Table(key.GetTableName()).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyUpdateError, zap.Error(err), zap.Int("api_key_id", id))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
+
+// PurgeAPIKeys
+func (repo *Repository) PurgeAPIKeys(ctx context.Context, before time.Time) (int64, error) {
+	var key models.APIKey
+
+	res := repo.db.WithContext(ctx).Table(key.GetTableName()).
+		Where("revoked_at < ? OR expires_at < ?", before.UTC(), before.UTC()).
+		Delete(&key)
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.JobPurgeError, zap.Error(res.Error), zap.String("table", key.GetTableName()))
+		return 0, errs.InternalErr()
+	}
+
+	return res.RowsAffected, nil
+}
+
+// CountAPIKeys
+func (repo *Repository) CountAPIKeys(ctx context.Context) (int64, error) {
+	var key models.APIKey
+	var count int64
+
+	err := repo.db.WithContext(ctx).Table(key.GetTableName()).Count(&count).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyFetchRecordsError, zap.Error(err))
+		return 0, errs.InternalErr()
+	}
+
+	return count, nil
+}
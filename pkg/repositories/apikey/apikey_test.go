@@ -0,0 +1,106 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db := testutil.OpenDB(t, &models.APIKey{})
+	if err := tenant.RegisterGORM(db, &models.APIKey{}); err != nil {
+		t.Fatalf("failed to scope tenants, %v", err)
+	}
+
+	testutil.InitLogger(t)
+	return db
+}
+
+func TestRepositoryRespectsTenant(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAPIKeyRepo(db)
+
+	acme := tenant.WithTenant(context.Background(), "acme")
+	globex := tenant.WithTenant(context.Background(), "globex")
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	// the key of acme was revoked a while ago so that a purge would take it
+	acmeKey := models.APIKey{Name: "billing", Prefix: "erk_acme", Scopes: "employee:read", RevokedAt: &past}
+	assert.NoError(t, repo.CreateAPIKey(acme, &acmeKey))
+	assert.Equal(t, "acme", acmeKey.TenantID)
+	globexKey := models.APIKey{Name: "billing", Prefix: "erk_globex", Scopes: "employee:read"}
+	assert.NoError(t, repo.CreateAPIKey(globex, &globexKey))
+
+	// every method runs for globex against the key of acme
+	testCases := []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{
+			name: "ListAPIKeys",
+			check: func(t *testing.T) {
+				keys, err := repo.ListAPIKeys(globex)
+				assert.NoError(t, err)
+				if assert.Len(t, keys, 1) {
+					assert.Equal(t, globexKey.ID, keys[0].ID)
+				}
+			},
+		},
+		{
+			name: "GetAPIKeyByPrefix",
+			check: func(t *testing.T) {
+				_, err := repo.GetAPIKeyByPrefix(globex, acmeKey.Prefix)
+				assert.Equal(t, errs.RequestNotProcessed(errs.APIKeyNoRecordFoundError), err)
+			},
+		},
+		{
+			name: "RevokeAPIKey",
+			check: func(t *testing.T) {
+				err := repo.RevokeAPIKey(globex, acmeKey.ID, now)
+				assert.Equal(t, errs.RequestNotProcessed(errs.APIKeyNoRecordFoundError), err)
+			},
+		},
+		{
+			name: "TouchAPIKey",
+			check: func(t *testing.T) {
+				assert.NoError(t, repo.TouchAPIKey(globex, acmeKey.ID, now))
+			},
+		},
+		{
+			name: "PurgeAPIKeys",
+			check: func(t *testing.T) {
+				purged, err := repo.PurgeAPIKeys(globex, now)
+				assert.NoError(t, err)
+				assert.Zero(t, purged)
+			},
+		},
+		{
+			name: "Every tenant",
+			check: func(t *testing.T) {
+				keys, err := repo.ListAPIKeys(tenant.AllTenants(context.Background()))
+				assert.NoError(t, err)
+				assert.Len(t, keys, 2)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.check(t)
+		})
+	}
+
+	// the key of acme is untouched
+	key, err := repo.GetAPIKeyByPrefix(acme, acmeKey.Prefix)
+	assert.NoError(t, err)
+	assert.Nil(t, key.LastUsedAt)
+	assert.WithinDuration(t, past, *key.RevokedAt, time.Second)
+}
@@ -0,0 +1,273 @@
+package asyncjob
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// claimAttempts bounds the retries of a claim lost to another worker
+const claimAttempts = 5
+
+// statusColumns are read for status requests, the payload and output may
+// be large
+var statusColumns = []string{
+	"id", "tenant_id", "kind", "owner", "status", "progress", "result", "error",
+	"output_type", "output_name", "created_at", "started_at", "finished_at",
+}
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewAsyncJobRepo(db *gorm.DB) repositories.AsyncJobRepository {
+	return &Repository{db: db}
+}
+
+// CreateAsyncJob
+func (repo *Repository) CreateAsyncJob(ctx context.Context, job *models.AsyncJob) error {
+	job.CreatedAt = job.CreatedAt.UTC()
+	err := repo.db.WithContext(ctx).Table(job.GetTableName()).Create(job).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.AsyncJobNewRecordError, zap.Error(err), zap.String("kind", job.Kind))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
+
+// CountQueuedAsyncJobs
+func (repo *Repository) CountQueuedAsyncJobs(ctx context.Context) (int64, error) {
+	var job models.AsyncJob
+	var count int64
+
+	err := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Where("status = ?", models.AsyncJobStatusQueued).
+		Count(&count).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.AsyncJobFetchRecordsError, zap.Error(err))
+		return 0, errs.InternalErr()
+	}
+
+	return count, nil
+}
+
+// GetAsyncJob
+func (repo *Repository) GetAsyncJob(ctx context.Context, id string) (models.AsyncJob, error) {
+	return repo.getAsyncJob(ctx, id, statusColumns)
+}
+
+// GetAsyncJobOutput
+func (repo *Repository) GetAsyncJobOutput(ctx context.Context, id string) (models.AsyncJob, error) {
+	return repo.getAsyncJob(ctx, id, append([]string{"output"}, statusColumns...))
+}
+
+func (repo *Repository) getAsyncJob(ctx context.Context, id string, columns []string) (models.AsyncJob, error) {
+	var job models.AsyncJob
+
+	err := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Select(columns).
+		Where("id = ?", id).
+		Take(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return job, errs.NotFoundErr(errs.AsyncJobNoRecordFoundError)
+	}
+	if err != nil {
+		zaplogger.Error(ctx, errs.AsyncJobFetchRecordsError, zap.Error(err), zap.String("async_job_id", id))
+		return job, errs.InternalErr()
+	}
+
+	return job, nil
+}
+
+// ClaimNextAsyncJob takes the oldest queued job, a job claimed by another
+// worker in between is skipped
+func (repo *Repository) ClaimNextAsyncJob(ctx context.Context, now time.Time) (models.AsyncJob, bool, error) {
+	now = now.UTC()
+
+	for attempt := 0; attempt < claimAttempts; attempt++ {
+		var job models.AsyncJob
+		// an empty queue is the common case of a poll, Find does not log
+		// it as an error like Take
+		res := repo.db.WithContext(ctx).Table(job.GetTableName()).
+			Where("status = ?", models.AsyncJobStatusQueued).
+			Order("created_at, id").
+			Limit(1).
+			Find(&job)
+		if res.Error != nil {
+			zaplogger.Error(ctx, errs.AsyncJobFetchRecordsError, zap.Error(res.Error))
+			return job, false, errs.InternalErr()
+		}
+		if res.RowsAffected == 0 {
+			return job, false, nil
+		}
+
+		res = repo.db.WithContext(ctx).Table(job.GetTableName()).
+			Where("id = ? AND status = ?", job.ID, models.AsyncJobStatusQueued).
+			Updates(map[string]interface{}{
+				"status":       models.AsyncJobStatusRunning,
+				"started_at":   now,
+				"heartbeat_at": now,
+			})
+		if res.Error != nil {
+			zaplogger.Error(ctx, errs.AsyncJobUpdateError, zap.Error(res.Error), zap.String("async_job_id", job.ID))
+			return job, false, errs.InternalErr()
+		}
+		if res.RowsAffected == 1 {
+			job.Status = models.AsyncJobStatusRunning
+			job.StartedAt = &now
+			job.HeartbeatAt = &now
+			return job, true, nil
+		}
+	}
+
+	return models.AsyncJob{}, false, nil
+}
+
+// UpdateAsyncJobProgress
+func (repo *Repository) UpdateAsyncJobProgress(ctx context.Context, id string, progress int, now time.Time) (bool, error) {
+	var job models.AsyncJob
+
+	res := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Where("id = ? AND status = ?", id, models.AsyncJobStatusRunning).
+		Updates(map[string]interface{}{
+			"progress":     progress,
+			"heartbeat_at": now.UTC(),
+		})
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.AsyncJobUpdateError, zap.Error(res.Error), zap.String("async_job_id", id))
+		return false, errs.InternalErr()
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// FinishAsyncJob stores the outcome of a running job
+func (repo *Repository) FinishAsyncJob(ctx context.Context, job *models.AsyncJob) (bool, error) {
+	if job.FinishedAt != nil {
+		finished := job.FinishedAt.UTC()
+		job.FinishedAt = &finished
+	}
+
+	res := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Where("id = ? AND status = ?", job.ID, models.AsyncJobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"progress":    job.Progress,
+			"result":      job.Result,
+			"error":       job.Error,
+			"output":      job.Output,
+			"output_type": job.OutputType,
+			"output_name": job.OutputName,
+			"finished_at": job.FinishedAt,
+		})
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.AsyncJobUpdateError, zap.Error(res.Error), zap.String("async_job_id", job.ID))
+		return false, errs.InternalErr()
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// CancelAsyncJob cancels a queued or running job
+func (repo *Repository) CancelAsyncJob(ctx context.Context, id string, now time.Time) (bool, error) {
+	var job models.AsyncJob
+
+	res := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Where("id = ? AND status IN ?", id, []string{models.AsyncJobStatusQueued, models.AsyncJobStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.AsyncJobStatusCancelled,
+			"finished_at": now.UTC(),
+		})
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.AsyncJobUpdateError, zap.Error(res.Error), zap.String("async_job_id", id))
+		return false, errs.InternalErr()
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// RequeueAsyncJob puts a job interrupted by a shutdown back in the queue
+func (repo *Repository) RequeueAsyncJob(ctx context.Context, id string) error {
+	var job models.AsyncJob
+
+	err := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Where("id = ? AND status = ?", id, models.AsyncJobStatusRunning).
+		Updates(map[string]interface{}{
+			"status":       models.AsyncJobStatusQueued,
+			"progress":     0,
+			"started_at":   nil,
+			"heartbeat_at": nil,
+		}).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.AsyncJobUpdateError, zap.Error(err), zap.String("async_job_id", id))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
+
+// ExpireAsyncJobs releases the jobs left running by a worker that died,
+// jobs running before heartbeats were stored count from their start
+func (repo *Repository) ExpireAsyncJobs(ctx context.Context, before time.Time, now time.Time,
+	rerun []string) (int64, error) {
+
+	var job models.AsyncJob
+	var expired int64
+
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		lost := func() *gorm.DB {
+			return tx.Table(job.GetTableName()).
+				Where("status = ? AND COALESCE(heartbeat_at, started_at) < ?", models.AsyncJobStatusRunning, before.UTC())
+		}
+
+		res := lost().Where("kind IN ?", rerun).
+			Updates(map[string]interface{}{
+				"status":       models.AsyncJobStatusQueued,
+				"progress":     0,
+				"started_at":   nil,
+				"heartbeat_at": nil,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		expired += res.RowsAffected
+
+		res = lost().
+			Updates(map[string]interface{}{
+				"status":      models.AsyncJobStatusFailed,
+				"error":       errs.AsyncJobLostError,
+				"finished_at": now.UTC(),
+			})
+		expired += res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		zaplogger.Error(ctx, errs.AsyncJobUpdateError, zap.Error(err))
+		return 0, errs.InternalErr()
+	}
+
+	return expired, nil
+}
+
+// PurgeAsyncJobs deletes the jobs finished before the given time
+func (repo *Repository) PurgeAsyncJobs(ctx context.Context, before time.Time) (int64, error) {
+	var job models.AsyncJob
+
+	res := repo.db.WithContext(ctx).Table(job.GetTableName()).
+		Where("finished_at < ?", before.UTC()).
+		Delete(&job)
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.JobPurgeError, zap.Error(res.Error), zap.String("table", job.GetTableName()))
+		return 0, errs.InternalErr()
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,160 @@
+package asyncjob
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db := testutil.OpenDB(t, &models.AsyncJob{})
+	if err := tenant.RegisterGORM(db, &models.AsyncJob{}); err != nil {
+		t.Fatalf("failed to scope tenants, %v", err)
+	}
+
+	testutil.InitLogger(t)
+	return db
+}
+
+func TestRepositoryRespectsTenant(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAsyncJobRepo(db)
+
+	acme := tenant.WithTenant(context.Background(), "acme")
+	globex := tenant.WithTenant(context.Background(), "globex")
+	now := time.Now()
+
+	queued := models.AsyncJob{ID: "queued", Kind: "export", Status: models.AsyncJobStatusQueued, CreatedAt: now}
+	assert.NoError(t, repo.CreateAsyncJob(acme, &queued))
+	assert.Equal(t, "acme", queued.TenantID)
+	finishedAt := now.Add(-time.Hour)
+	running := models.AsyncJob{ID: "running", Kind: "export", Status: models.AsyncJobStatusRunning, CreatedAt: now,
+		StartedAt: &finishedAt}
+	assert.NoError(t, repo.CreateAsyncJob(acme, &running))
+	finished := models.AsyncJob{ID: "finished", Kind: "export", Status: models.AsyncJobStatusSucceeded,
+		CreatedAt: now, FinishedAt: &finishedAt}
+	assert.NoError(t, repo.CreateAsyncJob(acme, &finished))
+
+	assertNotFound := func(t *testing.T, err error) {
+		var httpErr *errs.HTTPError
+		if assert.ErrorAs(t, err, &httpErr) {
+			assert.Equal(t, http.StatusNotFound, httpErr.StatusCode())
+		}
+	}
+
+	// every method runs for globex against the jobs of acme
+	testCases := []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{
+			name: "CountQueuedAsyncJobs",
+			check: func(t *testing.T) {
+				count, err := repo.CountQueuedAsyncJobs(globex)
+				assert.NoError(t, err)
+				assert.Zero(t, count)
+			},
+		},
+		{
+			name: "GetAsyncJob",
+			check: func(t *testing.T) {
+				_, err := repo.GetAsyncJob(globex, queued.ID)
+				assertNotFound(t, err)
+			},
+		},
+		{
+			name: "GetAsyncJobOutput",
+			check: func(t *testing.T) {
+				_, err := repo.GetAsyncJobOutput(globex, finished.ID)
+				assertNotFound(t, err)
+			},
+		},
+		{
+			name: "ClaimNextAsyncJob",
+			check: func(t *testing.T) {
+				_, claimed, err := repo.ClaimNextAsyncJob(globex, now)
+				assert.NoError(t, err)
+				assert.False(t, claimed)
+			},
+		},
+		{
+			name: "UpdateAsyncJobProgress",
+			check: func(t *testing.T) {
+				updated, err := repo.UpdateAsyncJobProgress(globex, running.ID, 50, now)
+				assert.NoError(t, err)
+				assert.False(t, updated)
+			},
+		},
+		{
+			name: "FinishAsyncJob",
+			check: func(t *testing.T) {
+				job := models.AsyncJob{ID: running.ID, Status: models.AsyncJobStatusFailed, FinishedAt: &now}
+				recorded, err := repo.FinishAsyncJob(globex, &job)
+				assert.NoError(t, err)
+				assert.False(t, recorded)
+			},
+		},
+		{
+			name: "CancelAsyncJob",
+			check: func(t *testing.T) {
+				cancelled, err := repo.CancelAsyncJob(globex, queued.ID, now)
+				assert.NoError(t, err)
+				assert.False(t, cancelled)
+			},
+		},
+		{
+			name: "RequeueAsyncJob",
+			check: func(t *testing.T) {
+				assert.NoError(t, repo.RequeueAsyncJob(globex, running.ID))
+			},
+		},
+		{
+			name: "ExpireAsyncJobs",
+			check: func(t *testing.T) {
+				expired, err := repo.ExpireAsyncJobs(globex, now, now, []string{"export"})
+				assert.NoError(t, err)
+				assert.Zero(t, expired)
+			},
+		},
+		{
+			name: "PurgeAsyncJobs",
+			check: func(t *testing.T) {
+				purged, err := repo.PurgeAsyncJobs(globex, now)
+				assert.NoError(t, err)
+				assert.Zero(t, purged)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.check(t)
+		})
+	}
+
+	// the jobs of acme are untouched
+	for id, status := range map[string]string{
+		queued.ID:   models.AsyncJobStatusQueued,
+		running.ID:  models.AsyncJobStatusRunning,
+		finished.ID: models.AsyncJobStatusSucceeded,
+	} {
+		job, err := repo.GetAsyncJob(acme, id)
+		assert.NoError(t, err)
+		assert.Equal(t, status, job.Status)
+		assert.Zero(t, job.Progress)
+	}
+
+	// workers claim the jobs of every tenant and run them for their tenant
+	job, claimed, err := repo.ClaimNextAsyncJob(tenant.AllTenants(context.Background()), now)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "acme", job.TenantID)
+}
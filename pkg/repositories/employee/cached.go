@@ -0,0 +1,141 @@
+package employee
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/cache"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/replica"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+)
+
+// CacheName names the employee cache in keys and metrics
+const CacheName = "employee"
+
+// cachedRepository serves the employee reads from a read-through cache,
+// the entries of a tenant are invalidated by every write of the process
+type cachedRepository struct {
+	next  repositories.EmployeeRepository
+	cache *cache.Cache
+}
+
+/*
+NewCachedEmployeeRepo : wraps the repository in a read-through cache of
+GetEmployeeByID and GetAllEmployee. Entries are scoped to the tenant of the
+context, reads acting for all tenants are not cached. A nil cache returns
+the repository unchanged.
+
+Parameters
+----------
+next: repository reading the database
+c: employee cache, see cache.Named
+*/
+func NewCachedEmployeeRepo(next repositories.EmployeeRepository, c *cache.Cache) repositories.EmployeeRepository {
+	if c == nil {
+		return next
+	}
+	return &cachedRepository{next: next, cache: c}
+}
+
+func (repo *cachedRepository) CreateEmployee(ctx context.Context, req global.DecodeEmployeesPOSTRequest) error {
+	defer repo.invalidate(ctx)
+	return repo.next.CreateEmployee(ctx, req)
+}
+
+func (repo *cachedRepository) GetEmployeeByID(ctx context.Context, id int) (global.SuccessGETInfo, error) {
+	return repo.get(ctx, "id:"+strconv.Itoa(id), func(ctx context.Context) (global.SuccessGETInfo, error) {
+		return repo.next.GetEmployeeByID(ctx, id)
+	})
+}
+
+func (repo *cachedRepository) UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest,
+	from models.Employee) error {
+
+	defer repo.invalidate(ctx)
+	return repo.next.UpdateEmployeeByID(ctx, request, from)
+}
+
+func (repo *cachedRepository) DeleteEmployeeByID(ctx context.Context, id int) error {
+	defer repo.invalidate(ctx)
+	return repo.next.DeleteEmployeeByID(ctx, id)
+}
+
+func (repo *cachedRepository) GetAllEmployee(ctx context.Context, queryParams map[string][]string) (global.SuccessGETInfo, error) {
+	// Encode sorts the parameters, equal queries share an entry
+	key := "list:" + url.Values(queryParams).Encode()
+	return repo.get(ctx, key, func(ctx context.Context) (global.SuccessGETInfo, error) {
+		return repo.next.GetAllEmployee(ctx, queryParams)
+	})
+}
+
+// FindEmployees selects the employees of bulk writes, which need the
+// stored rows
+func (repo *cachedRepository) FindEmployees(ctx context.Context, filter global.EmployeeFilter,
+	limit int) ([]models.Employee, error) {
+
+	return repo.next.FindEmployees(ctx, filter, limit)
+}
+
+// FindEmployeesDue selects the employees of the employment jobs, which
+// change the stored rows
+func (repo *cachedRepository) FindEmployeesDue(ctx context.Context, statuses []string, dateColumn string,
+	before time.Time, afterID int, limit int) ([]models.Employee, error) {
+
+	return repo.next.FindEmployeesDue(ctx, statuses, dateColumn, before, afterID, limit)
+}
+
+func (repo *cachedRepository) UpdateEmployees(ctx context.Context, requests []global.DecodeEmployeePUTRequest,
+	from map[int]models.Employee, atomic bool) ([]global.BulkItemResult, error) {
+
+	defer repo.invalidate(ctx)
+	return repo.next.UpdateEmployees(ctx, requests, from, atomic)
+}
+
+func (repo *cachedRepository) DeleteEmployees(ctx context.Context, ids []int, atomic bool) ([]global.BulkItemResult, error) {
+	defer repo.invalidate(ctx)
+	return repo.next.DeleteEmployees(ctx, ids, atomic)
+}
+
+func (repo *cachedRepository) ChangeEmploymentStatus(ctx context.Context, employee models.Employee, from string,
+	event models.EmploymentEvent) (bool, error) {
+
+	defer repo.invalidate(ctx)
+	return repo.next.ChangeEmploymentStatus(ctx, employee, from, event)
+}
+
+// get reads key of the tenant of ctx through the cache
+func (repo *cachedRepository) get(ctx context.Context, key string,
+	load func(ctx context.Context) (global.SuccessGETInfo, error)) (global.SuccessGETInfo, error) {
+
+	scope, ok := tenant.FromContext(ctx)
+	if !ok || tenant.IsAllTenants(ctx) {
+		return load(ctx)
+	}
+
+	value, err := repo.cache.Get(ctx, scope, key, func(ctx context.Context) (interface{}, error) {
+		// an entry invalidated by a write is not refilled from a replica
+		// lagging behind it
+		return load(replica.Consistent(ctx))
+	})
+	if err != nil {
+		return global.SuccessGETInfo{}, err
+	}
+	return value.(global.SuccessGETInfo), nil
+}
+
+// invalidate drops the entries of the tenant of ctx once a write returned,
+// failed writes included since they may have committed before failing.
+// Writes acting for all tenants drop every entry.
+func (repo *cachedRepository) invalidate(ctx context.Context) {
+	scope, ok := tenant.FromContext(ctx)
+	if !ok || tenant.IsAllTenants(ctx) {
+		repo.cache.InvalidateAll()
+		return
+	}
+	repo.cache.Invalidate(scope)
+}
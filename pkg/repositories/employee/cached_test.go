@@ -0,0 +1,63 @@
+package employee
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/cache"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCachedEmployeeRepo(NewEmployeeRepo(db), cache.New(CacheName, cache.NewLRU(100), time.Minute))
+	acme := tenant.WithTenant(context.Background(), "acme")
+	globex := tenant.WithTenant(context.Background(), "globex")
+
+	employee := models.Employee{Name: "John Doe", Position: "Manager", Salary: 50000}
+	db.Create(&employee)
+	// changes made behind the repository are only seen once invalidated
+	rename := func(name string) {
+		db.Model(&models.Employee{}).Where("id = ?", employee.ID).Update("name", name)
+	}
+	nameOf := func(ctx context.Context) string {
+		response, err := repo.GetEmployeeByID(ctx, employee.ID)
+		assert.NoError(t, err)
+		return response.Data.(models.Employee).Name
+	}
+	listedName := func(ctx context.Context) string {
+		response, err := repo.GetAllEmployee(ctx, map[string][]string{"page": {"1"}})
+		assert.NoError(t, err)
+		return response.Data.([]models.Employee)[0].Name
+	}
+
+	assert.Equal(t, "John Doe", nameOf(acme))
+	assert.Equal(t, "John Doe", listedName(acme))
+	rename("Jane Doe")
+	assert.Equal(t, "John Doe", nameOf(acme))
+	assert.Equal(t, "John Doe", listedName(acme))
+
+	// tenants and callers acting for all tenants do not share entries
+	assert.Equal(t, "Jane Doe", nameOf(globex))
+	assert.Equal(t, "Jane Doe", nameOf(tenant.AllTenants(acme)))
+
+	// writes invalidate the entries of their tenant
+	name := "Jim Doe"
+	err := repo.UpdateEmployeeByID(acme, global.DecodeEmployeePUTRequest{ID: employee.ID, Name: &name}, employee)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jim Doe", nameOf(acme))
+	assert.Equal(t, "Jim Doe", listedName(acme))
+	assert.Equal(t, "Jane Doe", nameOf(globex))
+
+	// errors are not cached
+	_, err = repo.GetEmployeeByID(acme, 99)
+	assert.Error(t, err)
+	db.Create(&models.Employee{ID: 99, Name: "Joe Doe", Position: "Manager", Salary: 50000})
+	response, err := repo.GetEmployeeByID(acme, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, "Joe Doe", response.Data.(models.Employee).Name)
+}
@@ -3,16 +3,20 @@ package employee
 import (
 	"context"
 	"errors"
-	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
 	"github.com/jainabhishek5986/employee-records/pkg/errs"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
 	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/replica"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type Repository struct {
@@ -30,15 +34,23 @@ func (repo *Repository) CreateEmployee(ctx context.Context, req global.DecodeEmp
 
 	for _, emp := range req.Employees {
 		employee := models.Employee{
-			Name:     emp.Name,
-			Position: emp.Position,
-			Salary:   emp.Salary,
+			Name:             emp.Name,
+			Position:         emp.Position,
+			Salary:           emp.Salary,
+			EmploymentStatus: employment.StatusActive,
+		}
+		if emp.EmploymentStatus != "" {
+			employee.EmploymentStatus = emp.EmploymentStatus
+		}
+		if emp.HireDate != "" {
+			// the service validated the date
+			employee.HireDate, _ = employment.ParseDate(emp.HireDate)
 		}
 
 		employees = append(employees, employee)
 	}
 
-	err := repo.db.Table(employee.GetTableName()).Create(&employees).Error
+	err := repo.db.WithContext(ctx).Table(employee.GetTableName()).Create(&employees).Error
 	if err != nil {
 		zaplogger.Error(ctx, errs.EmployeeNewRecordError, zap.Error(err))
 		return errs.InternalErr()
@@ -51,24 +63,13 @@ func (repo *Repository) CreateEmployee(ctx context.Context, req global.DecodeEmp
 // GetEmployeeByID
 func (repo *Repository) GetEmployeeByID(ctx context.Context, id int) (response global.SuccessGETInfo, err error) {
 	var employee models.Employee
-	tx := repo.db.Begin()
-	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Table(employee.GetTableName()).Where("id = ?", id).Find(&employee)
-
+	res := repo.db.WithContext(replica.Reads(ctx)).Table(employee.GetTableName()).Where("id = ?", id).Find(&employee)
 	if res.Error != nil {
-		tx.Rollback()
-		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return response, nil
-		}
 		zaplogger.Error(ctx, errs.EmployeeNoRecordFoundError, zap.Error(res.Error))
 		return response, errs.InternalErr()
 	}
-	err = tx.Commit().Error
-	if err != nil {
-		zaplogger.Error(ctx, errs.CommitTransactionError, zap.Error(err))
-		return response, err
-	}
 	if employee.ID == 0 {
-		return response, errs.RequestNotProcessed(errs.EmployeeNoRecordFoundError)
+		return response, errs.NotFoundErr(errs.EmployeeNoRecordFoundError)
 	}
 
 	response = global.SuccessGETInfo{
@@ -80,20 +81,17 @@ func (repo *Repository) GetEmployeeByID(ctx context.Context, id int) (response g
 }
 
 // UpdateEmployeeByID
-func (repo *Repository) UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest) error {
-	var employee models.Employee
-	if request.Name != nil {
-		employee.Name = *request.Name
-	}
-	if request.Position != nil {
-		employee.Position = *request.Position
-	}
-	if request.Salary != nil {
-		employee.Salary = *request.Salary
-	}
+func (repo *Repository) UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest,
+	from models.Employee) error {
+
+	employee := changesOf(request)
 
-	tx := repo.db.Begin()
-	res := tx.Table(employee.GetTableName()).Where("id = ?", request.ID).Updates(employee)
+	tx := repo.db.WithContext(ctx).Begin()
+	// the position and salary are compared so that concurrent updates do
+	// not together change the salary by more than the rules allow
+	res := tx.Table(employee.GetTableName()).
+		Where("id = ? AND position = ? AND salary = ?", request.ID, from.Position, from.Salary).
+		Updates(employee)
 	if res.Error != nil {
 		tx.Rollback()
 		zaplogger.Error(ctx, errs.EmployeeUpdateError, zap.Error(res.Error),
@@ -104,9 +102,19 @@ func (repo *Repository) UpdateEmployeeByID(ctx context.Context, request global.D
 
 	// Check if any rows were affected
 	if res.RowsAffected == 0 {
+		var count int64
+		err := tx.Table(employee.GetTableName()).Where("id = ?", request.ID).Count(&count).Error
 		tx.Rollback()
+		if err != nil {
+			zaplogger.Error(ctx, errs.EmployeeFetchRecordsError, zap.Error(err))
+			return errs.InternalErr()
+		}
+		if count > 0 {
+			zaplogger.Error(ctx, errs.EmployeeConflictError, zap.Int("employee_id", request.ID))
+			return errs.ConflictErr(errs.EmployeeConflictError)
+		}
 		zaplogger.Error(ctx, errs.EmployeeNoRecordFoundError, zap.Int("employee_id", request.ID))
-		return errs.RequestNotProcessed(errs.EmployeeNoRecordFoundError)
+		return errs.NotFoundErr(errs.EmployeeNoRecordFoundError)
 	}
 
 	err := tx.Commit().Error
@@ -126,7 +134,7 @@ func (repo *Repository) DeleteEmployeeByID(ctx context.Context, id int) error {
 	var employee models.Employee
 	employee.ID = id
 
-	tx := repo.db.Begin()
+	tx := repo.db.WithContext(ctx).Begin()
 
 	res := tx.Table(employee.GetTableName()).Where(&employee).Delete(&employee)
 	if res.Error != nil {
@@ -141,7 +149,7 @@ func (repo *Repository) DeleteEmployeeByID(ctx context.Context, id int) error {
 	if res.RowsAffected == 0 {
 		tx.Rollback()
 		zaplogger.Error(ctx, errs.EmployeeNoRecordFoundError, zap.Int("employee_id", id))
-		return errs.RequestNotProcessed(errs.EmployeeNoRecordFoundError)
+		return errs.NotFoundErr(errs.EmployeeNoRecordFoundError)
 	}
 	err := tx.Commit().Error
 	if err != nil {
@@ -179,33 +187,35 @@ func (repo *Repository) GetAllEmployee(ctx context.Context, queryParams map[stri
 		}
 	}
 	offset := (currentPage - 1) * pageSize
-	tx := repo.db.Begin()
+	// the count and the page are read without locking rows, from the same
+	// replica if any
+	db := repo.db.WithContext(replica.Reads(ctx)).Table(employee.GetTableName())
+	// the service validated the statuses, without any the terminated
+	// employees are left out so that the total is the headcount
+	statuses, _ := employment.ParseStatusFilter(queryParams["status"])
+	if len(statuses) > 0 {
+		db = db.Where("employment_status IN ?", statuses)
+	} else {
+		db = db.Where("employment_status <> ?", employment.StatusTerminated)
+	}
 
 	// Get the total count of employees
-	if err := tx.Table(employee.GetTableName()).Count(&totalCount).Error; err != nil {
-		tx.Rollback()
+	if err := db.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
 		zaplogger.Error(ctx, errs.EmployeeFetchRecordsError, zap.Error(err))
 		return response, errs.InternalErr()
 	}
-	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
-		Table(employee.GetTableName()).
+	res := db.Session(&gorm.Session{}).
 		Limit(pageSize).
 		Offset(offset).
 		Find(&employees)
 
 	if res.Error != nil {
-		tx.Rollback()
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			return response, nil
 		}
 		zaplogger.Error(ctx, errs.EmployeeFetchRecordsError, zap.Error(res.Error))
 		return response, errs.InternalErr()
 	}
-	err = tx.Commit().Error
-	if err != nil {
-		zaplogger.Error(ctx, errs.CommitTransactionError, zap.Error(err))
-		return response, err
-	}
 
 	lastPage := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
 	paginationResponse := map[string]int{
@@ -221,3 +231,217 @@ func (repo *Repository) GetAllEmployee(ctx context.Context, queryParams map[stri
 	return response, nil
 
 }
+
+// FindEmployees
+func (repo *Repository) FindEmployees(ctx context.Context, filter global.EmployeeFilter, limit int) ([]models.Employee, error) {
+	var employees []models.Employee
+	var employee models.Employee
+
+	query := repo.db.WithContext(ctx).Table(employee.GetTableName())
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.Name != nil {
+		query = query.Where("name = ?", *filter.Name)
+	}
+	if filter.Position != nil {
+		query = query.Where("position = ?", *filter.Position)
+	}
+	if filter.SalaryMin != nil {
+		query = query.Where("salary >= ?", *filter.SalaryMin)
+	}
+	if filter.SalaryMax != nil {
+		query = query.Where("salary <= ?", *filter.SalaryMax)
+	}
+
+	err := query.Order("id").Limit(limit).Find(&employees).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.EmployeeFetchRecordsError, zap.Error(err))
+		return nil, errs.InternalErr()
+	}
+
+	return employees, nil
+}
+
+// FindEmployeesDue
+func (repo *Repository) FindEmployeesDue(ctx context.Context, statuses []string, dateColumn string,
+	before time.Time, afterID int, limit int) ([]models.Employee, error) {
+
+	var employees []models.Employee
+	var employee models.Employee
+
+	err := repo.db.WithContext(ctx).Table(employee.GetTableName()).
+		Where("employment_status IN ?", statuses).
+		Where(dateColumn+" < ?", before.UTC()).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&employees).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.EmployeeFetchRecordsError, zap.Error(err))
+		return nil, errs.InternalErr()
+	}
+
+	return employees, nil
+}
+
+// UpdateEmployees
+func (repo *Repository) UpdateEmployees(ctx context.Context, requests []global.DecodeEmployeePUTRequest,
+	from map[int]models.Employee, atomic bool) ([]global.BulkItemResult, error) {
+
+	var employee models.Employee
+
+	return repo.bulk(ctx, len(requests), atomic, func(tx *gorm.DB, index int) global.BulkItemResult {
+		request := requests[index]
+		changes := changesOf(request)
+		// compared as in UpdateEmployeeByID, the salary rules were checked
+		// against the position and salary of from
+		res := tx.Table(employee.GetTableName()).
+			Where("id = ? AND position = ? AND salary = ?", request.ID, from[request.ID].Position, from[request.ID].Salary).
+			Updates(changes)
+		if res.Error != nil {
+			zaplogger.Error(ctx, errs.EmployeeUpdateError, zap.Error(res.Error),
+				zap.Int("employee_id", request.ID),
+			)
+			return global.BulkItemResult{ID: request.ID, Status: global.BulkStatusFailed,
+				Code: http.StatusInternalServerError, Error: errs.EmployeeUpdateError}
+		}
+		if res.RowsAffected == 0 {
+			var count int64
+			if err := tx.Table(employee.GetTableName()).Where("id = ?", request.ID).Count(&count).Error; err != nil {
+				zaplogger.Error(ctx, errs.EmployeeFetchRecordsError, zap.Error(err))
+				return global.BulkItemResult{ID: request.ID, Status: global.BulkStatusFailed,
+					Code: http.StatusInternalServerError, Error: errs.EmployeeFetchRecordsError}
+			}
+			if count > 0 {
+				zaplogger.Error(ctx, errs.EmployeeConflictError, zap.Int("employee_id", request.ID))
+				return global.BulkItemResult{ID: request.ID, Status: global.BulkStatusFailed,
+					Code: http.StatusConflict, Error: errs.EmployeeConflictError}
+			}
+			return global.BulkItemResult{ID: request.ID, Status: global.BulkStatusFailed,
+				Code: http.StatusNotFound, Error: errs.EmployeeNoRecordFoundError}
+		}
+		return global.BulkItemResult{ID: request.ID, Status: global.BulkStatusUpdated}
+	})
+}
+
+// DeleteEmployees
+func (repo *Repository) DeleteEmployees(ctx context.Context, ids []int, atomic bool) ([]global.BulkItemResult, error) {
+	var employee models.Employee
+
+	return repo.bulk(ctx, len(ids), atomic, func(tx *gorm.DB, index int) global.BulkItemResult {
+		id := ids[index]
+		res := tx.Table(employee.GetTableName()).Where("id = ?", id).Delete(&employee)
+		if res.Error != nil {
+			zaplogger.Error(ctx, errs.DeleteEmployeeError, zap.Error(res.Error),
+				zap.Int("employee_id", id),
+			)
+			return global.BulkItemResult{ID: id, Status: global.BulkStatusFailed,
+				Code: http.StatusInternalServerError, Error: errs.DeleteEmployeeError}
+		}
+		if res.RowsAffected == 0 {
+			return global.BulkItemResult{ID: id, Status: global.BulkStatusFailed,
+				Code: http.StatusNotFound, Error: errs.EmployeeNoRecordFoundError}
+		}
+		return global.BulkItemResult{ID: id, Status: global.BulkStatusDeleted}
+	})
+}
+
+// ChangeEmploymentStatus
+func (repo *Repository) ChangeEmploymentStatus(ctx context.Context, employee models.Employee, from string,
+	event models.EmploymentEvent) (bool, error) {
+
+	changed := false
+	err := repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the status is compared so that concurrent transitions of the
+		// employee do not both apply
+		res := tx.Table(employee.GetTableName()).
+			Where("id = ? AND employment_status = ?", employee.ID, from).
+			Select("employment_status", "hire_date", "termination_date").
+			Updates(&employee)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if err != nil {
+		zaplogger.Error(ctx, errs.EmploymentTransitionError, zap.Error(err),
+			zap.Int("employee_id", employee.ID),
+		)
+		return false, errs.InternalErr()
+	}
+
+	return changed, nil
+}
+
+// bulk runs apply for every item in one transaction. A failed item is
+// rolled back to its savepoint, or the whole transaction when atomic.
+func (repo *Repository) bulk(ctx context.Context, count int, atomic bool,
+	apply func(tx *gorm.DB, index int) global.BulkItemResult) ([]global.BulkItemResult, error) {
+
+	results := make([]global.BulkItemResult, 0, count)
+	failed := false
+
+	tx := repo.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		zaplogger.Error(ctx, errs.EmployeeUpdateError, zap.Error(tx.Error))
+		return nil, errs.InternalErr()
+	}
+	for index := 0; index < count; index++ {
+		savePoint := fmt.Sprintf("bulk_%d", index)
+		if !atomic {
+			if err := tx.SavePoint(savePoint).Error; err != nil {
+				tx.Rollback()
+				zaplogger.Error(ctx, errs.EmployeeUpdateError, zap.Error(err))
+				return nil, errs.InternalErr()
+			}
+		}
+
+		result := apply(tx, index)
+		if result.Status == global.BulkStatusFailed {
+			failed = true
+			if !atomic {
+				tx.RollbackTo(savePoint)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if atomic && failed {
+		tx.Rollback()
+		for index := range results {
+			if results[index].Status != global.BulkStatusFailed {
+				results[index].Status = global.BulkStatusRolledBack
+			}
+		}
+		return results, nil
+	}
+
+	err := tx.Commit().Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.CommitTransactionError, zap.Error(err))
+		return nil, errs.InternalErr()
+	}
+
+	return results, nil
+}
+
+// changesOf returns the fields set by an update, Updates skips the zero
+// values
+func changesOf(request global.DecodeEmployeePUTRequest) models.Employee {
+	var employee models.Employee
+	if request.Name != nil {
+		employee.Name = *request.Name
+	}
+	if request.Position != nil {
+		employee.Position = *request.Position
+	}
+	if request.Salary != nil {
+		employee.Salary = *request.Salary
+	}
+	return employee
+}
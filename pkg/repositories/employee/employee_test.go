@@ -2,29 +2,23 @@ package employee
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
 	"github.com/jainabhishek5986/employee-records/pkg/errs"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
 	"github.com/jainabhishek5986/employee-records/pkg/models"
-	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("failed to open gorm db, %v", err)
-	}
-
-	err = db.AutoMigrate(&models.Employee{})
-	if err != nil {
-		t.Fatalf("failed to migrate schema, %v", err)
-	}
-
-	zaplogger.InitLogger(global.TestLogFileName)
+	db := testutil.OpenDB(t, &models.Employee{}, &models.EmploymentEvent{})
+	testutil.InitLogger(t)
 	return db
 }
 
@@ -135,7 +129,7 @@ func TestUpdateEmployee(t *testing.T) {
 
 	ctx := context.Background()
 
-	err := repo.UpdateEmployeeByID(ctx, updatedEmployee)
+	err := repo.UpdateEmployeeByID(ctx, updatedEmployee, *employee)
 	assert.NoError(t, err)
 
 	var result models.Employee
@@ -144,6 +138,24 @@ func TestUpdateEmployee(t *testing.T) {
 	assert.Equal(t, *updatedEmployee.Name, result.Name)
 	assert.Equal(t, *updatedEmployee.Position, result.Position)
 	assert.Equal(t, *updatedEmployee.Salary, result.Salary)
+
+	// an update checked against the salary before the first one conflicts
+	raise := 80000.0
+	err = repo.UpdateEmployeeByID(ctx, global.DecodeEmployeePUTRequest{ID: employee.ID, Salary: &raise}, *employee)
+	var httpErr *errs.HTTPError
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, http.StatusConflict, httpErr.StatusCode())
+	}
+	err = db.First(&result, employee.ID).Error
+	assert.NoError(t, err)
+	assert.Equal(t, *updatedEmployee.Salary, result.Salary)
+
+	missing := *employee
+	missing.ID = 999
+	err = repo.UpdateEmployeeByID(ctx, global.DecodeEmployeePUTRequest{ID: missing.ID, Salary: &raise}, missing)
+	if assert.ErrorAs(t, err, &httpErr) {
+		assert.Equal(t, http.StatusNotFound, httpErr.StatusCode())
+	}
 }
 
 func TestDeleteEmployee(t *testing.T) {
@@ -167,3 +179,469 @@ func TestDeleteEmployee(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
 }
+
+func TestFindEmployees(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepo(db)
+
+	employees := []models.Employee{
+		{Name: "Alice", Position: "Engineer", Salary: 60000},
+		{Name: "Bob", Position: "Engineer", Salary: 80000},
+		{Name: "Carol", Position: "Manager", Salary: 90000},
+	}
+	db.Create(&employees)
+
+	engineer := "Engineer"
+	salary := 70000.0
+	testCases := []struct {
+		name        string
+		filter      global.EmployeeFilter
+		limit       int
+		expectedIDs []int
+	}{
+		{
+			name:        "By IDs",
+			filter:      global.EmployeeFilter{IDs: []int{3, 1, 42}},
+			limit:       10,
+			expectedIDs: []int{1, 3},
+		},
+		{
+			name:        "Criteria are combined",
+			filter:      global.EmployeeFilter{Position: &engineer, SalaryMin: &salary},
+			limit:       10,
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "Limited",
+			filter:      global.EmployeeFilter{SalaryMax: &salary},
+			limit:       1,
+			expectedIDs: []int{1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			found, err := repo.FindEmployees(context.Background(), tc.filter, tc.limit)
+			assert.NoError(t, err)
+			ids := make([]int, 0, len(found))
+			for _, employee := range found {
+				ids = append(ids, employee.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestFindEmployeesDue(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepo(db)
+
+	day := func(d int) *time.Time {
+		date := time.Date(2024, 7, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	employees := []models.Employee{
+		{Name: "Alice", Position: "Engineer", Salary: 1, EmploymentStatus: employment.StatusOnboarding, HireDate: day(1)},
+		{Name: "Bob", Position: "Engineer", Salary: 1, EmploymentStatus: employment.StatusOnboarding, HireDate: day(2)},
+		{Name: "Carol", Position: "Engineer", Salary: 1, EmploymentStatus: employment.StatusRehired, HireDate: day(1)},
+		{Name: "Dave", Position: "Engineer", Salary: 1, EmploymentStatus: employment.StatusActive, HireDate: day(1)},
+		{Name: "Erin", Position: "Engineer", Salary: 1, EmploymentStatus: employment.StatusOnboarding},
+	}
+	db.Create(&employees)
+
+	statuses := []string{employment.StatusOnboarding, employment.StatusRehired}
+	testCases := []struct {
+		name        string
+		afterID     int
+		limit       int
+		expectedIDs []int
+	}{
+		{
+			name:        "Due in the statuses",
+			limit:       10,
+			expectedIDs: []int{1, 3},
+		},
+		{
+			name:        "After an id",
+			afterID:     1,
+			limit:       10,
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "Limited",
+			limit:       1,
+			expectedIDs: []int{1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			found, err := repo.FindEmployeesDue(context.Background(), statuses, "hire_date", *day(2),
+				tc.afterID, tc.limit)
+			assert.NoError(t, err)
+			ids := make([]int, 0, len(found))
+			for _, employee := range found {
+				ids = append(ids, employee.ID)
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestUpdateEmployees(t *testing.T) {
+	senior := "Senior Engineer"
+
+	testCases := []struct {
+		name              string
+		ids               []int
+		stale             int
+		atomic            bool
+		expectedStatuses  []string
+		expectedCodes     []int
+		expectedPositions []string
+	}{
+		{
+			name:              "Missing employee fails alone",
+			ids:               []int{1, 42, 2},
+			expectedStatuses:  []string{global.BulkStatusUpdated, global.BulkStatusFailed, global.BulkStatusUpdated},
+			expectedCodes:     []int{0, http.StatusNotFound, 0},
+			expectedPositions: []string{senior, senior},
+		},
+		{
+			name:              "Atomic update is rolled back",
+			ids:               []int{1, 42, 2},
+			atomic:            true,
+			expectedStatuses:  []string{global.BulkStatusRolledBack, global.BulkStatusFailed, global.BulkStatusRolledBack},
+			expectedCodes:     []int{0, http.StatusNotFound, 0},
+			expectedPositions: []string{"Engineer", "Engineer"},
+		},
+		{
+			name:              "Employee changed since it was read conflicts",
+			ids:               []int{1, 2},
+			stale:             2,
+			expectedStatuses:  []string{global.BulkStatusUpdated, global.BulkStatusFailed},
+			expectedCodes:     []int{0, http.StatusConflict},
+			expectedPositions: []string{senior, "Engineer"},
+		},
+		{
+			name:              "Atomic update with a conflict is rolled back",
+			ids:               []int{1, 2},
+			stale:             2,
+			atomic:            true,
+			expectedStatuses:  []string{global.BulkStatusRolledBack, global.BulkStatusFailed},
+			expectedCodes:     []int{0, http.StatusConflict},
+			expectedPositions: []string{"Engineer", "Engineer"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			repo := NewEmployeeRepo(db)
+			db.Create(&[]models.Employee{
+				{Name: "Alice", Position: "Engineer", Salary: 60000},
+				{Name: "Bob", Position: "Engineer", Salary: 80000},
+			})
+
+			var read []models.Employee
+			db.Find(&read)
+			from := make(map[int]models.Employee, len(read))
+			for _, employee := range read {
+				from[employee.ID] = employee
+			}
+			// a concurrent update raised the salary after it was read
+			db.Model(&models.Employee{}).Where("id = ?", tc.stale).Update("salary", 120000)
+
+			requests := make([]global.DecodeEmployeePUTRequest, 0, len(tc.ids))
+			for _, id := range tc.ids {
+				requests = append(requests, global.DecodeEmployeePUTRequest{ID: id, Position: &senior})
+			}
+			results, err := repo.UpdateEmployees(context.Background(), requests, from, tc.atomic)
+			assert.NoError(t, err)
+			assert.Len(t, results, len(tc.ids))
+			for index, result := range results {
+				assert.Equal(t, tc.ids[index], result.ID)
+				assert.Equal(t, tc.expectedStatuses[index], result.Status)
+				assert.Equal(t, tc.expectedCodes[index], result.Code)
+			}
+
+			var stored []models.Employee
+			db.Order("id").Find(&stored)
+			assert.Len(t, stored, len(tc.expectedPositions))
+			for index, employee := range stored {
+				assert.Equal(t, tc.expectedPositions[index], employee.Position)
+			}
+		})
+	}
+}
+
+func TestDeleteEmployees(t *testing.T) {
+	testCases := []struct {
+		name             string
+		ids              []int
+		atomic           bool
+		expectedStatuses []string
+		expectedLeft     int64
+	}{
+		{
+			name:             "Missing employee fails alone",
+			ids:              []int{1, 42},
+			expectedStatuses: []string{global.BulkStatusDeleted, global.BulkStatusFailed},
+			expectedLeft:     1,
+		},
+		{
+			name:             "Atomic delete is rolled back",
+			ids:              []int{1, 42},
+			atomic:           true,
+			expectedStatuses: []string{global.BulkStatusRolledBack, global.BulkStatusFailed},
+			expectedLeft:     2,
+		},
+		{
+			name:             "Every employee deleted",
+			ids:              []int{1, 2},
+			atomic:           true,
+			expectedStatuses: []string{global.BulkStatusDeleted, global.BulkStatusDeleted},
+			expectedLeft:     0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			repo := NewEmployeeRepo(db)
+			db.Create(&[]models.Employee{
+				{Name: "Alice", Position: "Engineer", Salary: 60000},
+				{Name: "Bob", Position: "Engineer", Salary: 80000},
+			})
+
+			results, err := repo.DeleteEmployees(context.Background(), tc.ids, tc.atomic)
+			assert.NoError(t, err)
+			for index, result := range results {
+				assert.Equal(t, tc.expectedStatuses[index], result.Status)
+			}
+
+			var left int64
+			db.Model(&models.Employee{}).Count(&left)
+			assert.Equal(t, tc.expectedLeft, left)
+		})
+	}
+}
+
+func TestGetAllEmployeeByStatus(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepo(db)
+
+	db.Create(&[]models.Employee{
+		{Name: "John Doe", Position: "Manager", Salary: 50000, EmploymentStatus: employment.StatusActive},
+		{Name: "Jane Smith", Position: "Developer", Salary: 60000, EmploymentStatus: employment.StatusOnLeave},
+		{Name: "Jim Brown", Position: "Developer", Salary: 60000, EmploymentStatus: employment.StatusTerminated},
+	})
+
+	testCases := []struct {
+		name          string
+		queryParams   map[string][]string
+		expectedNames []string
+	}{
+		{
+			name:          "Terminated employees are left out of the headcount",
+			queryParams:   map[string][]string{},
+			expectedNames: []string{"John Doe", "Jane Smith"},
+		},
+		{
+			name:          "Filter by one status",
+			queryParams:   map[string][]string{"status": {"terminated"}},
+			expectedNames: []string{"Jim Brown"},
+		},
+		{
+			name:          "Filter by a list of statuses",
+			queryParams:   map[string][]string{"status": {"terminated,on_leave"}},
+			expectedNames: []string{"Jane Smith", "Jim Brown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response, err := repo.GetAllEmployee(context.Background(), tc.queryParams)
+			assert.NoError(t, err)
+			names := make([]string, 0)
+			for _, employee := range response.Data.([]models.Employee) {
+				names = append(names, employee.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedNames, names)
+			assert.Equal(t, len(tc.expectedNames), response.Pagination.(map[string]int)["total"])
+		})
+	}
+}
+
+func TestChangeEmploymentStatus(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewEmployeeRepo(db)
+	ctx := context.Background()
+
+	employee := models.Employee{Name: "John Doe", Position: "Manager", Salary: 50000}
+	db.Create(&employee)
+	assert.Equal(t, employment.StatusActive, employee.EmploymentStatus)
+
+	lastWorkingDay := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	terminated := employee
+	terminated.EmploymentStatus = employment.StatusTerminated
+	terminated.TerminationDate = &lastWorkingDay
+	event := models.EmploymentEvent{
+		EmployeeID: employee.ID,
+		Action:     employment.ActionTerminate,
+		FromStatus: employment.StatusActive,
+		ToStatus:   employment.StatusTerminated,
+		Reason:     "Resigned",
+	}
+
+	changed, err := repo.ChangeEmploymentStatus(ctx, terminated, employment.StatusActive, event)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	var stored models.Employee
+	assert.NoError(t, db.First(&stored, employee.ID).Error)
+	assert.Equal(t, employment.StatusTerminated, stored.EmploymentStatus)
+	if assert.NotNil(t, stored.TerminationDate) {
+		assert.True(t, lastWorkingDay.Equal(*stored.TerminationDate))
+	}
+	var events []models.EmploymentEvent
+	assert.NoError(t, db.Find(&events).Error)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "Resigned", events[0].Reason)
+	}
+
+	// a transition from a status the employee left is not applied
+	changed, err = repo.ChangeEmploymentStatus(ctx, terminated, employment.StatusActive, event)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoError(t, db.Find(&events).Error)
+	assert.Len(t, events, 1)
+}
+
+func TestRepositoryRespectsTenant(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, tenant.RegisterGORM(db, &models.Employee{}))
+	repo := NewEmployeeRepo(db)
+
+	acme := tenant.WithTenant(context.Background(), "acme")
+	globex := tenant.WithTenant(context.Background(), "globex")
+	all := tenant.AllTenants(context.Background())
+
+	// rows created through the repository get the tenant of the context
+	assert.NoError(t, repo.CreateEmployee(acme, global.DecodeEmployeesPOSTRequest{
+		Employees: []global.DecodeEmployee{{Name: "Alice", Position: "Engineer", Salary: 60000}},
+	}))
+	assert.NoError(t, repo.CreateEmployee(globex, global.DecodeEmployeesPOSTRequest{
+		Employees: []global.DecodeEmployee{{Name: "Bob", Position: "Engineer", Salary: 80000}},
+	}))
+	carol := models.Employee{TenantID: "acme", Name: "Carol", Position: "Engineer", Salary: 70000}
+	assert.NoError(t, db.WithContext(all).Create(&carol).Error)
+
+	var alice models.Employee
+	assert.NoError(t, db.WithContext(all).Where("name = ?", "Alice").Take(&alice).Error)
+	assert.Equal(t, "acme", alice.TenantID)
+
+	name := "Mallory"
+	assertNotFound := func(t *testing.T, err error) {
+		var httpErr *errs.HTTPError
+		if assert.ErrorAs(t, err, &httpErr) {
+			assert.Equal(t, http.StatusNotFound, httpErr.StatusCode())
+		}
+	}
+
+	// every method runs for globex against the rows of acme
+	testCases := []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{
+			name: "GetEmployeeByID",
+			check: func(t *testing.T) {
+				_, err := repo.GetEmployeeByID(globex, alice.ID)
+				assertNotFound(t, err)
+			},
+		},
+		{
+			name: "UpdateEmployeeByID",
+			check: func(t *testing.T) {
+				err := repo.UpdateEmployeeByID(globex, global.DecodeEmployeePUTRequest{ID: alice.ID, Name: &name}, alice)
+				assertNotFound(t, err)
+			},
+		},
+		{
+			name: "DeleteEmployeeByID",
+			check: func(t *testing.T) {
+				assertNotFound(t, repo.DeleteEmployeeByID(globex, alice.ID))
+			},
+		},
+		{
+			name: "GetAllEmployee",
+			check: func(t *testing.T) {
+				response, err := repo.GetAllEmployee(globex, map[string][]string{})
+				assert.NoError(t, err)
+				employees := response.Data.([]models.Employee)
+				if assert.Len(t, employees, 1) {
+					assert.Equal(t, "Bob", employees[0].Name)
+				}
+				assert.Equal(t, 1, response.Pagination.(map[string]int)["total"])
+			},
+		},
+		{
+			name: "FindEmployees",
+			check: func(t *testing.T) {
+				employees, err := repo.FindEmployees(globex, global.EmployeeFilter{IDs: []int{alice.ID}}, 10)
+				assert.NoError(t, err)
+				assert.Empty(t, employees)
+			},
+		},
+		{
+			name: "UpdateEmployees",
+			check: func(t *testing.T) {
+				results, err := repo.UpdateEmployees(globex, []global.DecodeEmployeePUTRequest{{ID: alice.ID, Name: &name}},
+					map[int]models.Employee{alice.ID: alice}, false)
+				assert.NoError(t, err)
+				assert.Equal(t, global.BulkStatusFailed, results[0].Status)
+			},
+		},
+		{
+			name: "DeleteEmployees",
+			check: func(t *testing.T) {
+				results, err := repo.DeleteEmployees(globex, []int{alice.ID}, false)
+				assert.NoError(t, err)
+				assert.Equal(t, global.BulkStatusFailed, results[0].Status)
+			},
+		},
+		{
+			name: "ChangeEmploymentStatus",
+			check: func(t *testing.T) {
+				terminated := alice
+				terminated.EmploymentStatus = employment.StatusTerminated
+				changed, err := repo.ChangeEmploymentStatus(globex, terminated, employment.StatusActive,
+					models.EmploymentEvent{EmployeeID: alice.ID})
+				assert.NoError(t, err)
+				assert.False(t, changed)
+			},
+		},
+		{
+			name: "Context without tenant",
+			check: func(t *testing.T) {
+				_, err := repo.GetEmployeeByID(context.Background(), alice.ID)
+				assert.Equal(t, errs.InternalErr(), err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.check(t)
+		})
+	}
+
+	// the rows of acme are untouched
+	var left []models.Employee
+	assert.NoError(t, db.WithContext(acme).Order("id").Find(&left).Error)
+	if assert.Len(t, left, 2) {
+		assert.Equal(t, "Alice", left[0].Name)
+		assert.Equal(t, "Carol", left[1].Name)
+	}
+}
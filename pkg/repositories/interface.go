@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
 )
 
 /*
@@ -12,7 +14,87 @@ EmployeeRepository : Employee Repository Interface
 type EmployeeRepository interface {
 	CreateEmployee(ctx context.Context, request global.DecodeEmployeesPOSTRequest) error
 	GetEmployeeByID(ctx context.Context, id int) (global.SuccessGETInfo, error)
-	UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest) error
+	// UpdateEmployeeByID applies the update while the employee still has the
+	// position and salary of from, against which the salary rules were checked
+	UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest, from models.Employee) error
 	DeleteEmployeeByID(ctx context.Context, id int) error
 	GetAllEmployee(ctx context.Context, queryParams map[string][]string) (global.SuccessGETInfo, error)
+	// FindEmployees returns up to limit employees selected by the filter
+	FindEmployees(ctx context.Context, filter global.EmployeeFilter, limit int) ([]models.Employee, error)
+	// FindEmployeesDue returns up to limit employees with an id above afterID
+	// in one of the statuses whose dateColumn, hire_date or termination_date,
+	// is before the time
+	FindEmployeesDue(ctx context.Context, statuses []string, dateColumn string, before time.Time,
+		afterID int, limit int) ([]models.Employee, error)
+	// UpdateEmployees applies the updates in one transaction while each
+	// employee still has the position and salary of from, atomic rolls
+	// every update back once one fails
+	UpdateEmployees(ctx context.Context, requests []global.DecodeEmployeePUTRequest, from map[int]models.Employee,
+		atomic bool) ([]global.BulkItemResult, error)
+	// DeleteEmployees deletes the employees in one transaction, atomic rolls
+	// every delete back once one fails
+	DeleteEmployees(ctx context.Context, ids []int, atomic bool) ([]global.BulkItemResult, error)
+	// ChangeEmploymentStatus stores the status and dates of the employee
+	// with its audit event in one transaction, it reports false when the
+	// employee is no longer in status from
+	ChangeEmploymentStatus(ctx context.Context, employee models.Employee, from string,
+		event models.EmploymentEvent) (bool, error)
+}
+
+/*
+APIKeyRepository : API Key Repository Interface
+*/
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int, at time.Time) error
+	TouchAPIKey(ctx context.Context, id int, at time.Time) error
+	// PurgeAPIKeys removes the keys revoked or expired before the given time
+	PurgeAPIKeys(ctx context.Context, before time.Time) (int64, error)
+	// CountAPIKeys counts the stored keys, revoked ones included
+	CountAPIKeys(ctx context.Context) (int64, error)
+}
+
+/*
+JobRepository : Job Run History and Lease Repository Interface
+*/
+type JobRepository interface {
+	CreateJobRun(ctx context.Context, run *models.JobRun) error
+	FinishJobRun(ctx context.Context, run *models.JobRun) error
+	ListJobRuns(ctx context.Context, job string, limit int) ([]models.JobRun, error)
+	PurgeJobRuns(ctx context.Context, before time.Time) (int64, error)
+	// AbandonJobRuns fails the runs of the job other holders left running,
+	// it is called by the holder of the job lease
+	AbandonJobRuns(ctx context.Context, job string, holder string, now time.Time) (int64, error)
+	// AcquireLease takes or renews the lease, it reports false while
+	// another holder owns an unexpired lease
+	AcquireLease(ctx context.Context, name string, holder string, ttl time.Duration, now time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, name string, holder string) error
+}
+
+/*
+AsyncJobRepository : Asynchronous Job Repository Interface. The status
+updates of a run only apply while the job is running, so that a
+cancellation is never overwritten.
+*/
+type AsyncJobRepository interface {
+	CreateAsyncJob(ctx context.Context, job *models.AsyncJob) error
+	CountQueuedAsyncJobs(ctx context.Context) (int64, error)
+	// GetAsyncJob returns the job without its payload and output
+	GetAsyncJob(ctx context.Context, id string) (models.AsyncJob, error)
+	GetAsyncJobOutput(ctx context.Context, id string) (models.AsyncJob, error)
+	// ClaimNextAsyncJob marks the oldest queued job running, it reports
+	// false when no job is queued
+	ClaimNextAsyncJob(ctx context.Context, now time.Time) (models.AsyncJob, bool, error)
+	// UpdateAsyncJobProgress stores the progress and heartbeat of a running
+	// job, it reports false once the job is no longer running
+	UpdateAsyncJobProgress(ctx context.Context, id string, progress int, now time.Time) (bool, error)
+	FinishAsyncJob(ctx context.Context, job *models.AsyncJob) (bool, error)
+	CancelAsyncJob(ctx context.Context, id string, now time.Time) (bool, error)
+	RequeueAsyncJob(ctx context.Context, id string) error
+	// ExpireAsyncJobs releases the running jobs without a heartbeat since
+	// before, jobs of the rerun kinds are queued again and the others fail
+	ExpireAsyncJobs(ctx context.Context, before time.Time, now time.Time, rerun []string) (int64, error)
+	PurgeAsyncJobs(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,158 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func NewJobRepo(db *gorm.DB) repositories.JobRepository {
+	return &Repository{db: db}
+}
+
+// CreateJobRun
+func (repo *Repository) CreateJobRun(ctx context.Context, run *models.JobRun) error {
+	run.StartedAt = run.StartedAt.UTC()
+	err := repo.db.WithContext(ctx).Table(run.GetTableName()).Create(run).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.JobRunRecordError, zap.Error(err), zap.String("job", run.Job))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
+
+// FinishJobRun stores the outcome of a run
+func (repo *Repository) FinishJobRun(ctx context.Context, run *models.JobRun) error {
+	if run.FinishedAt != nil {
+		finished := run.FinishedAt.UTC()
+		run.FinishedAt = &finished
+	}
+	err := repo.db.WithContext(ctx).Table(run.GetTableName()).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"result":      run.Result,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+		}).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.JobRunRecordError, zap.Error(err), zap.Int("job_run_id", run.ID))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
+
+// ListJobRuns returns the latest runs of a job, newest first
+func (repo *Repository) ListJobRuns(ctx context.Context, job string, limit int) ([]models.JobRun, error) {
+	var run models.JobRun
+	runs := make([]models.JobRun, 0)
+
+	err := repo.db.WithContext(ctx).Table(run.GetTableName()).
+		Where("job = ?", job).
+		Order("started_at DESC, id DESC").
+		Limit(limit).
+		Find(&runs).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.JobRunFetchError, zap.Error(err), zap.String("job", job))
+		return nil, errs.InternalErr()
+	}
+
+	return runs, nil
+}
+
+// PurgeJobRuns deletes the finished runs started before the given time
+func (repo *Repository) PurgeJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	var run models.JobRun
+
+	res := repo.db.WithContext(ctx).Table(run.GetTableName()).
+		Where("started_at < ? AND status <> ?", before.UTC(), models.JobStatusRunning).
+		Delete(&run)
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.JobPurgeError, zap.Error(res.Error), zap.String("table", run.GetTableName()))
+		return 0, errs.InternalErr()
+	}
+
+	return res.RowsAffected, nil
+}
+
+// AbandonJobRuns marks the runs of the job left running by other holders
+// failed, their holder lost the job lease, e.g. when its instance died
+func (repo *Repository) AbandonJobRuns(ctx context.Context, job string, holder string, now time.Time) (int64, error) {
+	var run models.JobRun
+
+	res := repo.db.WithContext(ctx).Table(run.GetTableName()).
+		Where("job = ? AND status = ? AND holder <> ?", job, models.JobStatusRunning, holder).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusFailed,
+			"error":       errs.JobRunAbandonedError,
+			"finished_at": now.UTC(),
+		})
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.JobRunRecordError, zap.Error(res.Error), zap.String("job", job))
+		return 0, errs.InternalErr()
+	}
+
+	return res.RowsAffected, nil
+}
+
+// AcquireLease renews a lease of the holder or takes over an expired one,
+// a missing lease is inserted. Only one of concurrent callers succeeds.
+func (repo *Repository) AcquireLease(ctx context.Context, name string, holder string,
+	ttl time.Duration, now time.Time) (bool, error) {
+
+	var lease models.JobLease
+	now = now.UTC()
+
+	res := repo.db.WithContext(ctx).Table(lease.GetTableName()).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+		Updates(map[string]interface{}{
+			"holder":     holder,
+			"expires_at": now.Add(ttl),
+		})
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.JobLeaseError, zap.Error(res.Error), zap.String("lease", name))
+		return false, errs.InternalErr()
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	lease = models.JobLease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}
+	res = repo.db.WithContext(ctx).Table(lease.GetTableName()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&lease)
+	if res.Error != nil {
+		zaplogger.Error(ctx, errs.JobLeaseError, zap.Error(res.Error), zap.String("lease", name))
+		return false, errs.InternalErr()
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+// ReleaseLease gives up a lease of the holder
+func (repo *Repository) ReleaseLease(ctx context.Context, name string, holder string) error {
+	var lease models.JobLease
+
+	err := repo.db.WithContext(ctx).Table(lease.GetTableName()).
+		Where("name = ? AND holder = ?", name, holder).
+		Delete(&lease).Error
+	if err != nil {
+		zaplogger.Error(ctx, errs.JobLeaseError, zap.Error(err), zap.String("lease", name))
+		return errs.InternalErr()
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package reqctx
+
+import "context"
+
+// Request scoped values shared by the transport, logging and tracing layers.
+// Each value has its own unexported key type so packages can't collide.
+type (
+	requestIDKey struct{}
+	routeKey     struct{}
+	userIDKey    struct{}
+	traceIDKey   struct{}
+	tenantIDKey  struct{}
+)
+
+// RequestIDHeader is the header used to accept and echo the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// WithRequestID stores the request ID in the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in the context, if any
+func RequestID(ctx context.Context) string {
+	return stringValue(ctx, requestIDKey{})
+}
+
+// WithRoute stores the matched route template in the context
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// Route returns the route template stored in the context, if any
+func Route(ctx context.Context) string {
+	return stringValue(ctx, routeKey{})
+}
+
+// WithUserID stores the authenticated caller in the context
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID returns the authenticated caller stored in the context, if any
+func UserID(ctx context.Context) string {
+	return stringValue(ctx, userIDKey{})
+}
+
+// WithTraceID stores the trace ID in the context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID returns the trace ID stored in the context, if any
+func TraceID(ctx context.Context) string {
+	return stringValue(ctx, traceIDKey{})
+}
+
+// WithTenantID stores the tenant the request acts for in the context
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey{}, tenantID)
+}
+
+// TenantID returns the tenant stored in the context, if any
+func TenantID(ctx context.Context) string {
+	return stringValue(ctx, tenantIDKey{})
+}
+
+func stringValue(ctx context.Context, key interface{}) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
@@ -0,0 +1,439 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/cron"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+const (
+	// leaderLease is held by the instance running the scheduled jobs
+	leaderLease = "scheduler"
+	// jobLeasePrefix names the lease held while a job runs, so that a
+	// manual run never overlaps a scheduled one on another instance
+	jobLeasePrefix = "job:"
+	// defaultTimeout applies to jobs without a catalog entry
+	defaultTimeout = 5 * time.Minute
+	// recordTimeout bounds storing the outcome of a run, which also
+	// happens when the run was cancelled by the shutdown
+	recordTimeout = 5 * time.Second
+	// historySize is the number of runs listed per job
+	historySize = 10
+)
+
+// Job : background work of the catalog. Run returns a short summary of what
+// it did, e.g. the number of purged rows.
+type Job struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context) (string, error)
+}
+
+// entry : a registered job with its schedule
+type entry struct {
+	job      Job
+	config   config.JobConfig
+	schedule cron.Schedule
+	next     time.Time
+	running  atomic.Bool
+}
+
+// Scheduler : runs the jobs of the catalog on their cron schedule. The
+// instances elect a leader through a database lease and only the leader
+// runs scheduled jobs, manual runs are started on the instance that
+// received the trigger.
+type Scheduler struct {
+	repo    repositories.JobRepository
+	cfg     config.JobsConfig
+	holder  string
+	entries map[string]*entry
+	names   []string
+	tick    time.Duration
+	now     func() time.Time
+
+	mu           sync.Mutex
+	runCtx       context.Context
+	leader       bool
+	campaignedAt time.Time
+}
+
+/*
+New : returns a scheduler for the jobs config, jobs are added with Register
+before it is started
+
+Parameters
+----------
+repo: run history and lease repository
+cfg: jobs config
+*/
+func New(repo repositories.JobRepository, cfg config.JobsConfig) *Scheduler {
+	return &Scheduler{
+		repo:    repo,
+		cfg:     cfg,
+		holder:  holderID(),
+		entries: make(map[string]*entry),
+		tick:    time.Second,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// holderID identifies the instance, the random suffix tells restarts of a
+// process apart
+func holderID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+/*
+Register : adds a job, it runs on the schedule of its catalog entry. Jobs
+without an enabled entry only run when triggered.
+
+Parameters
+----------
+job: job to add, names are lower cased
+*/
+func (s *Scheduler) Register(job Job) error {
+	job.Name = strings.ToLower(job.Name)
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("job %q is already registered", job.Name)
+	}
+
+	e := &entry{job: job, config: s.cfg.Catalog[job.Name]}
+	if e.config.Timeout <= 0 {
+		e.config.Timeout = defaultTimeout
+	}
+	if e.config.Enabled {
+		schedule, err := cron.Parse(e.config.Schedule)
+		if err != nil {
+			return fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		e.schedule = schedule
+	}
+
+	s.entries[job.Name] = e
+	s.names = append(s.names, job.Name)
+	sort.Strings(s.names)
+	return nil
+}
+
+/*
+Start : starts the scheduling loop, it ends with ctx. The loop and the job
+runs are tracked by waitgroup.Gwg so that the shutdown waits for them.
+
+Parameters
+----------
+ctx: run context of the process
+*/
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.mu.Unlock()
+
+	for name := range s.cfg.Catalog {
+		if _, ok := s.entries[name]; !ok {
+			zaplogger.Warn(ctx, "Catalog entry without a registered job", zap.String("job", name))
+		}
+	}
+	if !s.cfg.Enabled {
+		zaplogger.Info(ctx, "Job scheduler disabled, jobs only run when triggered")
+		return nil
+	}
+
+	waitgroup.Add(1)
+	go func() {
+		defer waitgroup.Done()
+		s.loop(ctx)
+	}()
+	zaplogger.Info(ctx, "Job scheduler started", zap.String("holder", s.holder), zap.Int("jobs", len(s.names)))
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	now := s.now()
+	for _, e := range s.entries {
+		if e.schedule != nil {
+			e.next = e.schedule.Next(now)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.resign()
+			return
+		case <-ticker.C:
+			s.runDue(ctx, s.now())
+		}
+	}
+}
+
+// runDue starts the jobs whose activation passed, every instance keeps
+// the schedule but only the leader runs it
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	leader := s.campaign(ctx, now)
+	for _, name := range s.names {
+		e := s.entries[name]
+		if e.schedule == nil || e.next.IsZero() || now.Before(e.next) {
+			continue
+		}
+		e.next = e.schedule.Next(now)
+		if !leader {
+			continue
+		}
+		// an overlapping run is skipped, the next activation catches up
+		if _, err := s.launch(ctx, e, models.JobTriggerSchedule); err != nil {
+			zaplogger.Warn(ctx, "Skipped scheduled job run", zap.String("job", name), zap.Error(err))
+		}
+	}
+}
+
+// campaign takes or renews the leader lease, a third of the lease
+// duration apart
+func (s *Scheduler) campaign(ctx context.Context, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.campaignedAt.IsZero() && now.Sub(s.campaignedAt) < s.cfg.LeaseDuration/3 {
+		return s.leader
+	}
+	s.campaignedAt = now
+
+	acquired, err := s.repo.AcquireLease(ctx, leaderLease, s.holder, s.cfg.LeaseDuration, now)
+	if err != nil {
+		// without a database we can not tell whether another instance leads
+		acquired = false
+	}
+	if acquired != s.leader {
+		if acquired {
+			zaplogger.Info(ctx, "Elected scheduler leader", zap.String("holder", s.holder))
+		} else {
+			zaplogger.Warn(ctx, "Lost scheduler leadership", zap.String("holder", s.holder))
+		}
+	}
+	s.leader = acquired
+	return acquired
+}
+
+// resign releases the leader lease so that another instance takes over
+// without waiting for it to expire
+func (s *Scheduler) resign() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.leader {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	_ = s.repo.ReleaseLease(ctx, leaderLease, s.holder)
+	s.leader = false
+}
+
+/*
+TriggerJob : starts a manual run of a job and returns it while it is
+running
+
+Parameters
+----------
+ctx: request context
+name: job name
+*/
+func (s *Scheduler) TriggerJob(ctx context.Context, name string) (global.JobRunInfo, error) {
+	e, ok := s.entries[strings.ToLower(name)]
+	if !ok {
+		return global.JobRunInfo{}, errs.RequestNotProcessed(errs.JobNotFoundError)
+	}
+
+	run, err := s.launch(ctx, e, models.JobTriggerManual)
+	if err != nil {
+		return global.JobRunInfo{}, err
+	}
+	return runInfo(run), nil
+}
+
+// launch takes the job lease, records the run and runs the job in the
+// background under the run context of the scheduler
+func (s *Scheduler) launch(ctx context.Context, e *entry, trigger string) (models.JobRun, error) {
+	s.mu.Lock()
+	runCtx := s.runCtx
+	s.mu.Unlock()
+	if runCtx == nil || runCtx.Err() != nil {
+		return models.JobRun{}, errs.InternalErrWithMsg(errs.SchedulerNotRunningError)
+	}
+
+	// the lease is renewed by its own holder, the flag keeps the runs of
+	// this instance from overlapping
+	if !e.running.CompareAndSwap(false, true) {
+		return models.JobRun{}, errs.ConflictErr(errs.JobAlreadyRunningError)
+	}
+	lease := jobLeasePrefix + e.job.Name
+	now := s.now()
+	acquired, err := s.repo.AcquireLease(ctx, lease, s.holder, e.config.Timeout+recordTimeout, now)
+	if err != nil {
+		e.running.Store(false)
+		return models.JobRun{}, err
+	}
+	if !acquired {
+		e.running.Store(false)
+		return models.JobRun{}, errs.ConflictErr(errs.JobAlreadyRunningError)
+	}
+	// a run of another instance still running lost the lease taken here,
+	// it would otherwise be listed as running forever
+	abandoned, err := s.repo.AbandonJobRuns(ctx, e.job.Name, s.holder, now)
+	if err != nil {
+		_ = s.repo.ReleaseLease(ctx, lease, s.holder)
+		e.running.Store(false)
+		return models.JobRun{}, err
+	}
+	if abandoned > 0 {
+		zaplogger.Warn(ctx, errs.JobRunAbandonedError, zap.String("job", e.job.Name), zap.Int64("runs", abandoned))
+	}
+
+	run := models.JobRun{
+		Job:       e.job.Name,
+		Trigger:   trigger,
+		Holder:    s.holder,
+		Status:    models.JobStatusRunning,
+		StartedAt: now,
+	}
+	if err := s.repo.CreateJobRun(ctx, &run); err != nil {
+		_ = s.repo.ReleaseLease(ctx, lease, s.holder)
+		e.running.Store(false)
+		return models.JobRun{}, err
+	}
+
+	zaplogger.Info(ctx, global.JobRunStartedSuccessfully,
+		zap.String("job", run.Job), zap.String("trigger", trigger), zap.Int("job_run_id", run.ID))
+	waitgroup.Add(1)
+	go func() {
+		defer waitgroup.Done()
+		defer e.running.Store(false)
+		s.execute(runCtx, e, run, lease)
+	}()
+	return run, nil
+}
+
+// execute runs the job within its timeout and records the outcome
+func (s *Scheduler) execute(ctx context.Context, e *entry, run models.JobRun, lease string) {
+	jobCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	result, err := safeRun(jobCtx, e.job)
+	finished := s.now()
+	run.FinishedAt = &finished
+	run.Result = result
+	run.Status = models.JobStatusSucceeded
+	if err != nil {
+		run.Status = models.JobStatusFailed
+		run.Error = err.Error()
+	}
+
+	// the run context is done on shutdown, the outcome is still recorded
+	recordCtx, cancelRecord := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancelRecord()
+	_ = s.repo.FinishJobRun(recordCtx, &run)
+	_ = s.repo.ReleaseLease(recordCtx, lease, s.holder)
+
+	fields := []zap.Field{
+		zap.String("job", run.Job),
+		zap.String("trigger", run.Trigger),
+		zap.Int("job_run_id", run.ID),
+		zap.Duration("duration", finished.Sub(run.StartedAt)),
+	}
+	if err != nil {
+		zaplogger.Error(ctx, errs.JobRunFailedError, append(fields, zap.Error(err))...)
+		return
+	}
+	zaplogger.Info(ctx, global.JobRunFinishedSuccessfully, append(fields, zap.String("result", result))...)
+}
+
+// safeRun turns a panic of a job into a failed run
+func safeRun(ctx context.Context, job Job) (result string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+/*
+ListJobs : returns the jobs with their schedule and latest runs
+
+Parameters
+----------
+ctx: request context
+*/
+func (s *Scheduler) ListJobs(ctx context.Context) (global.JobsInfo, error) {
+	s.mu.Lock()
+	info := global.JobsInfo{Holder: s.holder, Leader: s.leader, Jobs: make([]global.JobInfo, 0, len(s.names))}
+	s.mu.Unlock()
+
+	for _, name := range s.names {
+		e := s.entries[name]
+		runs, err := s.repo.ListJobRuns(ctx, name, historySize)
+		if err != nil {
+			return global.JobsInfo{}, err
+		}
+
+		job := global.JobInfo{
+			Name:        name,
+			Description: e.job.Description,
+			Enabled:     s.cfg.Enabled && e.schedule != nil,
+			Timeout:     e.config.Timeout.String(),
+			Runs:        make([]global.JobRunInfo, 0, len(runs)),
+		}
+		if e.schedule != nil {
+			job.Schedule = e.config.Schedule
+		}
+		if job.Enabled {
+			next := e.schedule.Next(s.now())
+			job.NextRun = &next
+		}
+		for _, run := range runs {
+			job.Runs = append(job.Runs, runInfo(run))
+		}
+		info.Jobs = append(info.Jobs, job)
+	}
+
+	return info, nil
+}
+
+func runInfo(run models.JobRun) global.JobRunInfo {
+	return global.JobRunInfo{
+		ID:         run.ID,
+		Job:        run.Job,
+		Trigger:    run.Trigger,
+		Holder:     run.Holder,
+		Status:     run.Status,
+		Result:     run.Result,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+}
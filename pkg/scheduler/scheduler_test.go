@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	jobrepo "github.com/jainabhishek5986/employee-records/pkg/repositories/job"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/jainabhishek5986/employee-records/pkg/waitgroup"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db := testutil.OpenDB(t, &models.JobRun{}, &models.JobLease{})
+	// runs are recorded from their own goroutines, a shared cache
+	// in-memory database locks tables between connections
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+// newTestScheduler returns a started scheduler on a fake clock, the loop
+// is driven by calling runDue
+func newTestScheduler(t *testing.T, db *gorm.DB, clock *time.Time, jobs ...Job) *Scheduler {
+	cfg := config.JobsConfig{
+		Enabled:       true,
+		LeaseDuration: time.Minute,
+		Catalog: map[string]config.JobConfig{
+			"count": {Enabled: true, Schedule: "*/5 * * * *", Timeout: time.Second},
+		},
+	}
+	s := New(jobrepo.NewJobRepo(db), cfg)
+	s.now = func() time.Time { return *clock }
+	for _, job := range jobs {
+		assert.NoError(t, s.Register(job))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s.runCtx = ctx
+	for _, e := range s.entries {
+		if e.schedule != nil {
+			e.next = e.schedule.Next(*clock)
+		}
+	}
+	return s
+}
+
+func TestSchedulerRunsScheduledJobsOnTheLeaderOnly(t *testing.T) {
+	db := setupTestDB(t)
+	clock := time.Date(2024, time.January, 15, 10, 3, 0, 0, time.UTC)
+	var runs atomic.Int32
+	count := Job{Name: "count", Run: func(ctx context.Context) (string, error) {
+		runs.Add(1)
+		return "counted", nil
+	}}
+
+	first := newTestScheduler(t, db, &clock, count)
+	second := newTestScheduler(t, db, &clock, count)
+
+	testCases := []struct {
+		name           string
+		advance        time.Duration
+		instances      []*Scheduler
+		expectedRuns   int32
+		expectedLeader *Scheduler
+	}{
+		{
+			name:           "Nothing runs before the activation",
+			advance:        time.Minute,
+			instances:      []*Scheduler{first, second},
+			expectedRuns:   0,
+			expectedLeader: first,
+		},
+		{
+			name:           "Only the leader runs the activation",
+			advance:        time.Minute,
+			instances:      []*Scheduler{first, second},
+			expectedRuns:   1,
+			expectedLeader: first,
+		},
+		{
+			name:           "Another instance takes over an expired lease",
+			advance:        10 * time.Minute,
+			instances:      []*Scheduler{second},
+			expectedRuns:   2,
+			expectedLeader: second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock = clock.Add(tc.advance)
+			for _, s := range tc.instances {
+				s.runDue(context.Background(), clock)
+			}
+			waitgroup.Gwg.Wait()
+
+			assert.Equal(t, tc.expectedRuns, runs.Load())
+			assert.True(t, tc.expectedLeader.leader)
+		})
+	}
+
+	info, err := second.ListJobs(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, info.Jobs, 1)
+	assert.Len(t, info.Jobs[0].Runs, 2)
+	assert.Equal(t, models.JobStatusSucceeded, info.Jobs[0].Runs[0].Status)
+	assert.Equal(t, "counted", info.Jobs[0].Runs[0].Result)
+	assert.Equal(t, second.holder, info.Jobs[0].Runs[0].Holder)
+}
+
+func TestSchedulerTriggerJob(t *testing.T) {
+	db := setupTestDB(t)
+	clock := time.Date(2024, time.January, 15, 10, 3, 0, 0, time.UTC)
+	release := make(chan struct{})
+	blocking := Job{Name: "blocking", Run: func(ctx context.Context) (string, error) {
+		<-release
+		return "", nil
+	}}
+	failing := Job{Name: "failing", Run: func(ctx context.Context) (string, error) {
+		return "", errors.New("table is gone")
+	}}
+	panicking := Job{Name: "panicking", Run: func(ctx context.Context) (string, error) {
+		panic("unexpected")
+	}}
+
+	first := newTestScheduler(t, db, &clock, blocking, failing, panicking)
+	second := newTestScheduler(t, db, &clock, blocking, failing, panicking)
+
+	testCases := []struct {
+		name           string
+		instance       *Scheduler
+		job            string
+		expectedStatus int
+		expectedRun    string
+		expectedError  string
+	}{
+		{
+			name:           "Unknown job",
+			instance:       first,
+			job:            "missing",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "Manual run starts",
+			instance:    first,
+			job:         "blocking",
+			expectedRun: models.JobStatusSucceeded,
+		},
+		{
+			name:           "Running job on the same instance",
+			instance:       first,
+			job:            "blocking",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Running job on another instance",
+			instance:       second,
+			job:            "blocking",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:          "Failed run is recorded",
+			instance:      second,
+			job:           "failing",
+			expectedRun:   models.JobStatusFailed,
+			expectedError: "table is gone",
+		},
+		{
+			name:          "Panicking run is recorded",
+			instance:      second,
+			job:           "panicking",
+			expectedRun:   models.JobStatusFailed,
+			expectedError: "panic: unexpected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run, err := tc.instance.TriggerJob(context.Background(), tc.job)
+			if tc.expectedStatus != 0 {
+				var httpErr *errs.HTTPError
+				assert.True(t, errors.As(err, &httpErr))
+				assert.Equal(t, tc.expectedStatus, httpErr.StatusCode())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, models.JobTriggerManual, run.Trigger)
+			assert.Equal(t, models.JobStatusRunning, run.Status)
+		})
+	}
+
+	close(release)
+	waitgroup.Gwg.Wait()
+
+	info, err := first.ListJobs(context.Background())
+	assert.NoError(t, err)
+	for _, tc := range testCases {
+		if tc.expectedRun == "" {
+			continue
+		}
+		for _, job := range info.Jobs {
+			if job.Name != tc.job {
+				continue
+			}
+			assert.Len(t, job.Runs, 1, tc.name)
+			assert.Equal(t, tc.expectedRun, job.Runs[0].Status, tc.name)
+			assert.Equal(t, tc.expectedError, job.Runs[0].Error, tc.name)
+			assert.NotNil(t, job.Runs[0].FinishedAt, tc.name)
+		}
+	}
+}
+
+func TestSchedulerClosesAbandonedRuns(t *testing.T) {
+	db := setupTestDB(t)
+	clock := time.Date(2024, time.January, 15, 10, 3, 0, 0, time.UTC)
+	count := Job{Name: "count", Run: func(ctx context.Context) (string, error) {
+		return "counted", nil
+	}}
+	s := newTestScheduler(t, db, &clock, count)
+
+	// left running by an instance that died while holding the job lease
+	abandoned := models.JobRun{Job: "count", Trigger: models.JobTriggerSchedule, Holder: "gone",
+		Status: models.JobStatusRunning, StartedAt: clock.Add(-time.Hour)}
+	assert.NoError(t, db.Create(&abandoned).Error)
+	assert.NoError(t, db.Create(&models.JobLease{Name: jobLeasePrefix + "count", Holder: "gone",
+		ExpiresAt: clock.Add(-time.Minute)}).Error)
+
+	_, err := s.TriggerJob(context.Background(), "count")
+	assert.NoError(t, err)
+	waitgroup.Gwg.Wait()
+
+	info, err := s.ListJobs(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, info.Jobs[0].Runs, 2)
+	assert.Equal(t, models.JobStatusSucceeded, info.Jobs[0].Runs[0].Status)
+	assert.Equal(t, abandoned.ID, info.Jobs[0].Runs[1].ID)
+	assert.Equal(t, models.JobStatusFailed, info.Jobs[0].Runs[1].Status)
+	assert.Equal(t, errs.JobRunAbandonedError, info.Jobs[0].Runs[1].Error)
+	assert.NotNil(t, info.Jobs[0].Runs[1].FinishedAt)
+}
@@ -0,0 +1,201 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories/apikey"
+	services "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Keys look like erk_<12 hex prefix>_<48 hex secret>
+const (
+	keyPrefix         = "erk"
+	prefixBytes       = 6
+	secretBytes       = 24
+	keyPartsSeparator = "_"
+)
+
+// lastUsedResolution limits the last-used writes to one per key and period
+const lastUsedResolution = time.Minute
+
+// API Key Service Structure
+type service struct {
+	repo repositories.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewService(db *gorm.DB) services.APIKeyService {
+
+	repo := apikey.NewAPIKeyRepo(db)
+	return &service{repo: repo, now: time.Now}
+}
+
+func (svc *service) CreateAPIKey(ctx context.Context, request global.DecodeAPIKeyPOSTRequest) (global.APIKeyCreated, error) {
+	prefix, err := randomHex(prefixBytes)
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyGenerateError, zap.Error(err))
+		return global.APIKeyCreated{}, errs.InternalErr()
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		zaplogger.Error(ctx, errs.APIKeyGenerateError, zap.Error(err))
+		return global.APIKeyCreated{}, errs.InternalErr()
+	}
+
+	tenantID := strings.ToLower(strings.TrimSpace(request.TenantID))
+	if tenantID == "" {
+		tenantID = tenant.Default()
+	}
+	if !tenant.Known(tenantID) {
+		zaplogger.Warn(ctx, errs.TenantNotFoundError, zap.String("tenant", tenantID))
+		return global.APIKeyCreated{}, errs.RequestNotProcessed(errs.TenantNotFoundError)
+	}
+
+	key := models.APIKey{
+		TenantID:  tenantID,
+		Name:      strings.TrimSpace(request.Name),
+		Prefix:    keyPrefix + keyPartsSeparator + prefix,
+		Hash:      hash(secret),
+		Scopes:    strings.Join(request.Scopes, ","),
+		ExpiresAt: request.ExpiresAt,
+	}
+	err = svc.repo.CreateAPIKey(ctx, &key)
+	if err != nil {
+		return global.APIKeyCreated{}, err
+	}
+	zaplogger.Info(ctx, global.APIKeyCreatedSuccessfully,
+		zap.Int("api_key_id", key.ID),
+		zap.String("tenant", key.TenantID),
+		zap.String("prefix", key.Prefix),
+		zap.Strings("scopes", key.ScopeList()),
+	)
+
+	return global.APIKeyCreated{
+		APIKeyInfo: info(key),
+		Key:        key.Prefix + keyPartsSeparator + secret,
+	}, nil
+}
+
+func (svc *service) ListAPIKeys(ctx context.Context) ([]global.APIKeyInfo, error) {
+	keys, err := svc.repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]global.APIKeyInfo, 0, len(keys))
+	for _, key := range keys {
+		infos = append(infos, info(key))
+	}
+	return infos, nil
+}
+
+func (svc *service) RevokeAPIKey(ctx context.Context, id int) error {
+	err := svc.repo.RevokeAPIKey(ctx, id, svc.now())
+	if err != nil {
+		return err
+	}
+	zaplogger.Info(ctx, global.APIKeyRevokedSuccessfully, zap.Int("api_key_id", id))
+
+	return nil
+}
+
+func (svc *service) Authenticate(ctx context.Context, presented string) (auth.Identity, error) {
+	// the reason stays in the logs, callers only learn that the key is invalid
+	invalid := func(reason string) (auth.Identity, error) {
+		zaplogger.Warn(ctx, errs.APIKeyInvalidError, zap.String("reason", reason))
+		return auth.Identity{}, errs.UnAuthorisedErr(errs.APIKeyInvalidError)
+	}
+
+	parts := strings.Split(strings.TrimSpace(presented), keyPartsSeparator)
+	if len(parts) != 3 || parts[0] != keyPrefix {
+		return invalid("malformed")
+	}
+
+	// the tenant of the caller is only known from the key
+	lookupCtx := tenant.AllTenants(ctx)
+	key, err := svc.repo.GetAPIKeyByPrefix(lookupCtx, parts[0]+keyPartsSeparator+parts[1])
+	if err != nil {
+		var notFound *errs.HTTPError
+		if errors.As(err, &notFound) && notFound.Status == http.StatusUnprocessableEntity {
+			return invalid("unknown prefix")
+		}
+		return auth.Identity{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash(parts[2])), []byte(key.Hash)) != 1 {
+		return invalid("secret mismatch")
+	}
+
+	now := svc.now()
+	if !key.Active(now) {
+		return invalid("revoked or expired")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		// a failed write must not fail the request
+		_ = svc.repo.TouchAPIKey(lookupCtx, key.ID, now)
+	}
+
+	return auth.Identity{
+		Subject: auth.MethodAPIKey + ":" + strconv.Itoa(key.ID),
+		Method:  auth.MethodAPIKey,
+		Scopes:  key.ScopeList(),
+		Tenant:  key.TenantID,
+	}, nil
+}
+
+func (svc *service) KeysIssued(ctx context.Context) (bool, error) {
+	count, err := svc.repo.CountAPIKeys(tenant.AllTenants(ctx))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// info converts the stored key to its listed form
+func info(key models.APIKey) global.APIKeyInfo {
+	return global.APIKeyInfo{
+		ID:         key.ID,
+		TenantID:   key.TenantID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// hash returns the hex SHA-256 of the secret. Secrets are random with 192
+// bits of entropy so a slow password hash is not needed.
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
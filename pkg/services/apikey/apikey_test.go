@@ -0,0 +1,120 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/repositories/apikey"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTestService(t *testing.T) (*service, *gorm.DB) {
+	db := testutil.OpenDB(t, &models.APIKey{})
+
+	testutil.InitLogger(t)
+	return &service{repo: apikey.NewAPIKeyRepo(db), now: time.Now}, db
+}
+
+func TestAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name            string
+		request         global.DecodeAPIKeyPOSTRequest
+		revoke          bool
+		tamper          func(key string) string
+		expectedSubject bool
+		expectedScope   string
+	}{
+		{
+			name:            "Valid key",
+			request:         global.DecodeAPIKeyPOSTRequest{Name: "batch", Scopes: []string{auth.ScopeEmployeeRead}},
+			expectedSubject: true,
+			expectedScope:   auth.ScopeEmployeeRead,
+		},
+		{
+			name:    "Wrong secret",
+			request: global.DecodeAPIKeyPOSTRequest{Name: "batch", Scopes: []string{auth.ScopeEmployeeRead}},
+			tamper: func(key string) string {
+				return key[:len(key)-1] + "x"
+			},
+		},
+		{
+			name:    "Malformed key",
+			request: global.DecodeAPIKeyPOSTRequest{Name: "batch", Scopes: []string{auth.ScopeEmployeeRead}},
+			tamper: func(key string) string {
+				return "not-a-key"
+			},
+		},
+		{
+			name:    "Expired key",
+			request: global.DecodeAPIKeyPOSTRequest{Name: "batch", Scopes: []string{auth.ScopeEmployeeRead}, ExpiresAt: &past},
+		},
+		{
+			name:    "Revoked key",
+			request: global.DecodeAPIKeyPOSTRequest{Name: "batch", Scopes: []string{auth.ScopeEmployeeRead}},
+			revoke:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, db := setupTestService(t)
+			ctx := context.Background()
+
+			created, err := svc.CreateAPIKey(ctx, tc.request)
+			assert.NoError(t, err)
+
+			var stored models.APIKey
+			db.First(&stored, created.ID)
+			assert.NotContains(t, created.Key, stored.Hash)
+			assert.NotEmpty(t, stored.Hash)
+
+			if tc.revoke {
+				assert.NoError(t, svc.RevokeAPIKey(ctx, created.ID))
+			}
+			key := created.Key
+			if tc.tamper != nil {
+				key = tc.tamper(key)
+			}
+
+			identity, err := svc.Authenticate(ctx, key)
+			if !tc.expectedSubject {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("apikey:%d", created.ID), identity.Subject)
+			assert.True(t, identity.HasScope(tc.expectedScope))
+			assert.False(t, identity.HasScope(auth.ScopeEmployeeWrite))
+
+			db.First(&stored, created.ID)
+			assert.NotNil(t, stored.LastUsedAt)
+		})
+	}
+}
+
+func TestKeysIssued(t *testing.T) {
+	svc, _ := setupTestService(t)
+	ctx := context.Background()
+
+	issued, err := svc.KeysIssued(ctx)
+	assert.NoError(t, err)
+	assert.False(t, issued)
+
+	created, err := svc.CreateAPIKey(ctx, global.DecodeAPIKeyPOSTRequest{Name: "batch", Scopes: []string{auth.ScopeEmployeeRead}})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.RevokeAPIKey(ctx, created.ID))
+
+	// revoked keys still close the open API
+	issued, err = svc.KeysIssued(ctx)
+	assert.NoError(t, err)
+	assert.True(t, issued)
+}
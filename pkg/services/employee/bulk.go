@@ -0,0 +1,229 @@
+package employee
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/validation"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// maxBulkEmployees bounds the employees of one bulk operation, larger
+// changes are split by the caller
+const maxBulkEmployees = 1000
+
+/*
+BulkUpdateEmployees : applies a list of changes per ID, or the same
+changes to every employee the filter selects, in one transaction. Every
+update is checked against the rules of a PUT first.
+
+Parameters
+----------
+ctx: request context
+request: decoded PATCH request
+*/
+func (envSvc *service) BulkUpdateEmployees(ctx context.Context,
+	request global.DecodeEmployeesPATCHRequest) (global.BulkResult, error) {
+
+	filter := global.EmployeeFilter{}
+	if request.Filter != nil {
+		filter = *request.Filter
+	} else {
+		for _, update := range request.Updates {
+			filter.IDs = append(filter.IDs, update.ID)
+		}
+	}
+	employees, err := envSvc.selectEmployees(ctx, filter)
+	if err != nil {
+		return global.BulkResult{}, err
+	}
+
+	// the salary rules depend on the stored position and salary
+	existing := make(map[int]models.Employee, len(employees))
+	updates := make([]global.DecodeEmployeePUTRequest, 0, len(employees))
+	for _, employee := range employees {
+		existing[employee.ID] = employee
+		if request.Filter != nil {
+			updates = append(updates, request.Changes.ForEmployee(employee.ID))
+		}
+	}
+	for _, update := range request.Updates {
+		if _, ok := existing[update.ID]; ok {
+			updates = append(updates, update.Changes.ForEmployee(update.ID))
+		}
+	}
+
+	result := global.BulkResult{DryRun: request.DryRun, Atomic: request.Atomic}
+	result.Results = missingResults(filter.IDs, existing)
+	valid := make([]global.DecodeEmployeePUTRequest, 0, len(updates))
+	for _, update := range updates {
+		violations := validation.CheckEmployeeUpdate(update, existing[update.ID])
+		if len(violations) > 0 {
+			result.Results = append(result.Results, global.BulkItemResult{
+				ID: update.ID, Status: global.BulkStatusFailed, Code: http.StatusUnprocessableEntity, Error: violations,
+			})
+			continue
+		}
+		valid = append(valid, update)
+		result.Results = append(result.Results, global.BulkItemResult{
+			ID: update.ID, Status: global.BulkStatusMatched,
+		})
+	}
+
+	inRequestOrder(result.Results, filter.IDs)
+
+	result, err = envSvc.runBulk(ctx, result, func() ([]global.BulkItemResult, error) {
+		return envSvc.repo.UpdateEmployees(ctx, valid, existing, request.Atomic)
+	})
+	if err == nil && !result.DryRun {
+		zaplogger.Info(ctx, global.EmployeesBulkUpdatedSuccessfully,
+			zap.Int("succeeded", result.Succeeded), zap.Int("failed", result.Failed),
+		)
+	}
+	return result, err
+}
+
+/*
+BulkDeleteEmployees : deletes the employees the filter selects in one
+transaction
+
+Parameters
+----------
+ctx: request context
+request: decoded DELETE request
+*/
+func (envSvc *service) BulkDeleteEmployees(ctx context.Context,
+	request global.DecodeEmployeesDELETERequest) (global.BulkResult, error) {
+
+	employees, err := envSvc.selectEmployees(ctx, request.Filter)
+	if err != nil {
+		return global.BulkResult{}, err
+	}
+
+	existing := make(map[int]models.Employee, len(employees))
+	ids := make([]int, 0, len(employees))
+	for _, employee := range employees {
+		existing[employee.ID] = employee
+		ids = append(ids, employee.ID)
+	}
+
+	result := global.BulkResult{DryRun: request.DryRun, Atomic: request.Atomic}
+	result.Results = missingResults(request.Filter.IDs, existing)
+	for _, id := range ids {
+		result.Results = append(result.Results, global.BulkItemResult{
+			ID: id, Status: global.BulkStatusMatched,
+		})
+	}
+
+	inRequestOrder(result.Results, request.Filter.IDs)
+
+	result, err = envSvc.runBulk(ctx, result, func() ([]global.BulkItemResult, error) {
+		return envSvc.repo.DeleteEmployees(ctx, ids, request.Atomic)
+	})
+	if err == nil && !result.DryRun {
+		zaplogger.Info(ctx, global.EmployeesBulkDeletedSuccessfully,
+			zap.Int("succeeded", result.Succeeded), zap.Int("failed", result.Failed),
+		)
+	}
+	return result, err
+}
+
+// selectEmployees returns the employees of the filter, refusing filters
+// selecting more than maxBulkEmployees
+func (envSvc *service) selectEmployees(ctx context.Context, filter global.EmployeeFilter) ([]models.Employee, error) {
+	if len(filter.IDs) > maxBulkEmployees {
+		return nil, errs.RequestNotProcessed(errs.BulkTooManyEmployeesError)
+	}
+	employees, err := envSvc.repo.FindEmployees(ctx, filter, maxBulkEmployees+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(employees) > maxBulkEmployees {
+		zaplogger.Error(ctx, errs.BulkTooManyEmployeesError, zap.Int("limit", maxBulkEmployees))
+		return nil, errs.RequestNotProcessed(errs.BulkTooManyEmployeesError)
+	}
+	return employees, nil
+}
+
+// runBulk applies the matched items unless it is a dry run. An atomic
+// operation with a failed item changes nothing and fails as a whole.
+func (envSvc *service) runBulk(ctx context.Context, result global.BulkResult,
+	apply func() ([]global.BulkItemResult, error)) (global.BulkResult, error) {
+
+	result.Matched = countStatus(result.Results, global.BulkStatusMatched)
+	if !result.DryRun && !(result.Atomic && countStatus(result.Results, global.BulkStatusFailed) > 0) {
+		applied, err := apply()
+		if err != nil {
+			return global.BulkResult{}, err
+		}
+		byID := make(map[int]global.BulkItemResult, len(applied))
+		for _, item := range applied {
+			byID[item.ID] = item
+		}
+		for index, item := range result.Results {
+			if outcome, ok := byID[item.ID]; ok && item.Status == global.BulkStatusMatched {
+				result.Results[index] = outcome
+			}
+		}
+	}
+
+	failed := countStatus(result.Results, global.BulkStatusFailed)
+	if result.Atomic && failed > 0 {
+		for index, item := range result.Results {
+			if item.Status != global.BulkStatusFailed {
+				result.Results[index].Status = global.BulkStatusRolledBack
+			}
+		}
+	}
+
+	result.Failed = failed
+	result.Succeeded = countStatus(result.Results, global.BulkStatusUpdated) +
+		countStatus(result.Results, global.BulkStatusDeleted)
+
+	if result.Atomic && failed > 0 {
+		zaplogger.Error(ctx, errs.BulkRolledBackError, zap.Int("failed", failed))
+		return result, errs.RequestNotProcessedWithErrors(errs.BulkRolledBackError, result)
+	}
+	return result, nil
+}
+
+// missingResults fails the IDs asked for that the selection does not hold
+func missingResults(ids []int, selected map[int]models.Employee) []global.BulkItemResult {
+	results := make([]global.BulkItemResult, 0)
+	for _, id := range ids {
+		if _, ok := selected[id]; !ok {
+			results = append(results, global.BulkItemResult{
+				ID: id, Status: global.BulkStatusFailed, Code: http.StatusNotFound, Error: errs.EmployeeNoRecordFoundError,
+			})
+		}
+	}
+	return results
+}
+
+// inRequestOrder sorts the results in the order the IDs were asked for
+func inRequestOrder(results []global.BulkItemResult, ids []int) {
+	position := make(map[int]int, len(ids))
+	for index, id := range ids {
+		if _, ok := position[id]; !ok {
+			position[id] = index
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return position[results[i].ID] < position[results[j].ID]
+	})
+}
+
+func countStatus(results []global.BulkItemResult, status string) int {
+	count := 0
+	for _, item := range results {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
@@ -2,11 +2,21 @@ package employee
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/jainabhishek5986/employee-records/pkg/cache"
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
 	"github.com/jainabhishek5986/employee-records/pkg/repositories"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
 
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
 	"github.com/jainabhishek5986/employee-records/pkg/repositories/employee"
 	services "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/validation"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -18,11 +28,25 @@ type service struct {
 
 func NewService(db *gorm.DB) services.EmployeeService {
 
-	repo := employee.NewEmployeeRepo(db)
-	return &service{db: db, repo: repo}
+	// reads are served from the employee cache of the process, if enabled
+	repo := employee.NewCachedEmployeeRepo(employee.NewEmployeeRepo(db), cache.Named(employee.CacheName))
+	return newTracingService(&service{db: db, repo: repo})
 }
 
 func (envSvc *service) CreateEmployee(ctx context.Context, req global.DecodeEmployeesPOSTRequest) error {
+	// Domain rules are enforced here as well so that every transport
+	// gets the same checks as the HTTP validator
+	violations := make(map[string]string)
+	for index, emp := range req.Employees {
+		for field, message := range validation.CheckEmployee(emp) {
+			violations[fmt.Sprintf("employees[%d].%s", index, field)] = message
+		}
+	}
+	if len(violations) > 0 {
+		zaplogger.Error(ctx, errs.EmployeeValidationError, zap.Any("violations", violations))
+		return errs.RequestNotProcessed(violations)
+	}
+
 	return envSvc.repo.CreateEmployee(ctx, req)
 }
 
@@ -31,7 +55,33 @@ func (envSvc *service) GetEmployeeByID(ctx context.Context, id int) (global.Succ
 }
 
 func (envSvc *service) UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest) error {
-	return envSvc.repo.UpdateEmployeeByID(ctx, request)
+	// The salary rules depend on the stored position and salary, the row is
+	// read, not a cached or replicated copy
+	employees, err := envSvc.repo.FindEmployees(ctx, global.EmployeeFilter{IDs: []int{request.ID}}, 1)
+	if err != nil {
+		return err
+	}
+	if len(employees) == 0 {
+		zaplogger.Error(ctx, errs.EmployeeNoRecordFoundError, zap.Int("employee_id", request.ID))
+		return errs.NotFoundErr(errs.EmployeeNoRecordFoundError)
+	}
+	existing := employees[0]
+
+	violations := validation.CheckEmployeeUpdate(request, existing)
+	if len(violations) > 0 {
+		zaplogger.Error(ctx, errs.EmployeeValidationError, zap.Any("violations", violations),
+			zap.Int("employee_id", request.ID),
+		)
+		return errs.RequestNotProcessed(violations)
+	}
+	if request.SalaryOverrideReason != nil && request.Salary != nil {
+		zaplogger.Info(ctx, "Salary change rule overridden",
+			zap.Int("employee_id", request.ID),
+			zap.String("reason", *request.SalaryOverrideReason),
+		)
+	}
+
+	return envSvc.repo.UpdateEmployeeByID(ctx, request, existing)
 }
 
 func (envSvc *service) DeleteEmployeeByID(ctx context.Context, id int) error {
@@ -39,5 +89,75 @@ func (envSvc *service) DeleteEmployeeByID(ctx context.Context, id int) error {
 }
 
 func (envSvc *service) GetAllEmployee(ctx context.Context, queryParams map[string][]string) (global.SuccessGETInfo, error) {
+	if _, violations := employment.ParseStatusFilter(queryParams["status"]); len(violations) > 0 {
+		zaplogger.Error(ctx, errs.EmploymentStatusQueryError, zap.Any("violations", violations))
+		return global.SuccessGETInfo{}, errs.RequestNotProcessed(violations)
+	}
 	return envSvc.repo.GetAllEmployee(ctx, queryParams)
 }
+
+func (envSvc *service) ChangeEmploymentStatus(ctx context.Context,
+	request global.DecodeEmploymentTransitionRequest) (global.SuccessGETInfo, error) {
+
+	transition, ok := employment.Lookup(request.Action)
+	if !ok {
+		return global.SuccessGETInfo{}, errs.BadRequest(errs.BadRequestErrorMessageDetail)
+	}
+	// the stored row is read, not a cached or replicated copy
+	employees, err := envSvc.repo.FindEmployees(ctx, global.EmployeeFilter{IDs: []int{request.ID}}, 1)
+	if err != nil {
+		return global.SuccessGETInfo{}, err
+	}
+	if len(employees) == 0 {
+		return global.SuccessGETInfo{}, errs.NotFoundErr(errs.EmployeeNoRecordFoundError)
+	}
+	current := employees[0]
+
+	if !transition.Allows(current.EmploymentStatus) {
+		zaplogger.Error(ctx, errs.EmploymentTransitionError, zap.Int("employee_id", request.ID),
+			zap.String("action", request.Action), zap.String("status", current.EmploymentStatus),
+		)
+		return global.SuccessGETInfo{}, errs.ConflictErr(
+			fmt.Sprintf(employment.TransitionMessage, current.EmploymentStatus, request.Action))
+	}
+	next, violations := transition.Apply(current, request)
+	if len(violations) > 0 {
+		zaplogger.Error(ctx, errs.EmploymentValidationError, zap.Any("violations", violations),
+			zap.Int("employee_id", request.ID),
+		)
+		return global.SuccessGETInfo{}, errs.RequestNotProcessed(violations)
+	}
+
+	event := models.EmploymentEvent{
+		EmployeeID: current.ID,
+		Action:     transition.Action,
+		FromStatus: current.EmploymentStatus,
+		ToStatus:   next.EmploymentStatus,
+		Reason:     request.Reason,
+		Actor:      reqctx.UserID(ctx),
+		RequestID:  reqctx.RequestID(ctx),
+	}
+	switch {
+	case request.LastWorkingDay != "":
+		event.EffectiveDate = next.TerminationDate
+	case request.HireDate != "":
+		event.EffectiveDate = next.HireDate
+	}
+	changed, err := envSvc.repo.ChangeEmploymentStatus(ctx, next, current.EmploymentStatus, event)
+	if err != nil {
+		return global.SuccessGETInfo{}, err
+	}
+	if !changed {
+		zaplogger.Error(ctx, errs.EmploymentConflictError, zap.Int("employee_id", request.ID))
+		return global.SuccessGETInfo{}, errs.ConflictErr(errs.EmploymentConflictError)
+	}
+	zaplogger.Info(ctx, "Employment status changed",
+		zap.Int("employee_id", request.ID),
+		zap.String("action", event.Action),
+		zap.String("from", event.FromStatus),
+		zap.String("to", event.ToStatus),
+		zap.String("actor", event.Actor),
+	)
+
+	return global.SuccessGETInfo{Data: next}, nil
+}
@@ -0,0 +1,92 @@
+package employee
+
+import (
+	"context"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	services "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/tracing"
+)
+
+// tracingService wraps every EmployeeService method in a span
+type tracingService struct {
+	next services.EmployeeService
+}
+
+func newTracingService(next services.EmployeeService) services.EmployeeService {
+	return &tracingService{next: next}
+}
+
+func (t *tracingService) CreateEmployee(ctx context.Context, req global.DecodeEmployeesPOSTRequest) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.CreateEmployee")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.CreateEmployee(ctx, req)
+}
+
+func (t *tracingService) GetEmployeeByID(ctx context.Context, id int) (res global.SuccessGETInfo, err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.GetEmployeeByID")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.GetEmployeeByID(ctx, id)
+}
+
+func (t *tracingService) UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.UpdateEmployeeByID")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.UpdateEmployeeByID(ctx, request)
+}
+
+func (t *tracingService) DeleteEmployeeByID(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.DeleteEmployeeByID")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.DeleteEmployeeByID(ctx, id)
+}
+
+func (t *tracingService) GetAllEmployee(ctx context.Context, queryParams map[string][]string) (res global.SuccessGETInfo, err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.GetAllEmployee")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.GetAllEmployee(ctx, queryParams)
+}
+
+func (t *tracingService) BulkUpdateEmployees(ctx context.Context, request global.DecodeEmployeesPATCHRequest) (res global.BulkResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.BulkUpdateEmployees")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.BulkUpdateEmployees(ctx, request)
+}
+
+func (t *tracingService) BulkDeleteEmployees(ctx context.Context, request global.DecodeEmployeesDELETERequest) (res global.BulkResult, err error) {
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.BulkDeleteEmployees")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.BulkDeleteEmployees(ctx, request)
+}
+
+func (t *tracingService) ChangeEmploymentStatus(ctx context.Context,
+	request global.DecodeEmploymentTransitionRequest) (res global.SuccessGETInfo, err error) {
+
+	ctx, span := tracing.StartSpan(ctx, "EmployeeService.ChangeEmploymentStatus")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	return t.next.ChangeEmploymentStatus(ctx, request)
+}
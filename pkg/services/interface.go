@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
 )
 
@@ -15,4 +16,41 @@ type EmployeeService interface {
 	UpdateEmployeeByID(ctx context.Context, request global.DecodeEmployeePUTRequest) error
 	DeleteEmployeeByID(ctx context.Context, id int) error
 	GetAllEmployee(ctx context.Context, queryParams map[string][]string) (global.SuccessGETInfo, error)
+	BulkUpdateEmployees(ctx context.Context, request global.DecodeEmployeesPATCHRequest) (global.BulkResult, error)
+	BulkDeleteEmployees(ctx context.Context, request global.DecodeEmployeesDELETERequest) (global.BulkResult, error)
+	// ChangeEmploymentStatus applies the transition of the request and
+	// returns the employee in its new status
+	ChangeEmploymentStatus(ctx context.Context, request global.DecodeEmploymentTransitionRequest) (global.SuccessGETInfo, error)
+}
+
+/*
+APIKeyService : Interface for API Key Service
+*/
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, request global.DecodeAPIKeyPOSTRequest) (global.APIKeyCreated, error)
+	ListAPIKeys(ctx context.Context) ([]global.APIKeyInfo, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	// Authenticate returns the identity of a presented key
+	Authenticate(ctx context.Context, key string) (auth.Identity, error)
+	// KeysIssued reports whether a key was stored for any tenant
+	KeysIssued(ctx context.Context) (bool, error)
+}
+
+/*
+JobService : Interface for the background job scheduler
+*/
+type JobService interface {
+	ListJobs(ctx context.Context) (global.JobsInfo, error)
+	TriggerJob(ctx context.Context, name string) (global.JobRunInfo, error)
+}
+
+/*
+AsyncJobService : Interface for the asynchronous job API, jobs are only
+visible to the caller who submitted them
+*/
+type AsyncJobService interface {
+	SubmitJob(ctx context.Context, kind string, payload interface{}) (global.AsyncJobInfo, error)
+	GetJob(ctx context.Context, id string) (global.AsyncJobInfo, error)
+	CancelJob(ctx context.Context, id string) (global.AsyncJobInfo, error)
+	GetJobOutput(ctx context.Context, id string) (global.FileResponse, error)
 }
@@ -0,0 +1,162 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Column holds the owning tenant in every tenant table
+const Column = "tenant_id"
+
+// field is the model field mapped to Column, models with it are tenant
+// tables
+const field = "TenantID"
+
+// ErrMissingTenant fails statements on a tenant table whose context has
+// neither a tenant nor AllTenants
+var ErrMissingTenant = errors.New("tenant: no tenant in the context of a tenant table statement")
+
+/*
+RegisterGORM : installs callbacks scoping every query, update and delete of
+a tenant table to the tenant of the statement context, see
+gorm.DB.WithContext, and setting the tenant of the rows it creates.
+Statements without a tenant fail, so that a repository can not forget the
+scope. Raw SQL is not scoped.
+
+Parameters
+----------
+db: database connection
+models: migrated models, those with a TenantID field are tenant tables
+*/
+func RegisterGORM(db *gorm.DB, models ...interface{}) error {
+	tables, err := tenantTables(db, models)
+	if err != nil {
+		return err
+	}
+
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("tenant:create", assign(tables)),
+		callback.Query().Before("gorm:query").Register("tenant:query", scope(tables)),
+		callback.Update().Before("gorm:update").Register("tenant:update", scope(tables)),
+		callback.Delete().Before("gorm:delete").Register("tenant:delete", scope(tables)),
+		callback.Row().Before("gorm:row").Register("tenant:row", scope(tables)),
+	)
+}
+
+/*
+Backfill : assigns the rows without a tenant to the tenant, e.g. the rows
+written before tenancy existed. It runs once after the migrations.
+
+Parameters
+----------
+ctx: context of the migration
+db: database connection
+id: tenant owning the rows, usually the default tenant
+models: migrated models, those with a TenantID field are tenant tables
+*/
+func Backfill(ctx context.Context, db *gorm.DB, id string, models ...interface{}) error {
+	tables, err := tenantTables(db, models)
+	if err != nil {
+		return err
+	}
+
+	for table := range tables {
+		err := db.WithContext(AllTenants(ctx)).
+			Table(table).
+			Where(Column+" IS NULL OR "+Column+" = ''").
+			Update(Column, id).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantTables returns the tables of the models with a TenantID field
+func tenantTables(db *gorm.DB, models []interface{}) (map[string]bool, error) {
+	tables := make(map[string]bool)
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		if stmt.Schema.LookUpField(field) != nil {
+			tables[stmt.Schema.Table] = true
+		}
+	}
+	return tables, nil
+}
+
+// scope restricts the statement to the rows of the tenant of its context.
+// Tables are matched by name since Table() statements like Count have no
+// schema.
+func scope(tables map[string]bool) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if !tables[stmt.Table] || IsAllTenants(stmt.Context) {
+			return
+		}
+		id, ok := FromContext(stmt.Context)
+		if !ok {
+			_ = db.AddError(ErrMissingTenant)
+			return
+		}
+
+		scoped := clause.Eq{Column: clause.Column{Table: stmt.Table, Name: Column}, Value: id}
+		where, ok := stmt.Clauses["WHERE"]
+		conditions, isWhere := where.Expression.(clause.Where)
+		if !ok || !isWhere || len(conditions.Exprs) == 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{scoped}})
+			return
+		}
+		// the conditions are grouped so that an Or can not escape the scope
+		where.Expression = clause.Where{Exprs: []clause.Expression{clause.And(conditions.Exprs...), scoped}}
+		stmt.Clauses["WHERE"] = where
+	}
+}
+
+// assign sets the tenant of the created rows to the tenant of the context.
+// AllTenants statements keep the tenant the rows were given.
+func assign(tables map[string]bool) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if !tables[stmt.Table] {
+			return
+		}
+		id, ok := FromContext(stmt.Context)
+		all := IsAllTenants(stmt.Context)
+		if stmt.Schema == nil || stmt.Schema.LookUpField(field) == nil || (!ok && !all) {
+			_ = db.AddError(ErrMissingTenant)
+			return
+		}
+
+		tenantField := stmt.Schema.LookUpField(field)
+		set := func(row reflect.Value) {
+			if _, zero := tenantField.ValueOf(stmt.Context, row); all && !zero {
+				return
+			}
+			if !ok {
+				_ = db.AddError(ErrMissingTenant)
+				return
+			}
+			_ = db.AddError(tenantField.Set(stmt.Context, row, id))
+		}
+
+		switch stmt.ReflectValue.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < stmt.ReflectValue.Len(); i++ {
+				set(reflect.Indirect(stmt.ReflectValue.Index(i)))
+			}
+		case reflect.Struct:
+			set(stmt.ReflectValue)
+		default:
+			// maps and raw values can not carry the tenant
+			_ = db.AddError(ErrMissingTenant)
+		}
+	}
+}
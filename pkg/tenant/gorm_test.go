@@ -0,0 +1,123 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// note is a tenant table, setting is not
+type note struct {
+	ID       int
+	TenantID string
+	Text     string
+}
+
+type setting struct {
+	ID   int
+	Name string
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db := testutil.OpenDB(t, &note{}, &setting{})
+	// written before tenancy existed
+	assert.NoError(t, db.Create(&note{Text: "legacy"}).Error)
+	assert.NoError(t, Backfill(context.Background(), db, "default", &note{}, &setting{}))
+	assert.NoError(t, RegisterGORM(db, &note{}, &setting{}))
+	return db
+}
+
+func TestRegisterGORM(t *testing.T) {
+	db := setupTestDB(t)
+	acme := WithTenant(context.Background(), "acme")
+	globex := WithTenant(context.Background(), "globex")
+	all := AllTenants(context.Background())
+
+	testCases := []struct {
+		name          string
+		run           func(db *gorm.DB) error
+		expectedError error
+		// expectedNotes are the texts per tenant afterwards
+		expectedNotes map[string][]string
+	}{
+		{
+			name: "Rows without tenant belong to the backfilled tenant",
+			run: func(db *gorm.DB) error {
+				return nil
+			},
+			expectedNotes: map[string][]string{"default": {"legacy"}},
+		},
+		{
+			name: "Created rows get the tenant of the context",
+			run: func(db *gorm.DB) error {
+				return db.WithContext(acme).Create(&[]note{{Text: "a1"}, {TenantID: "globex", Text: "a2"}}).Error
+			},
+			expectedNotes: map[string][]string{"acme": {"a1", "a2"}, "default": {"legacy"}},
+		},
+		{
+			name: "Every tenant keeps the tenant given",
+			run: func(db *gorm.DB) error {
+				return db.WithContext(all).Create(&note{TenantID: "globex", Text: "g1"}).Error
+			},
+			expectedNotes: map[string][]string{"acme": {"a1", "a2"}, "default": {"legacy"}, "globex": {"g1"}},
+		},
+		{
+			name: "Every tenant can not create rows without tenant",
+			run: func(db *gorm.DB) error {
+				return db.WithContext(all).Create(&note{Text: "lost"}).Error
+			},
+			expectedError: ErrMissingTenant,
+			expectedNotes: map[string][]string{"acme": {"a1", "a2"}, "default": {"legacy"}, "globex": {"g1"}},
+		},
+		{
+			name: "Updates only change the rows of the tenant",
+			run: func(db *gorm.DB) error {
+				return db.WithContext(globex).Model(&note{}).Where("text <> ?", "").Update("text", "g2").Error
+			},
+			expectedNotes: map[string][]string{"acme": {"a1", "a2"}, "default": {"legacy"}, "globex": {"g2"}},
+		},
+		{
+			name: "Deletes only remove the rows of the tenant",
+			run: func(db *gorm.DB) error {
+				return db.WithContext(acme).Where("text = ?", "g2").Or("text = ?", "a1").Delete(&note{}).Error
+			},
+			expectedNotes: map[string][]string{"acme": {"a2"}, "default": {"legacy"}, "globex": {"g2"}},
+		},
+		{
+			name: "Statements without tenant fail",
+			run: func(db *gorm.DB) error {
+				var count int64
+				return db.WithContext(context.Background()).Table("notes").Count(&count).Error
+			},
+			expectedError: ErrMissingTenant,
+			expectedNotes: map[string][]string{"acme": {"a2"}, "default": {"legacy"}, "globex": {"g2"}},
+		},
+		{
+			name: "Other tables are not scoped",
+			run: func(db *gorm.DB) error {
+				return db.WithContext(context.Background()).Create(&setting{Name: "theme"}).Error
+			},
+			expectedNotes: map[string][]string{"acme": {"a2"}, "default": {"legacy"}, "globex": {"g2"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, tc.run(db), tc.expectedError)
+
+			notes := make(map[string][]string)
+			for _, id := range []string{"acme", "default", "globex"} {
+				var texts []string
+				err := db.WithContext(WithTenant(context.Background(), id)).Model(&note{}).Order("id").Pluck("text", &texts).Error
+				assert.NoError(t, err)
+				if len(texts) > 0 {
+					notes[id] = texts
+				}
+			}
+			assert.Equal(t, tc.expectedNotes, notes)
+		})
+	}
+}
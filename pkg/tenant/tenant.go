@@ -0,0 +1,128 @@
+package tenant
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+)
+
+var settings atomic.Pointer[config.TenancyConfig]
+
+func init() {
+	Configure(config.TenancyConfig{DefaultTenant: "default"})
+}
+
+type allTenantsKey struct{}
+
+// WithTenant stores the tenant the context acts for, the queries of tenant
+// tables run with it only see and change the rows of that tenant
+func WithTenant(ctx context.Context, id string) context.Context {
+	return reqctx.WithTenantID(ctx, id)
+}
+
+// FromContext returns the tenant the context acts for, if any
+func FromContext(ctx context.Context) (string, bool) {
+	id := reqctx.TenantID(ctx)
+	return id, id != ""
+}
+
+// AllTenants marks the context as acting for the whole instance, e.g. the
+// admin routes, API key lookups and purge jobs. Its queries are not scoped
+// and rows it creates keep the tenant they were given.
+func AllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// IsAllTenants reports whether the context acts for the whole instance
+func IsAllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+// Configure atomically replaces the tenancy settings, tenant IDs are case
+// insensitive
+func Configure(cfg config.TenancyConfig) {
+	tenants := make(map[string]config.TenantConfig, len(cfg.Tenants))
+	for id, overrides := range cfg.Tenants {
+		// viper lower cases map keys
+		tenants[strings.ToLower(id)] = overrides
+	}
+	cfg.DefaultTenant = strings.ToLower(cfg.DefaultTenant)
+	cfg.BaseDomain = strings.ToLower(strings.Trim(cfg.BaseDomain, "."))
+	cfg.Tenants = tenants
+	settings.Store(&cfg)
+}
+
+// Enabled reports whether the tenant of each request is resolved
+func Enabled() bool {
+	return settings.Load().Enabled
+}
+
+// Default returns the tenant every request acts for while tenancy is
+// disabled
+func Default() string {
+	return settings.Load().DefaultTenant
+}
+
+// Known reports whether the instance serves the tenant
+func Known(id string) bool {
+	current := settings.Load()
+	id = strings.ToLower(id)
+	if id == current.DefaultTenant {
+		return true
+	}
+	_, ok := current.Tenants[id]
+	return ok
+}
+
+// Overrides returns the settings overridden for the tenant of ctx
+func Overrides(ctx context.Context) (config.TenantConfig, bool) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return config.TenantConfig{}, false
+	}
+	overrides, ok := settings.Load().Tenants[id]
+	return overrides, ok
+}
+
+/*
+FromHost : returns the tenant named by the subdomain of host below the
+configured base domain, e.g. acme for acme.example.com
+
+Parameters
+----------
+host: Host header of the request, with or without port
+*/
+func FromHost(host string) (string, bool) {
+	baseDomain := settings.Load().BaseDomain
+	if baseDomain == "" {
+		return "", false
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+
+	subdomain, ok := strings.CutSuffix(strings.ToLower(host), "."+baseDomain)
+	// only the label right below the base domain names a tenant
+	if !ok || subdomain == "" || strings.Contains(subdomain, ".") {
+		return "", false
+	}
+	return subdomain, true
+}
+
+// ForClientSubject returns the tenant of a verified client certificate
+// subject, see config.TenantConfig.ClientSubjects
+func ForClientSubject(subject string) (string, bool) {
+	for id, overrides := range settings.Load().Tenants {
+		for _, candidate := range overrides.ClientSubjects {
+			if candidate == subject {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+// Package testutil holds the fixtures shared by the tests of the packages
+package testutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+/*
+OpenDB : opens an in-memory SQLite database private to the test, so that
+seeded rows do not leak between tests, and migrates the models. The
+database is closed when the test ends.
+
+Parameters
+----------
+t: test using the database
+models: models to migrate
+*/
+func OpenDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm db, %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if len(models) > 0 {
+		if err := db.AutoMigrate(models...); err != nil {
+			t.Fatalf("failed to migrate schema, %v", err)
+		}
+	}
+	return db
+}
+
+// InitLogger writes the logs of the test to its temporary directory
+func InitLogger(t *testing.T) {
+	t.Helper()
+	err := zaplogger.Configure(zaplogger.Options{
+		EnableFile:     true,
+		FileJSONFormat: true,
+		FileLevel:      "info",
+		FileLocation:   filepath.Join(t.TempDir(), "unit-tests.log"),
+	})
+	if err != nil {
+		t.Fatalf("failed to init logger, %v", err)
+	}
+	// the logger outlives the test, it leaves the file before the
+	// directory is removed
+	t.Cleanup(func() {
+		_ = zaplogger.Sync()
+		_ = zaplogger.InitLogger()
+	})
+}
@@ -0,0 +1,249 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// Client certificate policies
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// defaultReloadInterval is how often the certificate files are checked
+const defaultReloadInterval = 30 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// material is a consistent snapshot of the files in use
+type material struct {
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	// stamps of the files the snapshot was loaded from
+	stamps map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+/*
+Reloader : serves the certificate and client CA bundle of the config and
+swaps them when the files change on disk, so that rotated certificates are
+picked up without a restart. Failed reloads keep the previous files in use.
+*/
+type Reloader struct {
+	conf    config.TLSConfig
+	current atomic.Pointer[material]
+}
+
+/*
+NewReloader : loads the certificate files of the config
+
+Parameters
+----------
+conf: TLS config
+*/
+func NewReloader(conf config.TLSConfig) (*Reloader, error) {
+	if conf.CertFile == "" || conf.KeyFile == "" {
+		return nil, errors.New("TLS.CertFile and TLS.KeyFile are required")
+	}
+	reloader := &Reloader{conf: conf}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Reload reads the certificate files again
+func (r *Reloader) Reload() error {
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS key pair: %w", err)
+	}
+	if certificate.Leaf == nil {
+		certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("parse TLS certificate: %w", err)
+		}
+	}
+
+	var clientCAs *x509.CertPool
+	if r.conf.ClientCAFile != "" {
+		bundle, err := os.ReadFile(r.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read TLS client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return fmt.Errorf("no certificate found in %s", r.conf.ClientCAFile)
+		}
+	}
+
+	r.current.Store(&material{certificate: &certificate, clientCAs: clientCAs, stamps: stamps})
+	return nil
+}
+
+// Certificate returns the server certificate in use
+func (r *Reloader) Certificate() *tls.Certificate {
+	return r.current.Load().certificate
+}
+
+// Changed reports whether a file differs from the ones in use
+func (r *Reloader) Changed() bool {
+	stamps, err := r.stat()
+	if err != nil {
+		// missing files during a rotation, keep the current ones
+		return false
+	}
+	for file, stamp := range stamps {
+		if r.current.Load().stamps[file] != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Watch : reloads the files when they change until ctx is done
+
+Parameters
+----------
+ctx: Global context
+*/
+func (r *Reloader) Watch(ctx context.Context) {
+	interval := r.conf.ReloadInterval
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.Changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				zaplogger.Error(ctx, "Unable to reload TLS certificates, keeping the current ones", zap.Error(err))
+				continue
+			}
+			zaplogger.Info(ctx, "Reloaded TLS certificates",
+				zap.Time("not_after", r.Certificate().Leaf.NotAfter))
+		}
+	}
+}
+
+func (r *Reloader) stat() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, 3)
+	for _, file := range []string{r.conf.CertFile, r.conf.KeyFile, r.conf.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+/*
+ServerConfig : builds the tls.Config of the server. The certificate and
+client CAs are looked up per handshake so that reloads apply to new
+connections.
+*/
+func (r *Reloader) ServerConfig() (*tls.Config, error) {
+	minVersion, ok := tlsVersions[r.conf.MinVersion]
+	if r.conf.MinVersion == "" {
+		minVersion, ok = tls.VersionTLS12, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS.MinVersion %q, use 1.2 or 1.3", r.conf.MinVersion)
+	}
+
+	cipherSuites, err := cipherSuiteIDs(r.conf.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth := tls.NoClientCert
+	switch strings.ToLower(r.conf.ClientAuth) {
+	case "", ClientAuthNone:
+	case ClientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown TLS.ClientAuth %q", r.conf.ClientAuth)
+	}
+	if clientAuth != tls.NoClientCert && r.conf.ClientCAFile == "" {
+		return nil, errors.New("TLS.ClientCAFile is required to verify client certificates")
+	}
+
+	base := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		ClientAuth:   clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		current := r.current.Load()
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.Certificates = []tls.Certificate{*current.certificate}
+		config.ClientCAs = current.clientCAs
+		return config, nil
+	}
+	return base, nil
+}
+
+// cipherSuiteIDs maps the configured names, e.g.
+// TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, to their IDs. Go does not allow
+// configuring the TLS 1.3 suites, they are always enabled.
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure TLS cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
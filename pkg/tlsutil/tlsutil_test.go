@@ -0,0 +1,178 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue creates a certificate signed by parent, or self signed without one
+func issue(t *testing.T, commonName string, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Employee Records"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, certFile string, keyFile string) {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	assert.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	if keyFile == "" {
+		return
+	}
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	assert.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestReloaderMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	conf := config.TLSConfig{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   ClientAuthRequire,
+		MinVersion:   "1.2",
+	}
+
+	ca := issue(t, "Test CA", 1, nil)
+	ca.write(t, conf.ClientCAFile, "")
+	issue(t, "server-1", 2, ca).write(t, conf.CertFile, conf.KeyFile)
+	client := issue(t, "payroll-batch", 3, ca)
+	stranger := issue(t, "stranger", 4, issue(t, "Other CA", 5, nil))
+
+	reloader, err := NewReloader(conf)
+	assert.NoError(t, err)
+	tlsConfig, err := reloader.ServerConfig()
+	assert.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(clientCert *testCert) (string, string, error) {
+		clientTLS := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if clientCert != nil {
+			clientTLS.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+		}
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		response, err := httpClient.Get(server.URL)
+		if err != nil {
+			return "", "", err
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return string(body), response.TLS.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	testCases := []struct {
+		name           string
+		clientCert     *testCert
+		expectedError  bool
+		expectedCaller string
+	}{
+		{name: "Verified client certificate", clientCert: client, expectedCaller: "payroll-batch"},
+		{name: "Missing client certificate", clientCert: nil, expectedError: true},
+		{name: "Client certificate of another CA", clientCert: stranger, expectedError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			caller, serverName, err := get(tc.clientCert)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCaller, caller)
+			assert.Equal(t, "server-1", serverName)
+		})
+	}
+
+	// rotate the server certificate, new connections get it
+	assert.False(t, reloader.Changed())
+	issue(t, "server-2", 6, ca).write(t, conf.CertFile, conf.KeyFile)
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(conf.CertFile, future, future))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloader.conf.ReloadInterval = 10 * time.Millisecond
+	go reloader.Watch(ctx)
+	assert.Eventually(t, func() bool {
+		return reloader.Certificate().Leaf.Subject.CommonName == "server-2"
+	}, time.Second, 10*time.Millisecond)
+
+	_, serverName, err := get(client)
+	assert.NoError(t, err)
+	assert.Equal(t, "server-2", serverName)
+}
+
+func TestServerConfigRejectsInvalidSettings(t *testing.T) {
+	testCases := []struct {
+		name string
+		conf config.TLSConfig
+	}{
+		{name: "Unknown minimum version", conf: config.TLSConfig{MinVersion: "1.0"}},
+		{name: "Unknown cipher suite", conf: config.TLSConfig{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}},
+		{name: "Client auth without CA bundle", conf: config.TLSConfig{ClientAuth: ClientAuthRequire}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := (&Reloader{conf: tc.conf}).ServerConfig()
+			assert.Error(t, err)
+		})
+	}
+}
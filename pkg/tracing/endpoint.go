@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TraceEndpoint returns a go-kit middleware wrapping the named endpoint in
+// a span
+func TraceEndpoint(name string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			ctx, span := Tracer().Start(ctx, "endpoint."+name)
+			defer func() {
+				RecordError(span, err)
+				span.End()
+			}()
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// StartSpan starts a span named name as child of the span in ctx
+func StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	return Tracer().Start(ctx, name)
+}
+
+// RecordError marks the span as failed when err is set
+func RecordError(span Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
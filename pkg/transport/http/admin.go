@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+
+	"gorm.io/gorm"
+
+	adminep "github.com/jainabhishek5986/employee-records/pkg/endpoint/admin"
+	apikeyep "github.com/jainabhishek5986/employee-records/pkg/endpoint/apikey"
+	jobsep "github.com/jainabhishek5986/employee-records/pkg/endpoint/jobs"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	apikeysvc "github.com/jainabhishek5986/employee-records/pkg/services/apikey"
+)
+
+/*
+AdminAuthMiddleware : guards the admin routes with a static bearer token.
+With an empty token the admin API is disabled and every call is forbidden.
+
+Parameters
+----------
+token: admin token from the config
+*/
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			localizedErrorEncoder(c, errs.ForbiddenErr(errs.AdminDisabledError), c.Writer)
+			c.Abort()
+			return
+		}
+
+		supplied := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(supplied), []byte(token)) != 1 {
+			zaplogger.Warn(c, errs.AdminTokenError)
+			localizedErrorEncoder(c, errs.UnAuthorisedErr(errs.AdminTokenError), c.Writer)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func RegisterAdminRoutes(adminRoutesGroup *gin.RouterGroup, db *gorm.DB, jobs service.JobService) {
+
+	var (
+		endpoint       = adminep.NewEndPoint()
+		apiKeyEndpoint = apikeyep.NewEndPoint(apikeysvc.NewService(db))
+		jobsEndpoint   = jobsep.NewEndPoint(jobs)
+	)
+
+	adminRoutesGroup.GET("/log-level", NewHTTPHandler(
+		endpoint.GetLogLevel, DecodeAllRequest,
+		EncodeJSONResponse))
+
+	adminRoutesGroup.PUT("/log-level", NewHTTPHandler(
+		endpoint.SetLogLevel, DecodeLogLevelPUTRequest,
+		EncodeJSONResponse))
+
+	// API Key Endpoints
+	adminRoutesGroup.POST("/api-keys", NewHTTPHandler(
+		apiKeyEndpoint.CreateAPIKey, DecodeAPIKeyPOSTRequest,
+		EncodeJSONResponse))
+
+	adminRoutesGroup.GET("/api-keys", NewHTTPHandler(
+		apiKeyEndpoint.ListAPIKeys, DecodeAllRequest,
+		EncodeJSONResponse))
+
+	adminRoutesGroup.DELETE("/api-keys/:id", NewHTTPHandler(
+		apiKeyEndpoint.RevokeAPIKey, DecodeByIDRequest,
+		EncodeJSONResponse))
+
+	// Background job Endpoints
+	adminRoutesGroup.GET("/jobs", NewHTTPHandler(
+		jobsEndpoint.ListJobs, DecodeAllRequest,
+		EncodeJSONResponse))
+
+	adminRoutesGroup.POST("/jobs/:name/run", NewHTTPHandler(
+		jobsEndpoint.TriggerJob, DecodeJobNameRequest,
+		EncodeJSONResponse))
+
+	zaplogger.Info(context.Background(), "admin routes injected")
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminLogLevel(t *testing.T) {
+	testCases := []struct {
+		name           string
+		token          string
+		authorization  string
+		body           string
+		expectedStatus int
+		expectedLevels map[string]string
+	}{
+		{
+			name:           "Admin API disabled without a token",
+			token:          "",
+			authorization:  "Bearer ",
+			body:           `{"level": "debug"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Wrong token",
+			token:          "secret",
+			authorization:  "Bearer guess",
+			body:           `{"level": "debug"}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Unknown level",
+			token:          "secret",
+			authorization:  "Bearer secret",
+			body:           `{"level": "loud"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Changes the console level only",
+			token:          "secret",
+			authorization:  "Bearer secret",
+			body:           `{"level": "error", "output": "console"}`,
+			expectedStatus: http.StatusOK,
+			expectedLevels: map[string]string{
+				zaplogger.OutputConsole: "error",
+				zaplogger.OutputFile:    "info",
+			},
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, zaplogger.SetLevel("", "info"))
+
+			router := gin.New()
+			adminRoutesGroup := router.Group("/admin")
+			adminRoutesGroup.Use(AdminAuthMiddleware(tc.token))
+			RegisterAdminRoutes(adminRoutesGroup, nil, nil)
+
+			request := httptest.NewRequest(http.MethodPut, "/admin/log-level",
+				strings.NewReader(tc.body))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", tc.authorization)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedLevels != nil {
+				assert.Equal(t, tc.expectedLevels, zaplogger.Levels())
+			}
+		})
+	}
+	assert.NoError(t, zaplogger.SetLevel("", "info"))
+}
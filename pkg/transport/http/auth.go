@@ -0,0 +1,150 @@
+package http
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+)
+
+// API key credentials are accepted in either header
+const (
+	APIKeyHeader = "X-API-Key"
+	// APIKeyScheme is the Authorization scheme, e.g. "ApiKey erk_..."
+	APIKeyScheme = "ApiKey"
+)
+
+/*
+AuthMiddleware : authenticates API keys sent as "Authorization: ApiKey
+<key>" or "X-API-Key: <key>" and stores the identity of the caller in the
+request context. Requests without credentials or client certificate are
+rejected when required is set. Otherwise they continue anonymously, in the
+open API mode while no API key was ever issued, and lacking every scope
+once one was.
+
+Parameters
+----------
+apiKeys: API key service
+required: reject requests without credentials
+*/
+func AuthMiddleware(apiKeys service.APIKeyService, required bool) gin.HandlerFunc {
+	// keys are never removed before they are revoked, so the API does not
+	// reopen while the process runs
+	var keysIssued atomic.Bool
+
+	return func(c *gin.Context) {
+		key := presentedAPIKey(c)
+		if key == "" {
+			// callers may already be known from their client certificate
+			if _, ok := auth.FromContext(c); ok {
+				c.Next()
+				return
+			}
+			if required {
+				localizedErrorEncoder(c, errs.UnAuthorisedErr(errs.AuthenticationRequired), c.Writer)
+				c.Abort()
+				return
+			}
+
+			if !keysIssued.Load() {
+				issued, err := apiKeys.KeysIssued(c)
+				if err != nil {
+					localizedErrorEncoder(c, err, c.Writer)
+					c.Abort()
+					return
+				}
+				if !issued {
+					c.Request = c.Request.WithContext(auth.WithOpenAccess(c.Request.Context()))
+					c.Next()
+					return
+				}
+				keysIssued.Store(true)
+			}
+			c.Next()
+			return
+		}
+
+		identity, err := apiKeys.Authenticate(c, key)
+		if err != nil {
+			localizedErrorEncoder(c, err, c.Writer)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), identity))
+		c.Next()
+	}
+}
+
+/*
+ClientCertMiddleware : stores the subject of a verified client certificate
+as the identity of the request, it acts for the tenant listing the subject
+in its ClientSubjects. API keys presented on the same request take
+precedence since AuthMiddleware runs later.
+
+Parameters
+----------
+scopes: scopes granted to certificate callers
+*/
+func ClientCertMiddleware(scopes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := c.Request.TLS
+		// only chains verified against the client CA bundle are trusted
+		if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+			c.Next()
+			return
+		}
+
+		subject := state.VerifiedChains[0][0].Subject.String()
+		identity := auth.Identity{
+			Subject: auth.MethodMTLS + ":" + subject,
+			Method:  auth.MethodMTLS,
+			Scopes:  scopes,
+		}
+		identity.Tenant, _ = tenant.ForClientSubject(subject)
+		c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), identity))
+		c.Next()
+	}
+}
+
+/*
+RequireScope : rejects authenticated callers lacking scope with 403 and
+anonymous callers with 401, unless the API is open.
+
+Parameters
+----------
+scope: scope required by the route
+*/
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := auth.FromContext(c)
+		if !ok && !auth.OpenAccess(c) {
+			localizedErrorEncoder(c, errs.UnAuthorisedErr(errs.AuthenticationRequired), c.Writer)
+			c.Abort()
+			return
+		}
+		if ok && !identity.HasScope(scope) {
+			localizedErrorEncoder(c, errs.ForbiddenErr(errs.InsufficientScopeError), c.Writer)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// presentedAPIKey returns the API key of the request, if any
+func presentedAPIKey(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader(APIKeyHeader)); key != "" {
+		return key
+	}
+
+	scheme, credentials, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, APIKeyScheme) {
+		return strings.TrimSpace(credentials)
+	}
+	return ""
+}
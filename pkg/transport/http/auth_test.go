@@ -0,0 +1,239 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPIKeys accepts the key "good" with the read scope only, none is
+// issued while noKeys is set
+type fakeAPIKeys struct {
+	noKeys bool
+}
+
+func (fakeAPIKeys) CreateAPIKey(context.Context, global.DecodeAPIKeyPOSTRequest) (global.APIKeyCreated, error) {
+	return global.APIKeyCreated{}, nil
+}
+
+func (fakeAPIKeys) ListAPIKeys(context.Context) ([]global.APIKeyInfo, error) {
+	return nil, nil
+}
+
+func (fakeAPIKeys) RevokeAPIKey(context.Context, int) error {
+	return nil
+}
+
+func (fakeAPIKeys) Authenticate(_ context.Context, key string) (auth.Identity, error) {
+	if key != "good" {
+		return auth.Identity{}, errs.UnAuthorisedErr(errs.APIKeyInvalidError)
+	}
+	return auth.Identity{Subject: "apikey:1", Method: auth.MethodAPIKey, Scopes: []string{auth.ScopeEmployeeRead}}, nil
+}
+
+func (keys fakeAPIKeys) KeysIssued(context.Context) (bool, error) {
+	return !keys.noKeys, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		required       bool
+		noKeys         bool
+		method         string
+		headers        map[string]string
+		expectedStatus int
+		expectedUser   string
+	}{
+		{
+			name:           "Anonymous request of the open API",
+			noKeys:         true,
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Anonymous request once keys are issued",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Anonymous request when required",
+			required:       true,
+			method:         http.MethodGet,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Authorization ApiKey header",
+			method:         http.MethodGet,
+			headers:        map[string]string{"Authorization": "ApiKey good"},
+			expectedStatus: http.StatusOK,
+			expectedUser:   "apikey:1",
+		},
+		{
+			name:           "X-API-Key header",
+			required:       true,
+			method:         http.MethodGet,
+			headers:        map[string]string{APIKeyHeader: "good"},
+			expectedStatus: http.StatusOK,
+			expectedUser:   "apikey:1",
+		},
+		{
+			name:           "Invalid key",
+			method:         http.MethodGet,
+			headers:        map[string]string{APIKeyHeader: "bad"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Missing scope",
+			method:         http.MethodPost,
+			headers:        map[string]string{APIKeyHeader: "good"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(AuthMiddleware(fakeAPIKeys{noKeys: tc.noKeys}, tc.required))
+			handler := func(c *gin.Context) {
+				c.String(http.StatusOK, reqctx.UserID(c))
+			}
+			router.GET("/employee", RequireScope(auth.ScopeEmployeeRead), handler)
+			router.POST("/employee", RequireScope(auth.ScopeEmployeeWrite), handler)
+
+			request := httptest.NewRequest(tc.method, "/employee", nil)
+			for key, value := range tc.headers {
+				request.Header.Set(key, value)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, tc.expectedUser, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	tenant.Configure(config.TenancyConfig{
+		DefaultTenant: "default",
+		Tenants:       map[string]config.TenantConfig{"acme": {ClientSubjects: []string{"CN=payroll,O=HR"}}},
+	})
+	t.Cleanup(func() { tenant.Configure(config.TenancyConfig{DefaultTenant: "default"}) })
+
+	testCases := []struct {
+		name           string
+		state          *tls.ConnectionState
+		expectedUser   string
+		expectedTenant string
+	}{
+		{
+			name:         "Plain HTTP",
+			state:        nil,
+			expectedUser: "",
+		},
+		{
+			name:         "Unverified certificate",
+			state:        &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "payroll"}}}},
+			expectedUser: "",
+		},
+		{
+			name: "Verified certificate",
+			state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+				{Subject: pkix.Name{CommonName: "payroll", Organization: []string{"HR"}}},
+			}}},
+			expectedUser:   "mtls:CN=payroll,O=HR",
+			expectedTenant: "acme",
+		},
+		{
+			name: "Verified certificate of no tenant",
+			state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+				{Subject: pkix.Name{CommonName: "payroll", Organization: []string{"Finance"}}},
+			}}},
+			expectedUser: "mtls:CN=payroll,O=Finance",
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(ClientCertMiddleware([]string{auth.ScopeEmployeeRead}), AuthMiddleware(fakeAPIKeys{}, true))
+	router.GET("/employee", RequireScope(auth.ScopeEmployeeRead), func(c *gin.Context) {
+		identity, _ := auth.FromContext(c)
+		c.Header("X-Tenant", identity.Tenant)
+		c.String(http.StatusOK, reqctx.UserID(c))
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/employee", nil)
+			request.TLS = tc.state
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if tc.expectedUser == "" {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				return
+			}
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tc.expectedUser, recorder.Body.String())
+			assert.Equal(t, tc.expectedTenant, recorder.Header().Get("X-Tenant"))
+		})
+	}
+}
+
+func TestJobRouteScopes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "Cancelling a job with the read scope",
+			method:         http.MethodDelete,
+			path:           "/api/v1/jobs/62bf17a673331db41aff57be9356a672",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Submitting an update with the read scope",
+			method:         http.MethodPost,
+			path:           "/api/v1/employee/update",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(AuthMiddleware(fakeAPIKeys{}, true))
+	RegisterAPIRoutes(router.Group("/api/v1"), nil, nil)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(tc.method, tc.path, nil)
+			request.Header.Set(APIKeyHeader, "good")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
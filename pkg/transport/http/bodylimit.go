@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+)
+
+/*
+BodyLimitMiddleware : bounds the request body with http.MaxBytesReader to
+the limit of the route, or the default one. Requests announcing a larger
+Content-Length are rejected before the handler runs, the others fail while
+decoding once the limit is read.
+
+Parameters
+----------
+conf: body limits from the config
+*/
+func BodyLimitMiddleware(conf config.BodyLimitConfig) gin.HandlerFunc {
+	routes := make(map[string]int64, len(conf.Routes))
+	for route, limit := range conf.Routes {
+		// viper lower cases map keys
+		routes[strings.ToLower(route)] = limit
+	}
+
+	return func(c *gin.Context) {
+		limit, ok := routes[strings.ToLower(c.Request.Method+" "+c.FullPath())]
+		if !ok {
+			limit = conf.MaxBytes
+		}
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			err := errs.ErrorReqHandler(&http.MaxBytesError{Limit: limit})
+			localizedErrorEncoder(c, err, c.Writer)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// Prefixes of the CORS origin patterns
+const (
+	corsAnyOrigin   = "*"
+	corsRegexPrefix = "regex:"
+)
+
+// corsAllowHeaders are sent by the clients of every route group
+var corsAllowHeaders = []string{
+	"Origin", "Content-Length", "Content-Type", "Accept-Language", "Authorization",
+	reqctx.RequestIDHeader, "traceparent", "tracestate", APIKeyHeader,
+	ReadYourWritesHeader,
+}
+
+// corsExposeHeaders are readable by browser clients of every route group
+var corsExposeHeaders = []string{
+	reqctx.RequestIDHeader, "traceparent", "tracestate",
+	RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader, RetryAfterHeader,
+}
+
+// corsHandler is a compiled policy
+type corsHandler struct {
+	allowed func(origin string) bool
+	handle  gin.HandlerFunc
+}
+
+// CORS : applies a cross origin policy to a route group. The policy can be
+// replaced at runtime with SetConfig.
+type CORS struct {
+	name    string
+	handler atomic.Pointer[corsHandler]
+}
+
+/*
+NewCORS : returns the CORS middleware of a route group
+
+Parameters
+----------
+name: route group name used in the logs, e.g. api or admin
+policy: CORS policy from the config
+*/
+func NewCORS(name string, policy config.CORSPolicy) (*CORS, error) {
+	c := &CORS{name: name}
+	if err := c.SetConfig(policy); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetConfig atomically replaces the policy, an invalid policy keeps the
+// current one
+func (c *CORS) SetConfig(policy config.CORSPolicy) error {
+	allowed, err := originMatcher(policy.AllowedOrigins)
+	if err != nil {
+		return fmt.Errorf("cors %s: %w", c.name, err)
+	}
+	for _, origin := range policy.AllowedOrigins {
+		// browsers refuse credentials on a wildcard origin
+		if origin == corsAnyOrigin && policy.AllowCredentials {
+			return fmt.Errorf("cors %s: %q can not be combined with credentials", c.name, corsAnyOrigin)
+		}
+	}
+
+	corsConfig := cors.Config{
+		AllowOriginFunc:  allowed,
+		AllowMethods:     policy.AllowedMethods,
+		AllowHeaders:     append(append([]string{}, corsAllowHeaders...), policy.AllowedHeaders...),
+		ExposeHeaders:    append(append([]string{}, corsExposeHeaders...), policy.ExposedHeaders...),
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           policy.MaxAge,
+	}
+	if err := corsConfig.Validate(); err != nil {
+		return fmt.Errorf("cors %s: %w", c.name, err)
+	}
+
+	c.handler.Store(&corsHandler{allowed: allowed, handle: cors.New(corsConfig)})
+	return nil
+}
+
+/*
+Middleware : answers preflight requests and sets the CORS headers of cross
+origin requests. Requests of a disallowed origin are rejected with 403,
+preflights among them are logged with the origin. Same origin requests, e.g.
+of the API docs, are not subject to the policy.
+*/
+func (c *CORS) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" || sameOrigin(ctx.Request, origin) {
+			ctx.Next()
+			return
+		}
+
+		handler := c.handler.Load()
+		if isPreflight(ctx.Request) && !handler.allowed(origin) {
+			zaplogger.Warn(ctx, errs.CORSPreflightRejected,
+				zap.String("policy", c.name),
+				zap.String("origin", origin),
+				zap.String("method", ctx.GetHeader("Access-Control-Request-Method")),
+				zap.String("path", ctx.Request.URL.Path))
+		}
+		handler.handle(ctx)
+	}
+}
+
+// PreflightHandler terminates preflight requests the CORS middleware let
+// through, the route groups register it for OPTIONS on all their paths
+func PreflightHandler(c *gin.Context) {
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// isPreflight reports whether r is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// sameOrigin reports whether origin is the host the request was sent to
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+/*
+originMatcher : compiles the allowed origins to a matcher. Exact origins
+are compared case insensitively, a * in an origin matches one or more
+subdomain labels and entries prefixed with regex: are regular expressions
+matched against the whole lower cased origin.
+
+Parameters
+----------
+origins: allowed origin patterns
+*/
+func originMatcher(origins []string) (func(origin string) bool, error) {
+	exact := make(map[string]bool)
+	patterns := make([]*regexp.Regexp, 0)
+	anyOrigin := false
+
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "":
+			return nil, errors.New("empty allowed origin")
+		case origin == corsAnyOrigin:
+			anyOrigin = true
+		case strings.HasPrefix(origin, corsRegexPrefix):
+			pattern, err := regexp.Compile(`^(?:` + strings.TrimPrefix(origin, corsRegexPrefix) + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("allowed origin %q: %w", origin, err)
+			}
+			patterns = append(patterns, pattern)
+		case strings.Contains(origin, "*"):
+			parts := strings.Split(strings.ToLower(origin), "*")
+			for i := range parts {
+				parts[i] = regexp.QuoteMeta(parts[i])
+			}
+			// a wildcard stays within the host, it never matches a port
+			// or path separator
+			pattern := `^` + strings.Join(parts, `[a-z0-9-]+(?:\.[a-z0-9-]+)*`) + `$`
+			patterns = append(patterns, regexp.MustCompile(pattern))
+		default:
+			exact[strings.ToLower(origin)] = true
+		}
+	}
+
+	return func(origin string) bool {
+		if anyOrigin {
+			return true
+		}
+		origin = strings.ToLower(origin)
+		if exact[origin] {
+			return true
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	policy := config.CORSPolicy{
+		AllowedOrigins: []string{
+			"https://app.example.com",
+			"https://*.example.org",
+			`regex:https://review-[0-9]+\.example\.net`,
+		},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	testCases := []struct {
+		name           string
+		method         string
+		origin         string
+		host           string
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{
+			name:           "Preflight of an exact origin",
+			method:         http.MethodOptions,
+			origin:         "https://APP.example.com",
+			expectedStatus: http.StatusNoContent,
+			expectedOrigin: "https://APP.example.com",
+		},
+		{
+			name:           "Preflight of a wildcard subdomain",
+			method:         http.MethodOptions,
+			origin:         "https://eu.admin.example.org",
+			expectedStatus: http.StatusNoContent,
+			expectedOrigin: "https://eu.admin.example.org",
+		},
+		{
+			name:           "Wildcard does not match the bare domain",
+			method:         http.MethodOptions,
+			origin:         "https://example.org",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Wildcard does not match a port",
+			method:         http.MethodOptions,
+			origin:         "https://x.example.org:8443",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Preflight of a regex origin",
+			method:         http.MethodOptions,
+			origin:         "https://review-42.example.net",
+			expectedStatus: http.StatusNoContent,
+			expectedOrigin: "https://review-42.example.net",
+		},
+		{
+			name:           "Regex is anchored",
+			method:         http.MethodOptions,
+			origin:         "https://review-42.example.net.evil.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Rejected preflight",
+			method:         http.MethodOptions,
+			origin:         "https://evil.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Cross origin request",
+			method:         http.MethodGet,
+			origin:         "https://app.example.com",
+			expectedStatus: http.StatusOK,
+			expectedOrigin: "https://app.example.com",
+		},
+		{
+			name:           "Same origin request",
+			method:         http.MethodGet,
+			origin:         "http://records.internal:9876",
+			host:           "records.internal:9876",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Request without origin",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	middleware, err := NewCORS("api", policy)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	group := router.Group("/api/v1", middleware.Middleware())
+	group.OPTIONS("/*path", PreflightHandler)
+	group.GET("/employee", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/api/v1/employee", nil)
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			if tc.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			if tc.expectedOrigin != "" {
+				assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			}
+			if tc.method == http.MethodOptions && tc.expectedStatus == http.StatusNoContent {
+				assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+				assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), http.CanonicalHeaderKey(APIKeyHeader))
+			}
+		})
+	}
+}
+
+func TestNewCORSInvalidPolicy(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy config.CORSPolicy
+	}{
+		{
+			name:   "Any origin with credentials",
+			policy: config.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		},
+		{
+			name:   "Invalid regex",
+			policy: config.CORSPolicy{AllowedOrigins: []string{"regex:https://(["}},
+		},
+		{
+			name:   "Empty origin",
+			policy: config.CORSPolicy{AllowedOrigins: []string{" "}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCORS("api", tc.policy)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+)
+
+/*
+NewDiagnosticsHandler : builds the router of the diagnostics listener with
+pprof, goroutine dumps, GC stats, the redacted config and build info, all
+behind the admin token
+
+Parameters
+----------
+conf: Config object
+*/
+func NewDiagnosticsHandler(conf *config.Config) http.Handler {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(AdminAuthMiddleware(conf.Admin.Token))
+
+	router.GET("/debug/pprof/*profile", pprofHandler)
+	router.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+
+	router.GET("/debug/goroutines", func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		// debug=2 prints the stack of every goroutine like an unrecovered panic
+		handler := pprof.Handler("goroutine")
+		c.Request.URL.RawQuery = "debug=2"
+		handler.ServeHTTP(c.Writer, c.Request)
+	})
+
+	router.GET("/debug/gc", func(c *gin.Context) {
+		c.JSON(http.StatusOK, global.SuccessGETInfo{Data: gcStats()})
+	})
+
+	router.GET("/debug/config", func(c *gin.Context) {
+		// reloaded keys are part of the config in effect
+		effective := conf
+		if reloaded := config.Current(); reloaded != nil {
+			effective = reloaded
+		}
+		c.JSON(http.StatusOK, global.SuccessGETInfo{Data: config.Redacted(effective)})
+	})
+
+	router.GET("/debug/build", func(c *gin.Context) {
+		c.JSON(http.StatusOK, global.SuccessGETInfo{Data: buildInfo()})
+	})
+
+	return router
+}
+
+// pprofHandler dispatches /debug/pprof/<profile> to net/http/pprof
+func pprofHandler(c *gin.Context) {
+	switch profile := strings.TrimPrefix(c.Param("profile"), "/"); profile {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(profile).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+func gcStats() map[string]interface{} {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return map[string]interface{}{
+		"num_gc":         stats.NumGC,
+		"last_gc":        stats.LastGC,
+		"pause_total":    stats.PauseTotal.String(),
+		"heap_alloc":     memStats.HeapAlloc,
+		"heap_sys":       memStats.HeapSys,
+		"heap_objects":   memStats.HeapObjects,
+		"next_gc":        memStats.NextGC,
+		"num_goroutine":  runtime.NumGoroutine(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"gc_cpu_percent": memStats.GCCPUFraction * 100,
+	}
+}
+
+func buildInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"version":    global.BinaryVersion,
+		"go_version": runtime.Version(),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info["module"] = build.Main.Path
+		settings := make(map[string]string, len(build.Settings))
+		for _, setting := range build.Settings {
+			// vcs.revision, vcs.time, vcs.modified and build flags
+			settings[setting.Key] = setting.Value
+		}
+		info["settings"] = settings
+	}
+	return info
+}
+
+/*
+NewDiagnosticsServer : serves the diagnostics handler on its own listener,
+it is only started when enabled in the config
+
+Parameters
+----------
+conf: Config object
+*/
+func NewDiagnosticsServer(conf *config.Config) *Server {
+	return &Server{
+		name: "diagnostics",
+		srv: &http.Server{
+			Addr:    net.JoinHostPort(conf.Diagnostics.Host, conf.Diagnostics.Port),
+			Handler: NewDiagnosticsHandler(conf),
+		},
+		started: func() {
+			if conf.Admin.Token == "" {
+				zaplogger.Warn(context.Background(), "Diagnostics server enabled without Admin.Token, every request will be rejected")
+			}
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticsHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+		unexpectedBody string
+	}{
+		{
+			name:           "Rejects a missing token",
+			path:           "/debug/config",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Redacts secrets of the config",
+			path:           "/debug/config",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   config.RedactedValue,
+			unexpectedBody: "db-password",
+		},
+		{
+			name:           "Serves the pprof index",
+			path:           "/debug/pprof/",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "goroutine",
+		},
+		{
+			name:           "Dumps the goroutines",
+			path:           "/debug/goroutines",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "goroutine ",
+		},
+		{
+			name:           "Reports build info",
+			path:           "/debug/build",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "go_version",
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	conf := &config.Config{
+		DB:    config.DBConfig{Password: "db-password"},
+		Admin: config.AdminConfig{Token: "secret"},
+	}
+	handler := NewDiagnosticsHandler(conf)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.authorization != "" {
+				request.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tc.expectedBody)
+			if tc.unexpectedBody != "" {
+				assert.NotContains(t, recorder.Body.String(), tc.unexpectedBody)
+			}
+		})
+	}
+
+	// the config served is a copy, the running config keeps its secrets
+	assert.Equal(t, "secret", conf.Admin.Token)
+}
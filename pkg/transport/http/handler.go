@@ -3,22 +3,21 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-
-	ut "github.com/go-playground/universal-translator"
-	en_translations "github.com/go-playground/validator/v10/translations/en"
-
+	"errors"
+	"io"
+	"mime"
 	"net/http"
-	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-kit/kit/endpoint"
 	gohttp "github.com/go-kit/kit/transport/http"
-	"github.com/go-playground/locales/en"
 	"github.com/go-playground/validator/v10"
 	"github.com/jainabhishek5986/employee-records/pkg/errs"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/i18n"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 	"go.uber.org/zap"
 )
@@ -31,7 +30,7 @@ type EncodeResponseFunc func(context.Context, *gin.Context,
 func NewHTTPHandler(ep endpoint.Endpoint, dec DecodeRequestFunc,
 	enc EncodeResponseFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		errorEncoder := gohttp.DefaultErrorEncoder
+		errorEncoder := localizedErrorEncoder
 		request, err := dec(c, c)
 		if err != nil {
 			errorEncoder(c, err, c.Writer)
@@ -51,6 +50,15 @@ func NewHTTPHandler(ep endpoint.Endpoint, dec DecodeRequestFunc,
 	}
 }
 
+// localizedErrorEncoder translates errors of the errs package to the locale
+// negotiated for the request and tags them with the request ID before
+// encoding them
+func localizedErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
+	err = errs.Localize(err, i18n.FromContext(ctx))
+	err = errs.WithRequestID(err, reqctx.RequestID(ctx))
+	gohttp.DefaultErrorEncoder(ctx, err, w)
+}
+
 func EncodeJSONResponse(_ context.Context, c *gin.Context,
 	response interface{}) error {
 
@@ -76,28 +84,37 @@ func EncodeJSONResponse(_ context.Context, c *gin.Context,
 	return json.NewEncoder(c.Writer).Encode(response)
 }
 
-func translateError(ctx context.Context, err error, validate *validator.Validate,
-	decodeStruct reflect.Value) (errMessage map[string]string, internalError error) {
+// EncodeFileResponse writes a global.FileResponse as an attachment
+func EncodeFileResponse(ctx context.Context, c *gin.Context,
+	response interface{}) error {
 
-	if err == nil {
-		return nil, nil
+	file, ok := response.(global.FileResponse)
+	if !ok {
+		zaplogger.Error(ctx, errs.StructDecodeError)
+		return errs.InternalErr()
 	}
 
-	// Converting error into string format
-	translator := en.New()
-	uni := ut.New(translator, translator)
-	trans, found := uni.GetTranslator("en")
-	if !found {
-		zaplogger.Error(ctx, "Converting error into string translator error", zap.Any("found", found))
-		return nil, errs.InternalErr()
+	c.Writer.Header().Set("Content-Type", file.ContentType)
+	c.Writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": file.FileName}))
+	c.Writer.WriteHeader(http.StatusOK)
+	_, err := c.Writer.Write(file.Data)
+	return err
+}
+
+func translateError(ctx context.Context, err error) (errMessage map[string]string,
+	internalError error) {
+
+	if err == nil {
+		return nil, nil
 	}
 
-	translationError := en_translations.RegisterDefaultTranslations(validate,
-		trans)
-	if translationError != nil {
-		zaplogger.Error(ctx, "Converting error into string translator error", zap.Any("error", translationError.Error()))
+	// Converting error into string format for the negotiated locale
+	if Translator == nil {
+		zaplogger.Error(ctx, "Converting error into string translator error", zap.Any("found", false))
 		return nil, errs.InternalErr()
 	}
+	trans := translatorFor(ctx)
 
 	errMessage = make(map[string]string)
 
@@ -105,26 +122,8 @@ func translateError(ctx context.Context, err error, validate *validator.Validate
 	validatorErrs, _ := err.(validator.ValidationErrors)
 
 	for _, e := range validatorErrs {
-		// Extract the JSON tag from the struct field, if available
-		field, ok := decodeStruct.Type().FieldByName(e.Field())
-		jsonFieldName := e.Field()
-		if ok && field.Tag.Get("json") != "" {
-			jsonFieldName = field.Tag.Get("json")
-		}
-
-		// Customize the error message
-		var errMsg string
-		switch e.Tag() {
-		case "required":
-			errMsg = fmt.Sprintf("%s is a required field", jsonFieldName)
-		case "trimspace":
-			errMsg = fmt.Sprintf("%s cannot be just spaces", jsonFieldName)
-		// Add more cases here for other validation tags if needed
-		default:
-			errMsg = fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", jsonFieldName, e.Tag())
-		}
-
-		errMessage[jsonFieldName] = errMsg
+		// Field names are the json names thanks to the registered tag name func
+		errMessage[e.Field()] = translateFieldError(trans, e)
 	}
 
 	return errMessage, nil
@@ -146,7 +145,7 @@ func DecodeEmployeesPOSTRequest(c context.Context, g *gin.Context) (request inte
 	}
 
 	var decodeEmployeesPOSTRequest global.DecodeEmployeesPOSTRequest
-	err = g.ShouldBindJSON(&decodeEmployeesPOSTRequest)
+	err = decodeStrictJSON(g.Request.Body, &decodeEmployeesPOSTRequest)
 	if err != nil {
 		zaplogger.Error(c, errs.DecodeEmployeesPOSTError, zap.Error(err))
 		err = errs.ErrorReqHandler(err)
@@ -155,9 +154,7 @@ func DecodeEmployeesPOSTRequest(c context.Context, g *gin.Context) (request inte
 	err = Validate.Struct(decodeEmployeesPOSTRequest)
 	if err != nil {
 		zaplogger.Error(c, errs.DecodeEmployeesPOSTError, zap.Error(err))
-		val := reflect.ValueOf(global.DecodeEmployeesPOSTRequest{})
-		payloadErrorMessages, internalError := translateError(c, err,
-			Validate, val)
+		payloadErrorMessages, internalError := translateError(c, err)
 		if internalError != nil {
 
 			return nil, errs.InternalErr()
@@ -168,9 +165,7 @@ func DecodeEmployeesPOSTRequest(c context.Context, g *gin.Context) (request inte
 		err = Validate.Struct(decodeEnv)
 		if err != nil {
 			zaplogger.Error(c, errs.DecodeEmployeesPOSTError, zap.Error(err))
-			val := reflect.ValueOf(global.DecodeEmployee{})
-			payloadErrorMessages, internalError := translateError(c, err,
-				Validate, val)
+			payloadErrorMessages, internalError := translateError(c, err)
 			if internalError != nil {
 
 				return nil, errs.InternalErr()
@@ -198,7 +193,7 @@ func DecodeEmployeePUTRequest(c context.Context, g *gin.Context) (request interf
 	}
 
 	var decodeEmployeePUTRequest global.DecodeEmployeePUTRequest
-	err = g.ShouldBindJSON(&decodeEmployeePUTRequest)
+	err = decodeStrictJSON(g.Request.Body, &decodeEmployeePUTRequest)
 	if err != nil {
 		zaplogger.Error(c, errs.DecodeEmployeesPOSTError, zap.Error(err))
 		err = errs.ErrorReqHandler(err)
@@ -207,9 +202,7 @@ func DecodeEmployeePUTRequest(c context.Context, g *gin.Context) (request interf
 	err = Validate.Struct(decodeEmployeePUTRequest)
 	if err != nil {
 		zaplogger.Error(c, errs.DecodeEmployeePUTError, zap.Error(err))
-		val := reflect.ValueOf(global.DecodeEmployeePUTRequest{})
-		payloadErrorMessages, internalError := translateError(c, err,
-			Validate, val)
+		payloadErrorMessages, internalError := translateError(c, err)
 		if internalError != nil {
 
 			return nil, errs.InternalErr()
@@ -220,6 +213,150 @@ func DecodeEmployeePUTRequest(c context.Context, g *gin.Context) (request interf
 	return decodeEmployeePUTRequest, nil
 }
 
+func DecodeEmployeesPATCHRequest(c context.Context, g *gin.Context) (request interface{}, err error) {
+
+	// Checking body payload is empty or not
+	ErrMsg := make([]interface{}, 0)
+	queryParams := g.Request.URL.Query()
+
+	if len(queryParams) > 0 {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.BadQueryParams})
+		return nil, errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	var decodeEmployeesPATCHRequest global.DecodeEmployeesPATCHRequest
+	err = decodeStrictJSON(g.Request.Body, &decodeEmployeesPATCHRequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeEmployeesPATCHError, zap.Error(err))
+		err = errs.ErrorReqHandler(err)
+		return nil, err
+	}
+	err = Validate.Struct(decodeEmployeesPATCHRequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeEmployeesPATCHError, zap.Error(err))
+		payloadErrorMessages, internalError := translateError(c, err)
+		if internalError != nil {
+
+			return nil, errs.InternalErr()
+		}
+		return nil, errs.RequestNotProcessed(payloadErrorMessages)
+	}
+
+	// a list of changes per ID or a filter with the changes, never both
+	byFilter := decodeEmployeesPATCHRequest.Filter != nil || decodeEmployeesPATCHRequest.Changes != nil
+	if byFilter == (len(decodeEmployeesPATCHRequest.Updates) > 0) {
+		return nil, errs.RequestNotProcessed(errs.BulkSelectorError)
+	}
+	if byFilter {
+		if decodeEmployeesPATCHRequest.Filter == nil || decodeEmployeesPATCHRequest.Changes == nil {
+			return nil, errs.RequestNotProcessed(errs.BulkSelectorError)
+		}
+		if decodeEmployeesPATCHRequest.Filter.Empty() {
+			return nil, errs.RequestNotProcessed(errs.BulkEmptyFilterError)
+		}
+		if decodeEmployeesPATCHRequest.Changes.Empty() {
+			return nil, errs.RequestNotProcessed(errs.BulkNoChangesError)
+		}
+	}
+	for _, update := range decodeEmployeesPATCHRequest.Updates {
+		if update.Changes.Empty() {
+			return nil, errs.RequestNotProcessed(errs.BulkNoChangesError)
+		}
+	}
+
+	return decodeEmployeesPATCHRequest, nil
+}
+
+// DecodeEmployeesDELETERequest reads the filter of a bulk delete from the
+// query, ids takes a comma separated list
+func DecodeEmployeesDELETERequest(c context.Context, g *gin.Context) (request interface{}, err error) {
+
+	ErrMsg := make([]interface{}, 0)
+	badQueryParams := func() error {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.BadQueryParams})
+		return errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	// Empty body payload
+	if g.Request.Body != http.NoBody {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.PayloadShouldBeEmpty})
+		return nil, errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	var decodeEmployeesDELETERequest global.DecodeEmployeesDELETERequest
+	filter := &decodeEmployeesDELETERequest.Filter
+	for key, values := range g.Request.URL.Query() {
+		value := values[len(values)-1]
+		switch key {
+		case "ids":
+			for _, value := range values {
+				for _, id := range strings.Split(value, ",") {
+					integerID, err := strconv.Atoi(strings.TrimSpace(id))
+					if err != nil {
+						zaplogger.Error(c, errs.DecodeEmployeesDELETEError, zap.Error(err))
+						return nil, errs.BadRequest(errs.ConvertToIntError)
+					}
+					filter.IDs = append(filter.IDs, integerID)
+				}
+			}
+		case "name":
+			filter.Name = &value
+		case "position":
+			filter.Position = &value
+		case "salary_min", "salary_max":
+			salary, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				zaplogger.Error(c, errs.DecodeEmployeesDELETEError, zap.Error(err))
+				return nil, badQueryParams()
+			}
+			if key == "salary_min" {
+				filter.SalaryMin = &salary
+			} else {
+				filter.SalaryMax = &salary
+			}
+		case "dry_run", "atomic":
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				zaplogger.Error(c, errs.DecodeEmployeesDELETEError, zap.Error(err))
+				return nil, badQueryParams()
+			}
+			if key == "dry_run" {
+				decodeEmployeesDELETERequest.DryRun = flag
+			} else {
+				decodeEmployeesDELETERequest.Atomic = flag
+			}
+		default:
+			return nil, badQueryParams()
+		}
+	}
+
+	err = Validate.Struct(decodeEmployeesDELETERequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeEmployeesDELETEError, zap.Error(err))
+		payloadErrorMessages, internalError := translateError(c, err)
+		if internalError != nil {
+
+			return nil, errs.InternalErr()
+		}
+		return nil, errs.RequestNotProcessed(payloadErrorMessages)
+	}
+	// an unfiltered delete would remove every employee
+	if filter.Empty() {
+		return nil, errs.RequestNotProcessed(errs.BulkEmptyFilterError)
+	}
+
+	return decodeEmployeesDELETERequest, nil
+}
+
 func DecodeByIDRequest(ctx context.Context, g *gin.Context) (request interface{}, err error) {
 
 	// Checking body payload is empty or not
@@ -254,6 +391,103 @@ func DecodeByIDRequest(ctx context.Context, g *gin.Context) (request interface{}
 	return integerID, err
 }
 
+/*
+DecodeEmploymentTransitionRequest : returns the decoder of the transition
+routes /employee/:id/<action>. The body is optional, the fields an action
+requires are checked by the service.
+
+Parameters
+----------
+action: transition of the route, see pkg/employment
+*/
+func DecodeEmploymentTransitionRequest(action string) DecodeRequestFunc {
+	return func(c context.Context, g *gin.Context) (request interface{}, err error) {
+
+		ErrMsg := make([]interface{}, 0)
+		if len(g.Request.URL.Query()) > 0 {
+			ErrMsg = append(ErrMsg, errs.ErrMessage{
+				Key:    "BadPayload",
+				Detail: errs.BadQueryParams})
+			return nil, errs.ErrResponse(errs.BadRequestTitle,
+				http.StatusBadRequest, ErrMsg)
+		}
+
+		integerID, err := strconv.Atoi(g.Param("id"))
+		if err != nil {
+			zaplogger.Error(c, errs.ConvertToIntError)
+			return nil, errs.BadRequest(errs.ConvertToIntError)
+		}
+
+		decodeTransitionRequest := global.DecodeEmploymentTransitionRequest{ID: integerID, Action: action}
+		if g.Request.Body != http.NoBody {
+			err = decodeStrictJSON(g.Request.Body, &decodeTransitionRequest)
+			// e.g. activate and leave may be sent without a body
+			if err != nil && !errors.Is(err, io.EOF) {
+				zaplogger.Error(c, errs.EmploymentValidationError, zap.Error(err))
+				return nil, errs.ErrorReqHandler(err)
+			}
+		}
+		err = Validate.Struct(decodeTransitionRequest)
+		if err != nil {
+			zaplogger.Error(c, errs.EmploymentValidationError, zap.Error(err))
+			payloadErrorMessages, internalError := translateError(c, err)
+			if internalError != nil {
+
+				return nil, errs.InternalErr()
+			}
+			return nil, errs.RequestNotProcessed(payloadErrorMessages)
+		}
+
+		return decodeTransitionRequest, nil
+	}
+}
+
+// DecodeJobNameRequest decodes the job name of /admin/jobs/:name routes,
+// they take neither a body nor query params
+func DecodeJobNameRequest(ctx context.Context, g *gin.Context) (request interface{}, err error) {
+
+	ErrMsg := make([]interface{}, 0)
+	if len(g.Request.URL.Query()) > 0 {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.BadQueryParams})
+		return nil, errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	if g.Request.Body != http.NoBody {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.PayloadShouldBeEmpty})
+		return nil, errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	return g.Param("name"), nil
+}
+
+func DecodeAsyncJobIDRequest(ctx context.Context, g *gin.Context) (request interface{}, err error) {
+
+	ErrMsg := make([]interface{}, 0)
+	if len(g.Request.URL.Query()) > 0 {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.BadQueryParams})
+		return nil, errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	if g.Request.Body != http.NoBody {
+		ErrMsg = append(ErrMsg, errs.ErrMessage{
+			Key:    "BadPayload",
+			Detail: errs.PayloadShouldBeEmpty})
+		return nil, errs.ErrResponse(errs.BadRequestTitle,
+			http.StatusBadRequest, ErrMsg)
+	}
+
+	return g.Param("id"), nil
+}
+
 func DecodeAllRequest(ctx context.Context, g *gin.Context) (request interface{}, err error) {
 
 	// Checking body payload is empty or not
@@ -280,3 +514,49 @@ func DecodeAllRequest(ctx context.Context, g *gin.Context) (request interface{},
 
 	return paramsMap, err
 }
+
+func DecodeLogLevelPUTRequest(c context.Context, g *gin.Context) (request interface{}, err error) {
+
+	var decodeLogLevelPUTRequest global.DecodeLogLevelPUTRequest
+	err = decodeStrictJSON(g.Request.Body, &decodeLogLevelPUTRequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeLogLevelPUTError, zap.Error(err))
+		err = errs.ErrorReqHandler(err)
+		return nil, err
+	}
+	err = Validate.Struct(decodeLogLevelPUTRequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeLogLevelPUTError, zap.Error(err))
+		payloadErrorMessages, internalError := translateError(c, err)
+		if internalError != nil {
+
+			return nil, errs.InternalErr()
+		}
+		return nil, errs.RequestNotProcessed(payloadErrorMessages)
+	}
+
+	return decodeLogLevelPUTRequest, nil
+}
+
+func DecodeAPIKeyPOSTRequest(c context.Context, g *gin.Context) (request interface{}, err error) {
+
+	var decodeAPIKeyPOSTRequest global.DecodeAPIKeyPOSTRequest
+	err = decodeStrictJSON(g.Request.Body, &decodeAPIKeyPOSTRequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeAPIKeyPOSTError, zap.Error(err))
+		err = errs.ErrorReqHandler(err)
+		return nil, err
+	}
+	err = Validate.Struct(decodeAPIKeyPOSTRequest)
+	if err != nil {
+		zaplogger.Error(c, errs.DecodeAPIKeyPOSTError, zap.Error(err))
+		payloadErrorMessages, internalError := translateError(c, err)
+		if internalError != nil {
+
+			return nil, errs.InternalErr()
+		}
+		return nil, errs.RequestNotProcessed(payloadErrorMessages)
+	}
+
+	return decodeAPIKeyPOSTRequest, nil
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/i18n"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+)
+
+// maxRequestIDLength bounds client supplied request IDs
+const maxRequestIDLength = 128
+
+/*
+RequestIDMiddleware : accepts the X-Request-ID of the caller or generates
+one, stores it with the route template in the request context for the
+logger and echoes it on the response
+*/
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(reqctx.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx := reqctx.WithRequestID(c.Request.Context(), requestID)
+		if route := c.FullPath(); route != "" {
+			ctx = reqctx.WithRoute(ctx, route)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Header(reqctx.RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII IDs of bounded length only, so
+// that callers cannot inject arbitrary content into our logs
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+/*
+LocaleMiddleware : negotiates the locale of the response from the
+Accept-Language header and stores it in the request context so that
+validation and errs messages can be translated
+*/
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		name       string
+		requestID  string
+		expectSame bool
+	}{
+		{
+			name:       "Generates a request ID",
+			requestID:  "",
+			expectSame: false,
+		},
+		{
+			name:       "Accepts the caller request ID",
+			requestID:  "batch-job-42",
+			expectSame: true,
+		},
+		{
+			name:       "Replaces an invalid request ID",
+			requestID:  "line\nbreak",
+			expectSame: false,
+		},
+		{
+			name:       "Replaces an oversized request ID",
+			requestID:  strings.Repeat("a", maxRequestIDLength+1),
+			expectSame: false,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestIDMiddleware())
+	router.GET("/employee/:id", func(c *gin.Context) {
+		localizedErrorEncoder(c, errs.InternalErr(), c.Writer)
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/employee/1", nil)
+			if tc.requestID != "" {
+				request.Header.Set(reqctx.RequestIDHeader, tc.requestID)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			requestID := recorder.Header().Get(reqctx.RequestIDHeader)
+			assert.NotEmpty(t, requestID)
+			assert.Equal(t, tc.expectSame, requestID == tc.requestID)
+			assert.Contains(t, recorder.Body.String(), `"request_id":"`+requestID+`"`)
+		})
+	}
+}
@@ -0,0 +1,502 @@
+package http
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/validation"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification we generate
+const OpenAPIVersion = "3.1.0"
+
+//go:embed swagger/index.html
+var swaggerUIPage []byte
+
+// swaggerUIAssets are the stylesheet and bundle of swagger-ui-dist 5.18.2,
+// vendored so that the page loads nothing from a CDN
+//
+//go:embed swagger/swagger-ui.css swagger/swagger-ui-bundle.js
+var swaggerUIAssets embed.FS
+
+// RouteDoc : documentation of a single route used to build the OpenAPI
+// document. Request and Response are sample values, interface fields of
+// the samples are documented with their dynamic value.
+type RouteDoc struct {
+	Summary       string
+	Description   string
+	Tag           string
+	PathParams    []ParamDoc
+	QueryParams   []ParamDoc
+	Request       interface{}
+	Response      interface{}
+	SuccessStatus int
+	ErrorStatuses []int
+}
+
+// ParamDoc : documentation of a path or query parameter
+type ParamDoc struct {
+	Name        string
+	Description string
+	Type        string
+	Required    bool
+}
+
+// routeKey builds the key used in the route documentation table
+func routeKey(method string, path string) string {
+	return method + " " + path
+}
+
+/*
+NewOpenAPIDocument : generates the OpenAPI document of the v1 API from the
+route table of a router on which RegisterAPIRoutes has been called
+
+Parameters
+----------
+routes: routes registered on the gin engine
+*/
+func NewOpenAPIDocument(routes gin.RoutesInfo) (map[string]interface{}, error) {
+	generator := &openAPIGenerator{components: make(map[string]interface{})}
+	paths := make(map[string]interface{})
+
+	for _, route := range routes {
+		doc, ok := routeDocs[routeKey(route.Method, route.Path)]
+		if !ok {
+			return nil, fmt.Errorf("route %s %s is not documented", route.Method, route.Path)
+		}
+
+		path := openAPIPath(route.Path)
+		item, _ := paths[path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = generator.operation(route, doc)
+	}
+
+	generator.components["Error"] = errorSchema()
+	generator.components["ProcessingError"] = processingErrorSchema()
+
+	return map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "Employee Records Service",
+			"description": "Microservice for Employee Records",
+			"version":     global.BinaryVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": generator.components,
+		},
+	}, nil
+}
+
+/*
+BuildOpenAPIDocument : registers the API routes on a throw away router and
+generates the OpenAPI document from it. No database is needed since the
+routes are never served. The gin mode of the caller applies.
+*/
+func BuildOpenAPIDocument() (map[string]interface{}, error) {
+	router := gin.New()
+	RegisterAPIRoutes(router.Group("/api/v1"), nil, nil)
+	return NewOpenAPIDocument(router.Routes())
+}
+
+/*
+RegisterDocsRoutes : serves the OpenAPI document and the Swagger UI page
+with its assets. The group carries none of the API middlewares, the
+documentation is readable without an API key.
+*/
+func RegisterDocsRoutes(docsRoutesGroup *gin.RouterGroup, document map[string]interface{}) {
+	docsRoutesGroup.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	})
+	docsRoutesGroup.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUIPage)
+	})
+	docsRoutesGroup.GET("/docs/:asset", func(c *gin.Context) {
+		c.FileFromFS("swagger/"+c.Param("asset"), http.FS(swaggerUIAssets))
+	})
+
+	zaplogger.Info(context.Background(), "API documentation routes injected",
+		zap.Int("paths", len(document["paths"].(map[string]interface{}))))
+}
+
+// openAPIPath converts gin path parameters (:id) to OpenAPI ones ({id})
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type openAPIGenerator struct {
+	components map[string]interface{}
+}
+
+func (g *openAPIGenerator) operation(route gin.RouteInfo, doc RouteDoc) map[string]interface{} {
+	operation := map[string]interface{}{
+		"summary":     doc.Summary,
+		"operationId": operationID(route),
+	}
+	if doc.Description != "" {
+		operation["description"] = doc.Description
+	}
+	if doc.Tag != "" {
+		operation["tags"] = []string{doc.Tag}
+	}
+
+	parameters := make([]interface{}, 0)
+	for _, param := range doc.PathParams {
+		param.Required = true
+		parameters = append(parameters, parameter("path", param))
+	}
+	for _, param := range doc.QueryParams {
+		parameters = append(parameters, parameter("query", param))
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if doc.Request != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(g.schema(reflect.ValueOf(doc.Request))),
+		}
+	}
+
+	successStatus := doc.SuccessStatus
+	if successStatus == 0 {
+		successStatus = http.StatusOK
+	}
+	content := jsonContent(g.schema(reflect.ValueOf(doc.Response)))
+	// files are sent as they are, see EncodeFileResponse
+	if file, ok := doc.Response.(global.FileResponse); ok {
+		content = map[string]interface{}{
+			file.ContentType: map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			},
+		}
+	}
+	responses := map[string]interface{}{
+		strconv.Itoa(successStatus): map[string]interface{}{
+			"description": http.StatusText(successStatus),
+			"content":     content,
+		},
+	}
+	for _, status := range doc.ErrorStatuses {
+		responses[strconv.Itoa(status)] = map[string]interface{}{
+			"description": http.StatusText(status),
+			"content":     jsonContent(errorSchemaFor(status)),
+		}
+	}
+	operation["responses"] = responses
+
+	return operation
+}
+
+func operationID(route gin.RouteInfo) string {
+	name := strings.ToLower(route.Method)
+	for _, segment := range strings.Split(route.Path, "/") {
+		if segment == "" || segment == "api" || segment == "v1" {
+			continue
+		}
+		segment = strings.TrimLeft(segment, ":*")
+		name += strings.ToUpper(segment[:1]) + segment[1:]
+	}
+	return name
+}
+
+func parameter(in string, param ParamDoc) map[string]interface{} {
+	paramType := param.Type
+	if paramType == "" {
+		paramType = "string"
+	}
+	return map[string]interface{}{
+		"name":        param.Name,
+		"in":          in,
+		"description": param.Description,
+		"required":    param.Required,
+		"schema":      map[string]interface{}{"type": paramType},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+func ref(component string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + component}
+}
+
+// errorSchemaFor returns the error shape the errs package produces for a
+// status. Payload decoding errors use HTTPErr, everything else HTTPError.
+func errorSchemaFor(status int) map[string]interface{} {
+	if status == http.StatusBadRequest {
+		return map[string]interface{}{
+			"oneOf": []interface{}{ref("Error"), ref("ProcessingError")},
+		}
+	}
+	return ref("ProcessingError")
+}
+
+// errorSchema documents errs.HTTPErr
+func errorSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"type", "title", "status", "message"},
+		"properties": map[string]interface{}{
+			"type":       map[string]interface{}{"type": "string", "const": "error"},
+			"title":      map[string]interface{}{"type": "string"},
+			"status":     map[string]interface{}{"type": "integer"},
+			"request_id": map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"key", "detail"},
+					"properties": map[string]interface{}{
+						"key":    map[string]interface{}{"type": "string"},
+						"detail": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// processingErrorSchema documents errs.HTTPError, the message is either a
+// plain text or a map of json field name to validation message
+func processingErrorSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"type", "message"},
+		"properties": map[string]interface{}{
+			"type":  map[string]interface{}{"type": "string"},
+			"title": map[string]interface{}{"type": "string"},
+			"message": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+			"errors":     map[string]interface{}{},
+			"request_id": map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema builds the JSON schema of a sample value. Named structs without
+// dynamic fields become components so they are shared between operations.
+func (g *openAPIGenerator) schema(value reflect.Value) map[string]interface{} {
+	if !value.IsValid() {
+		return map[string]interface{}{}
+	}
+
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return map[string]interface{}{}
+		}
+		return g.schema(value.Elem())
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nullable(g.schema(reflect.New(value.Type().Elem()).Elem()))
+		}
+		return nullable(g.schema(value.Elem()))
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		if hasInterfaceField(value.Type()) {
+			return g.structSchema(value)
+		}
+		name := value.Type().Name()
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = g.structSchema(value)
+		}
+		return ref(name)
+	case reflect.Slice, reflect.Array:
+		item := reflect.New(value.Type().Elem()).Elem()
+		if value.Len() > 0 {
+			item = value.Index(0)
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(item)}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.schema(reflect.New(value.Type().Elem()).Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (g *openAPIGenerator) structSchema(value reflect.Value) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := jsonTagName(field)
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		property := g.schema(value.Field(i))
+		tags := strings.Split(field.Tag.Get("validate"), ",")
+		if applyValidateTags(property, tags) {
+			required = append(required, name)
+		}
+		if strings.Contains(field.Tag.Get("json"), ",omitempty") {
+			property = nonNull(property)
+		}
+		properties[name] = property
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyValidateTags maps validator tags to JSON schema keywords and reports
+// whether the field is required
+func applyValidateTags(property map[string]interface{}, tags []string) (required bool) {
+	if _, isRef := property["$ref"]; isRef {
+		return hasTag(tags, "required")
+	}
+
+	for _, tag := range tags {
+		name, param, _ := strings.Cut(tag, "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "gte":
+			property[lengthOrValue(property, "minLength", "minimum")] = numberParam(param)
+		case "max", "lte":
+			property[lengthOrValue(property, "maxLength", "maximum")] = numberParam(param)
+		case "gt":
+			property["exclusiveMinimum"] = numberParam(param)
+		case "lt":
+			property["exclusiveMaximum"] = numberParam(param)
+		case "oneof":
+			property["enum"] = strings.Fields(param)
+		case "trimspace":
+			property["pattern"] = `\S`
+		case "employee_name":
+			rules := validation.Rules()
+			property["minLength"] = rules.NameMinLength
+			property["maxLength"] = rules.NameMaxLength
+			property["pattern"] = rules.NamePattern
+		case "position":
+			if positions := validation.Rules().Positions; len(positions) > 0 {
+				property["enum"] = positions
+			}
+		case "salary":
+			salaryRange := validation.SalaryRangeFor("")
+			property["minimum"] = salaryRange.Min
+			property["maximum"] = salaryRange.Max
+			property["description"] = "Must lie within the salary range configured for the position"
+		}
+	}
+
+	return required
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func lengthOrValue(property map[string]interface{}, lengthKey string, valueKey string) string {
+	if isType(property, "string") {
+		return lengthKey
+	}
+	return valueKey
+}
+
+func isType(property map[string]interface{}, name string) bool {
+	switch t := property["type"].(type) {
+	case string:
+		return t == name
+	case []string:
+		return hasTag(t, name)
+	}
+	return false
+}
+
+func numberParam(param string) interface{} {
+	if value, err := strconv.ParseFloat(param, 64); err == nil {
+		return value
+	}
+	return param
+}
+
+// nullable marks a schema as accepting null the OpenAPI 3.1 way
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []string{t, "null"}
+		return schema
+	}
+	return map[string]interface{}{"oneOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+// nonNull reverts nullable for fields omitted rather than sent as null
+func nonNull(schema map[string]interface{}) map[string]interface{} {
+	if t, ok := schema["type"].([]string); ok && len(t) == 2 {
+		schema["type"] = t[0]
+	}
+	return schema
+}
+
+func hasInterfaceField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() == reflect.Interface {
+			return true
+		}
+	}
+	return false
+}
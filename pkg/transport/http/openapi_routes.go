@@ -0,0 +1,189 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+)
+
+const (
+	employeeTag = "Employee"
+	jobTag      = "Job"
+)
+
+var employeeIDParam = ParamDoc{
+	Name:        "id",
+	Description: "Unique ID For Employee",
+	Type:        "integer",
+}
+
+// employeeFilterParams select the employees of a bulk delete
+var employeeFilterParams = []ParamDoc{
+	{Name: "ids", Description: "comma separated employee IDs"},
+	{Name: "name", Description: "employees with this name"},
+	{Name: "position", Description: "employees with this position"},
+	{Name: "salary_min", Description: "employees earning at least this salary", Type: "number"},
+	{Name: "salary_max", Description: "employees earning at most this salary", Type: "number"},
+	{Name: "dry_run", Description: "report the employees that would be deleted without deleting them", Type: "boolean"},
+	{Name: "atomic", Description: "delete every selected employee or none", Type: "boolean"},
+}
+
+// transitionDoc documents a route of the employment lifecycle, they share
+// the request and the statuses
+func transitionDoc(summary string, description string) RouteDoc {
+	return RouteDoc{
+		Summary:       summary,
+		Description:   description,
+		Tag:           employeeTag,
+		PathParams:    []ParamDoc{employeeIDParam},
+		Request:       global.DecodeEmploymentTransitionRequest{},
+		Response:      global.SuccessGETInfo{Data: models.Employee{}},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	}
+}
+
+var jobIDParam = ParamDoc{
+	Name:        "id",
+	Description: "ID of the job, from the Location header of its submission",
+	Type:        "string",
+}
+
+// routeDocs documents every route registered by RegisterAPIRoutes, keyed
+// by method and full gin path. TestRoutesAreDocumented fails for routes
+// missing here.
+var routeDocs = map[string]RouteDoc{
+	routeKey(http.MethodGet, "/api/v1/employee/:id"): {
+		Summary:       "Get Employee By ID",
+		Description:   "Fetches Records for Employees corresponding to given ID.",
+		Tag:           employeeTag,
+		PathParams:    []ParamDoc{employeeIDParam},
+		Response:      global.SuccessGETInfo{Data: models.Employee{}},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodGet, "/api/v1/employee"): {
+		Summary:     "Get Employees",
+		Description: "Fetches Records for all Employees in a paginated format.",
+		Tag:         employeeTag,
+		QueryParams: []ParamDoc{
+			{Name: "page", Description: "page user want see", Type: "integer"},
+			{Name: "per_page", Description: "number of records to be displayed per page", Type: "integer"},
+			{Name: "status", Description: "comma separated employment statuses, terminated employees are left out without it"},
+		},
+		Response: global.SuccessGETInfo{
+			Data:       []models.Employee{},
+			Pagination: map[string]int{},
+		},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodPost, "/api/v1/employee"): {
+		Summary:       "Create Employee",
+		Description:   "Creates new records for Employees, in bulk if required.",
+		Tag:           employeeTag,
+		Request:       global.DecodeEmployeesPOSTRequest{Employees: []global.DecodeEmployee{{}}},
+		Response:      global.SuccessInfo{},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodPut, "/api/v1/employee"): {
+		Summary:       "Update Employee",
+		Description:   "Updates Employee Record corresponding to given ID. Conflicts when its position or salary changes concurrently.",
+		Tag:           employeeTag,
+		Request:       global.DecodeEmployeePUTRequest{},
+		Response:      global.SuccessInfo{},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodDelete, "/api/v1/employee/:id"): {
+		Summary:       "Delete Employee",
+		Description:   "Deletes Record for Employee corresponding to given ID.",
+		Tag:           employeeTag,
+		PathParams:    []ParamDoc{employeeIDParam},
+		Response:      global.SuccessInfo{},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodPatch, "/api/v1/employee"): {
+		Summary:     "Bulk Update Employees",
+		Description: "Applies a list of changes per ID, or the same changes to every employee a filter selects, in one transaction. Reports the result per ID.",
+		Tag:         employeeTag,
+		Request: global.DecodeEmployeesPATCHRequest{
+			Updates: []global.DecodeEmployeeChange{{}},
+			Filter:  &global.EmployeeFilter{},
+			Changes: &global.EmployeeChanges{},
+		},
+		Response:      global.SuccessGETInfo{Data: global.BulkResult{Results: []global.BulkItemResult{{}}}},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodDelete, "/api/v1/employee"): {
+		Summary:       "Bulk Delete Employees",
+		Description:   "Deletes the employees the query selects in one transaction. Reports the result per ID.",
+		Tag:           employeeTag,
+		QueryParams:   employeeFilterParams,
+		Response:      global.SuccessGETInfo{Data: global.BulkResult{Results: []global.BulkItemResult{{}}}},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodPost, "/api/v1/employee/:id/onboard"): transitionDoc("Onboard Employee",
+		"Moves a candidate to onboarding, hire_date is required."),
+	routeKey(http.MethodPost, "/api/v1/employee/:id/activate"): transitionDoc("Activate Employee",
+		"Moves an onboarding or rehired employee to active, also ends a leave and withdraws a notice."),
+	routeKey(http.MethodPost, "/api/v1/employee/:id/leave"): transitionDoc("Start Employee Leave",
+		"Moves an active employee on leave."),
+	routeKey(http.MethodPost, "/api/v1/employee/:id/notice"): transitionDoc("Give Employee Notice",
+		"Moves an active employee or an employee on leave to the notice period, last_working_day is required and becomes the termination date."),
+	routeKey(http.MethodPost, "/api/v1/employee/:id/terminate"): transitionDoc("Terminate Employee",
+		"Terminates an employee, reason and last_working_day are required. Terminated employees are left out of the listing by default."),
+	routeKey(http.MethodPost, "/api/v1/employee/:id/rehire"): transitionDoc("Rehire Employee",
+		"Rehires a terminated employee, hire_date is required and must be after the termination date."),
+	routeKey(http.MethodPost, "/api/v1/employee/import"): {
+		Summary:       "Import Employees",
+		Description:   "Queues a job creating the employees one by one, the job at the Location header reports the rows that failed.",
+		Tag:           jobTag,
+		Request:       global.DecodeEmployeesPOSTRequest{Employees: []global.DecodeEmployee{{}}},
+		Response:      global.SuccessAcceptedInfo{Data: global.AsyncJobInfo{}},
+		SuccessStatus: http.StatusAccepted,
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	routeKey(http.MethodPost, "/api/v1/employee/export"): {
+		Summary:       "Export Employees",
+		Description:   "Queues a job writing the employees to a CSV file, downloaded from the job once it succeeded.",
+		Tag:           jobTag,
+		Response:      global.SuccessAcceptedInfo{Data: global.AsyncJobInfo{}},
+		SuccessStatus: http.StatusAccepted,
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	routeKey(http.MethodPost, "/api/v1/employee/update"): {
+		Summary:     "Update Employees",
+		Description: "Queues a job applying a PATCH /api/v1/employee payload, the job at the Location header reports the result per ID.",
+		Tag:         jobTag,
+		Request: global.DecodeEmployeesPATCHRequest{
+			Updates: []global.DecodeEmployeeChange{{}},
+			Filter:  &global.EmployeeFilter{},
+			Changes: &global.EmployeeChanges{},
+		},
+		Response:      global.SuccessAcceptedInfo{Data: global.AsyncJobInfo{}},
+		SuccessStatus: http.StatusAccepted,
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable},
+	},
+	routeKey(http.MethodGet, "/api/v1/jobs/:id"): {
+		Summary:       "Get Job",
+		Description:   "Fetches the status, progress and report of a job submitted by the caller.",
+		Tag:           jobTag,
+		PathParams:    []ParamDoc{jobIDParam},
+		Response:      global.SuccessGETInfo{Data: global.AsyncJobInfo{}},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodDelete, "/api/v1/jobs/:id"): {
+		Summary:       "Cancel Job",
+		Description:   "Cancels a queued or running job submitted by the caller, rows already processed are kept.",
+		Tag:           jobTag,
+		PathParams:    []ParamDoc{jobIDParam},
+		Response:      global.SuccessGETInfo{Data: global.AsyncJobInfo{}},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	routeKey(http.MethodGet, "/api/v1/jobs/:id/download"): {
+		Summary:       "Download Job Output",
+		Description:   "Downloads the file produced by a succeeded job submitted by the caller.",
+		Tag:           jobTag,
+		PathParams:    []ParamDoc{jobIDParam},
+		Response:      global.FileResponse{ContentType: "text/csv"},
+		ErrorStatuses: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+}
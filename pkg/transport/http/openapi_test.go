@@ -0,0 +1,142 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutesAreDocumented(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	RegisterAPIRoutes(router.Group("/api/v1"), nil, nil)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		key := routeKey(route.Method, route.Path)
+		registered[key] = true
+		_, ok := routeDocs[key]
+		assert.True(t, ok, "route %s has no entry in routeDocs", key)
+	}
+
+	for key := range routeDocs {
+		assert.True(t, registered[key], "routeDocs entry %s has no registered route", key)
+	}
+}
+
+func TestBuildOpenAPIDocument(t *testing.T) {
+	document, err := BuildOpenAPIDocument()
+	assert.NoError(t, err)
+	assert.Equal(t, OpenAPIVersion, document["openapi"])
+
+	paths := document["paths"].(map[string]interface{})
+	assert.Contains(t, paths, "/api/v1/employee/{id}")
+
+	schemas := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	employee := schemas["DecodeEmployee"].(map[string]interface{})
+	assert.Equal(t, []string{"name", "position", "salary"}, employee["required"])
+
+	salary := employee["properties"].(map[string]interface{})["salary"].(map[string]interface{})
+	assert.Contains(t, salary, "minimum")
+
+	getByID := paths["/api/v1/employee/{id}"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Contains(t, getByID["responses"], "422")
+	// missing employees and those of other tenants
+	assert.Contains(t, getByID["responses"], "404")
+}
+
+func TestDocsRoutes(t *testing.T) {
+	testCases := []struct {
+		name                string
+		path                string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			name:                "Swagger UI page",
+			path:                "/api/v1/docs",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+		},
+		{
+			name:                "Vendored stylesheet",
+			path:                "/api/v1/docs/swagger-ui.css",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/css; charset=utf-8",
+		},
+		{
+			name:                "Vendored bundle",
+			path:                "/api/v1/docs/swagger-ui-bundle.js",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/javascript; charset=utf-8",
+		},
+		{
+			name:           "Unknown asset",
+			path:           "/api/v1/docs/swagger-ui.js",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	RegisterDocsRoutes(router.Group("/api/v1"), map[string]interface{}{"paths": map[string]interface{}{}})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+			// the page loads nothing from other origins
+			assert.NotContains(t, recorder.Body.String(), "https://unpkg.com")
+		})
+	}
+}
+
+func TestDocsRoutesArePublic(t *testing.T) {
+	db := testutil.OpenDB(t, models.All()...)
+	testutil.InitLogger(t)
+	conf := &config.Config{Auth: config.AuthConfig{Required: true}}
+	server, err := NewAPIServer(context.Background(), conf, db, nil, nil)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "Swagger UI page without a key",
+			path:           "/api/v1/docs",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "OpenAPI document without a key",
+			path:           "/api/v1/openapi.json",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "API without a key",
+			path:           "/api/v1/employee",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.srv.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/ratelimit"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// Rate limit headers of the IETF RateLimit header fields draft
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+/*
+RateLimitMiddleware : takes a token from the bucket of the caller for the
+route and rejects the request with 429 once it is empty. Callers are keyed
+by the authenticated identity of the request context, which the auth
+middlewares set from the API key or client certificate, and by client IP
+otherwise. Store failures let the request through.
+
+Parameters
+----------
+limiter: limiter applying the configured quotas
+*/
+func RateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limiter.Enabled() {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		result, err := limiter.Allow(c, rateLimitClient(c), c.Request.Method, route)
+		if err != nil {
+			zaplogger.Error(c, errs.RateLimitStoreError, zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if result.Limit > 0 {
+			c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+			c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+			c.Header(RateLimitResetHeader, ceilSeconds(result.Reset))
+		}
+		if !result.Allowed {
+			c.Header(RetryAfterHeader, ceilSeconds(result.RetryAfter))
+			zaplogger.Warn(c, errs.RateLimitExceededMessage)
+			localizedErrorEncoder(c, errs.RequestRatelimitExceeded(errs.RateLimitExceededMessage), c.Writer)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitClient keys the caller by tenant and identity, falling back to
+// the IP, so that tenants with an own quota do not share buckets
+func rateLimitClient(c *gin.Context) string {
+	client := "ip:" + c.ClientIP()
+	if userID := reqctx.UserID(c); userID != "" {
+		client = "user:" + userID
+	}
+	if tenantID := reqctx.TenantID(c); tenantID != "" {
+		return tenantID + "/" + client
+	}
+	return client
+}
+
+// ceilSeconds formats a duration as whole seconds rounded up
+func ceilSeconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
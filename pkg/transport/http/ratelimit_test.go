@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	testCases := []struct {
+		name               string
+		expectedStatus     int
+		expectedRemaining  string
+		expectedRetryAfter string
+	}{
+		{
+			name:              "First request is allowed",
+			expectedStatus:    http.StatusOK,
+			expectedRemaining: "1",
+		},
+		{
+			name:              "Second request takes the last token",
+			expectedStatus:    http.StatusOK,
+			expectedRemaining: "0",
+		},
+		{
+			name:               "Third request is rejected",
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedRemaining:  "0",
+			expectedRetryAfter: "30",
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitQuota{Limit: 2, Period: time.Minute},
+	})
+	router := gin.New()
+	router.Use(RateLimitMiddleware(limiter))
+	router.GET("/employee/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/employee/1", nil))
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			assert.Equal(t, "2", recorder.Header().Get(RateLimitLimitHeader))
+			assert.Equal(t, tc.expectedRemaining, recorder.Header().Get(RateLimitRemainingHeader))
+			assert.Equal(t, tc.expectedRetryAfter, recorder.Header().Get(RetryAfterHeader))
+		})
+	}
+}
+
+func TestRateLimitClientIP(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		expectedStatus int
+	}{
+		{
+			name:           "Spoofed X-Forwarded-For shares the bucket of the peer",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "X-Forwarded-For of a trusted proxy",
+			trustedProxies: []string{"192.0.2.0/24"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, err := newRouter(&config.Config{TrustedProxies: tc.trustedProxies})
+			assert.NoError(t, err)
+			limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), config.RateLimitConfig{
+				Enabled: true,
+				Default: config.RateLimitQuota{Limit: 1, Period: time.Minute},
+			})
+			router.Use(RateLimitMiddleware(limiter))
+			router.GET("/employee/:id", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			// httptest requests come from 192.0.2.1
+			var recorder *httptest.ResponseRecorder
+			for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+				request := httptest.NewRequest(http.MethodGet, "/employee/1", nil)
+				request.Header.Set("X-Forwarded-For", forwardedFor)
+				recorder = httptest.NewRecorder()
+				router.ServeHTTP(recorder, request)
+			}
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/replica"
+)
+
+// ReadYourWritesHeader asks for reads that see the earlier writes of the
+// caller, e.g. X-Read-Your-Writes: true
+const ReadYourWritesHeader = "X-Read-Your-Writes"
+
+/*
+ReadYourWritesMiddleware : stores the caller of the request for the read
+replica routing, keyed like the rate limiter. The writes of every caller
+are remembered for DB.ReadYourWritesWindow, reads of callers sending
+ReadYourWritesHeader go to the primary within that window.
+*/
+func ReadYourWritesMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		readYourWrites, _ := strconv.ParseBool(c.GetHeader(ReadYourWritesHeader))
+		c.Request = c.Request.WithContext(replica.WithCaller(c.Request.Context(), rateLimitClient(c), readYourWrites))
+		c.Next()
+	}
+}
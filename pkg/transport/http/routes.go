@@ -4,40 +4,104 @@ import (
 	"context"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
 	"gorm.io/gorm"
 
+	asyncjobep "github.com/jainabhishek5986/employee-records/pkg/endpoint/asyncjob"
 	ep "github.com/jainabhishek5986/employee-records/pkg/endpoint/employee"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
 	svc "github.com/jainabhishek5986/employee-records/pkg/services/employee"
 )
 
-func RegisterAPIRoutes(v1RoutesGroup *gin.RouterGroup, db *gorm.DB) {
+func RegisterAPIRoutes(v1RoutesGroup *gin.RouterGroup, db *gorm.DB, asyncJobs service.AsyncJobService) {
 
 	var (
-		service  = svc.NewService(db)
-		endpoint = ep.NewEndPoint(service)
+		endpoint         = ep.NewEndPoint(svc.NewService(db))
+		asyncJobEndpoint = asyncjobep.NewEndPoint(asyncJobs)
 	)
 
 	// Employee Endpoints
-	v1RoutesGroup.GET("/employee/:id", NewHTTPHandler(
+	v1RoutesGroup.GET("/employee/:id", RequireScope(auth.ScopeEmployeeRead), NewHTTPHandler(
 		endpoint.GetEmployeeByID, DecodeByIDRequest,
 		EncodeJSONResponse))
 
-	v1RoutesGroup.GET("/employee", NewHTTPHandler(
+	v1RoutesGroup.GET("/employee", RequireScope(auth.ScopeEmployeeRead), NewHTTPHandler(
 		endpoint.GetAllEmployee, DecodeAllRequest,
 		EncodeJSONResponse))
 
-	v1RoutesGroup.POST("/employee", NewHTTPHandler(
+	v1RoutesGroup.POST("/employee", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
 		endpoint.CreateEmployee, DecodeEmployeesPOSTRequest,
 		EncodeJSONResponse))
 
-	v1RoutesGroup.PUT("/employee", NewHTTPHandler(
+	v1RoutesGroup.PUT("/employee", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
 		endpoint.UpdateEmployeeByID, DecodeEmployeePUTRequest,
 		EncodeJSONResponse))
 
-	v1RoutesGroup.DELETE("/employee/:id", NewHTTPHandler(
+	v1RoutesGroup.DELETE("/employee/:id", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
 		endpoint.DeleteEmployeeByID, DecodeByIDRequest,
 		EncodeJSONResponse))
 
+	// Bulk Endpoints, one transaction for every selected employee
+	v1RoutesGroup.PATCH("/employee", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.BulkUpdate, DecodeEmployeesPATCHRequest,
+		EncodeJSONResponse))
+
+	v1RoutesGroup.DELETE("/employee", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.BulkDelete, DecodeEmployeesDELETERequest,
+		EncodeJSONResponse))
+
+	// Employment lifecycle Endpoints, one per transition
+	v1RoutesGroup.POST("/employee/:id/onboard", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.Onboard, DecodeEmploymentTransitionRequest(employment.ActionOnboard),
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/:id/activate", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.Activate, DecodeEmploymentTransitionRequest(employment.ActionActivate),
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/:id/leave", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.Leave, DecodeEmploymentTransitionRequest(employment.ActionLeave),
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/:id/notice", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.Notice, DecodeEmploymentTransitionRequest(employment.ActionNotice),
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/:id/terminate", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.Terminate, DecodeEmploymentTransitionRequest(employment.ActionTerminate),
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/:id/rehire", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		endpoint.Rehire, DecodeEmploymentTransitionRequest(employment.ActionRehire),
+		EncodeJSONResponse))
+
+	// Asynchronous job Endpoints, the bulk variants answer 202 with the
+	// location of the job
+	v1RoutesGroup.POST("/employee/import", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		asyncJobEndpoint.ImportEmployees, DecodeEmployeesPOSTRequest,
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/export", RequireScope(auth.ScopeEmployeeRead), NewHTTPHandler(
+		asyncJobEndpoint.ExportEmployees, DecodeAllRequest,
+		EncodeJSONResponse))
+
+	v1RoutesGroup.POST("/employee/update", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		asyncJobEndpoint.UpdateEmployees, DecodeEmployeesPATCHRequest,
+		EncodeJSONResponse))
+
+	v1RoutesGroup.GET("/jobs/:id", RequireScope(auth.ScopeEmployeeRead), NewHTTPHandler(
+		asyncJobEndpoint.GetJob, DecodeAsyncJobIDRequest,
+		EncodeJSONResponse))
+
+	v1RoutesGroup.DELETE("/jobs/:id", RequireScope(auth.ScopeEmployeeWrite), NewHTTPHandler(
+		asyncJobEndpoint.CancelJob, DecodeAsyncJobIDRequest,
+		EncodeJSONResponse))
+
+	v1RoutesGroup.GET("/jobs/:id/download", RequireScope(auth.ScopeEmployeeRead), NewHTTPHandler(
+		asyncJobEndpoint.DownloadJobOutput, DecodeAsyncJobIDRequest,
+		EncodeFileResponse))
+
 	zaplogger.Info(context.Background(), "v1.0 routes injected")
 }
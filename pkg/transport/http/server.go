@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/jainabhishek5986/employee-records/pkg/lifecycle"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+// Server : an HTTP server run by the lifecycle manager
+type Server struct {
+	name string
+	srv  *http.Server
+	// wrap is applied to the bound listener, e.g. for TLS
+	wrap func(ctx context.Context, listener net.Listener) (net.Listener, error)
+	// started runs once the listener is bound
+	started func()
+	// draining runs before the server stops accepting connections
+	draining func(ctx context.Context)
+}
+
+/*
+Start : binds the port and serves in the background. A taken port fails the
+start right away, errors of the running server are passed to fail.
+
+Parameters
+----------
+ctx: lifetime of the background work of the server
+fail: reports a server that stopped serving
+*/
+func (s *Server) Start(ctx context.Context, fail func(error)) error {
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+	if s.wrap != nil {
+		wrapped, err := s.wrap(ctx, listener)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = wrapped
+	}
+
+	go func() {
+		zaplogger.Info(ctx, "Starting server", zap.String("server", s.name), zap.String("address", s.srv.Addr))
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fail(fmt.Errorf("%s server: %w", s.name, err))
+		}
+	}()
+
+	if s.started != nil {
+		s.started()
+	}
+	return nil
+}
+
+/*
+Stop : stops accepting connections and waits for the requests in flight
+until ctx expires
+
+Parameters
+----------
+ctx: shutdown deadline
+*/
+func (s *Server) Stop(ctx context.Context) error {
+	if s.draining != nil {
+		s.draining(ctx)
+	}
+	zaplogger.Info(ctx, "Shutting down server", zap.String("server", s.name))
+	return s.srv.Shutdown(ctx)
+}
+
+// Hook returns the lifecycle hook of the server
+func (s *Server) Hook(fail func(error)) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: s.name + " server",
+		Start: func(ctx context.Context) error {
+			return s.Start(ctx, fail)
+		},
+		Stop: s.Stop,
+	}
+}
@@ -2,18 +2,22 @@ package http
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"crypto/tls"
+	"net"
 	"net/http"
-	"sync"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/jainabhishek5986/employee-records/pkg/errs"
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/health"
+	"github.com/jainabhishek5986/employee-records/pkg/metrics"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/jainabhishek5986/employee-records/pkg/ratelimit"
+	service "github.com/jainabhishek5986/employee-records/pkg/services"
+	apikeysvc "github.com/jainabhishek5986/employee-records/pkg/services/apikey"
+	"github.com/jainabhishek5986/employee-records/pkg/tlsutil"
+	"github.com/jainabhishek5986/employee-records/pkg/tracing"
 	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
-	"github.com/lestrrat-go/backoff"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
@@ -21,123 +25,183 @@ import (
 )
 
 /*
-StartAPIServer : start the API server and call of functions like
+NewAPIServer : builds the API server with
 1- middleware for global logging
-2- Setting up cors
+2- Setting up the cors policies of the route groups
 3- Routes function to get all the endpoint
 4- Register API routes
+The server is started and stopped by the lifecycle manager.
 
 Parameters
 ----------
 ctx: Global context
 config: Config object
-wg: Wait group object
 db: Database connection
-logger: Global logging object
+jobs: background job scheduler listed and triggered on /admin/jobs
 */
-func StartAPIServer(ctx context.Context, conf *config.Config,
-	wg *sync.WaitGroup, db *gorm.DB) error {
-
-	wg.Add(1)
-	defer wg.Done()
-
+func NewAPIServer(ctx context.Context, conf *config.Config, db *gorm.DB, jobs service.JobService,
+	asyncJobs service.AsyncJobService) (*Server, error) {
 	// Set gin to release mode
 	gin.SetMode(gin.ReleaseMode)
 
 	zaplogger.Info(ctx, "Setting up http handler")
-	router := gin.Default()
+	router, err := newRouter(conf)
+	if err != nil {
+		return nil, err
+	}
 
-	// Recovery middleware recovers from any panics and writes a 500
-	// if there was one
-	router.Use(gin.Recovery())
+	// Correlate log lines and error responses of a request
+	router.Use(RequestIDMiddleware())
+
+	// Server span of the request with W3C traceparent propagation
+	router.Use(tracing.GinMiddleware())
+
+	// Negotiate the response language from Accept-Language
+	router.Use(LocaleMiddleware())
 
-	errChan := make(chan error)
+	// RED metrics labelled by route template
+	router.Use(metrics.GinMiddleware())
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Probes live outside /api/v1 and never touch a table lock
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("db", health.DBCheck(db))
+	checker.AddReadinessCheck("logger", health.LoggerCheck())
+	checker.AddReadinessCheck("migrations", health.MigrationsCheck(db, models.All()...))
+	checker.RegisterRoutes(router)
+
+	// API documentation generated from the route table, public like the
+	// probes, so it is served outside the authenticated and rate limited
+	// group
+	openAPIDocument, err := BuildOpenAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+	RegisterDocsRoutes(router.Group("/api/v1"), openAPIDocument)
 
 	// All the router groups
 	v1RoutesGroup := router.Group("/api/v1")
 
-	// Cors config for rest of the routes
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowAllOrigins = true
-	v1RoutesGroup.Use(cors.New(corsConfig))
+	// Cross origin policy of the public API
+	apiCORS, err := NewCORS("api", conf.CORS.API)
+	if err != nil {
+		return nil, err
+	}
+	v1RoutesGroup.Use(apiCORS.Middleware())
+	v1RoutesGroup.OPTIONS("/*path", PreflightHandler)
 
-	// Registering API Routes
-	RegisterAPIRoutes(v1RoutesGroup, db)
+	// Bounded bodies for the strict JSON decoders
+	v1RoutesGroup.Use(BodyLimitMiddleware(conf.BodyLimit))
 
-	// HTTP server instance
-	srv := &http.Server{
-		Addr:    ":" + conf.Port,
-		Handler: router,
-	}
+	// Identity of client certificate and API key callers, before the rate
+	// limiter keys on it
+	v1RoutesGroup.Use(ClientCertMiddleware(conf.TLS.ClientScopes))
+	v1RoutesGroup.Use(AuthMiddleware(apikeysvc.NewService(db), conf.Auth.Required))
 
-	// channel to signal server process exit
-	done := make(chan struct{})
+	// Tenant of the credentials or subdomain, repositories only see its rows
+	v1RoutesGroup.Use(TenantMiddleware())
 
-	go func() {
-		zaplogger.Info(ctx, "Starting server on port", zap.String("port", conf.Port))
-		// service connections
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			zaplogger.Error(ctx, "listen", zap.Error(err))
-			errChan <- err
-		}
-	}()
+	// Caller of the request, pinned to the primary after its writes if it
+	// asks to read them
+	v1RoutesGroup.Use(ReadYourWritesMiddleware())
 
-	select {
-	case <-ctx.Done():
-		const GracefulTimeout = 20000 * time.Millisecond
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), GracefulTimeout)
+	// Token bucket quotas per client and route
+	rateLimitStore, err := ratelimit.NewStore(conf.RateLimit.Store, db)
+	if err != nil {
+		return nil, err
+	}
+	limiter := ratelimit.NewLimiter(rateLimitStore, conf.RateLimit)
+	config.Subscribe("RateLimit", func(ctx context.Context, cfg *config.Config) error {
+		limiter.SetConfig(cfg.RateLimit)
+		return nil
+	})
+	v1RoutesGroup.Use(RateLimitMiddleware(limiter))
 
-		defer cancel()
-		zaplogger.Info(shutdownCtx, "Caller has requested graceful shutdown. shutting down the server")
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			zaplogger.Error(shutdownCtx, fmt.Sprintf("Server Shutdown: error -%s", err.Error()))
+	// Registering API Routes
+	RegisterAPIRoutes(v1RoutesGroup, db, asyncJobs)
+
+	// Operational routes behind the admin token
+	adminRoutesGroup := router.Group("/admin")
+	adminCORS, err := NewCORS("admin", conf.CORS.Admin)
+	if err != nil {
+		return nil, err
+	}
+	// preflights carry no token, answer them before the admin auth
+	adminRoutesGroup.Use(adminCORS.Middleware())
+	adminRoutesGroup.OPTIONS("/*path", PreflightHandler)
+	config.Subscribe("CORS", func(ctx context.Context, cfg *config.Config) error {
+		// policies are checked before one is swapped
+		if _, err := NewCORS("api", cfg.CORS.API); err != nil {
+			return err
 		}
-		return nil
-	case err := <-errChan:
-		return err
-	case <-done:
-		return nil
+		if _, err := NewCORS("admin", cfg.CORS.Admin); err != nil {
+			return err
+		}
+		if err := apiCORS.SetConfig(cfg.CORS.API); err != nil {
+			return err
+		}
+		return adminCORS.SetConfig(cfg.CORS.Admin)
+	})
+	// operators manage the API keys of every tenant
+	adminRoutesGroup.Use(AdminAuthMiddleware(conf.Admin.Token), BodyLimitMiddleware(conf.BodyLimit),
+		AllTenantsMiddleware())
+	RegisterAdminRoutes(adminRoutesGroup, db, jobs)
+
+	server := &Server{
+		name: "api",
+		srv: &http.Server{
+			Addr:    ":" + conf.Port,
+			Handler: router,
+		},
+		// the listener is bound, the startup probe can pass
+		started: checker.MarkStarted,
+		// fail readiness first and give the load balancer time to notice
+		// before we stop accepting connections
+		draining: func(ctx context.Context) {
+			checker.MarkShuttingDown()
+			select {
+			case <-time.After(global.ReadinessDrainDelay):
+			case <-ctx.Done():
+			}
+		},
 	}
-}
-
-/*
-Setup function just set up the process to start the API server
 
-Parameters
-----------
-config: Config object
-ctx: Global context
-wg: Wait group object
-db: DB object
-*/
-func Setup(ctx context.Context, conf *config.Config, wg *sync.WaitGroup, db *gorm.DB) error {
-	zaplogger.Info(ctx, "Starting API server")
-
-	var policy = backoff.NewExponential(
-		backoff.WithInterval(global.FiveHundred*time.Millisecond), // base interval
-		backoff.WithJitterFactor(global.PointZeroFive),            // 5% jitter
-		backoff.WithMaxRetries(global.MaxAPIServerStartAttempts),  // If not specified, default number of retries is 10
-	)
-
-	b, cancel := policy.Start(context.Background())
-	defer cancel()
-
-	for backoff.Continue(b) {
-		// check for context
-		select {
-		case <-ctx.Done():
-			zaplogger.Debug(ctx, "Context cancelled. Stopping sink proxy")
-			return nil
-		default:
-			err := StartAPIServer(ctx, conf, wg, db)
+	// HTTPS with certificates reloaded from disk on rotation
+	if conf.TLS.Enabled {
+		server.wrap = func(ctx context.Context, listener net.Listener) (net.Listener, error) {
+			reloader, err := tlsutil.NewReloader(conf.TLS)
 			if err != nil {
-				zaplogger.Error(ctx, errs.APIServerStartError, zap.Error(err))
-			} else {
-				return nil
+				return nil, err
 			}
+			tlsConfig, err := reloader.ServerConfig()
+			if err != nil {
+				return nil, err
+			}
+			go reloader.Watch(ctx)
+			zaplogger.Info(ctx, "TLS enabled",
+				zap.String("min_version", conf.TLS.MinVersion),
+				zap.String("client_auth", conf.TLS.ClientAuth))
+			return tls.NewListener(listener, tlsConfig), nil
 		}
 	}
 
-	// in the case of retry attempts to exceed, return with error
-	return errors.New("failed to start server after maximum retries")
+	return server, nil
+}
+
+// newRouter builds the gin engine of the API server. The client IP, which
+// keys the rate limits of anonymous callers, is only read from the
+// X-Forwarded-For of conf.TrustedProxies, other clients could pick it.
+func newRouter(conf *config.Config) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(conf.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	// Recovery middleware recovers from any panics and writes a 500
+	// if there was one
+	router.Use(gin.Recovery())
+
+	// Let handlers read values that middlewares store in the request context
+	router.ContextWithFallback = true
+	return router, nil
 }
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+)
+
+/*
+decodeStrictJSON : decodes a single JSON document from body into v. Unlike
+ShouldBindJSON it rejects keys v does not define, repeated keys and data
+after the document, reporting the JSON pointer of the offending key. The
+errors are mapped to response codes by errs.ErrorReqHandler.
+
+Parameters
+----------
+body: request body, limited by BodyLimitMiddleware
+v: pointer to the request struct
+*/
+func decodeStrictJSON(body io.Reader, v interface{}) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := checkJSONValue(decoder, reflect.TypeOf(v), ""); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return &errs.TrailingDataError{Offset: decoder.InputOffset()}
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// checkJSONValue walks the next value of decoder against the type it is
+// decoded into. A nil type accepts any keys.
+func checkJSONValue(decoder *json.Decoder, t reflect.Type, pointer string) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	token, err := decoder.Token()
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		return checkJSONObject(decoder, t, pointer)
+	case json.Delim('['):
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i := 0; decoder.More(); i++ {
+			if err := checkJSONValue(decoder, elem, pointer+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+
+	// scalars are checked by json.Unmarshal
+	return nil
+}
+
+func checkJSONObject(decoder *json.Decoder, t reflect.Type, pointer string) error {
+	var fields map[string]reflect.Type
+	if t != nil && t.Kind() == reflect.Struct {
+		fields = jsonFields(t)
+	}
+
+	seen := make(map[string]bool)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		keyPointer := pointer + "/" + escapeJSONPointer(key)
+
+		// encoding/json matches struct fields case insensitively, so Name
+		// and name set the same field
+		name := key
+		if fields != nil {
+			name = strings.ToLower(key)
+		}
+		if seen[name] {
+			return &errs.DuplicateKeyError{Pointer: keyPointer}
+		}
+		seen[name] = true
+
+		var child reflect.Type
+		switch {
+		case fields != nil:
+			fieldType, ok := fields[name]
+			if !ok {
+				return &errs.UnknownFieldError{Pointer: keyPointer}
+			}
+			child = fieldType
+		case t != nil && t.Kind() == reflect.Map:
+			child = t.Elem()
+		}
+
+		if err := checkJSONValue(decoder, child, keyPointer); err != nil {
+			return err
+		}
+	}
+
+	_, err := decoder.Token()
+	return err
+}
+
+// jsonFields returns the types of the fields of a struct keyed by lower
+// cased json name, including the promoted fields of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, fieldType := range jsonFields(embedded) {
+					fields[key] = fieldType
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+	return fields
+}
+
+// escapeJSONPointer escapes a key as a JSON pointer reference token
+func escapeJSONPointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeStrictJSON(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedKey    string
+		expectedDetail string
+	}{
+		{
+			name: "Valid payload",
+			body: `{"employees": [{"name": "Jane Doe", "position": "Manager", "salary": 100}]}`,
+		},
+		{
+			name:           "Unknown nested field",
+			body:           `{"employees": [{"name": "Jane Doe"}, {"nmae": "John Doe"}]}`,
+			expectedKey:    "UnknownField",
+			expectedDetail: `"/employees/1/nmae"`,
+		},
+		{
+			name:           "Duplicate key",
+			body:           `{"employees": [{"name": "Jane Doe", "name": "John Doe"}]}`,
+			expectedKey:    "DuplicateKey",
+			expectedDetail: `"/employees/0/name"`,
+		},
+		{
+			name:           "Duplicate key differing in case",
+			body:           `{"employees": [], "Employees": []}`,
+			expectedKey:    "DuplicateKey",
+			expectedDetail: `"/Employees"`,
+		},
+		{
+			name:        "Trailing garbage",
+			body:        `{"employees": []} {"employees": []}`,
+			expectedKey: "TrailingData",
+		},
+		{
+			name:        "Syntax error",
+			body:        `{"employees": [}`,
+			expectedKey: "SyntaxError",
+		},
+		{
+			name:        "Truncated document",
+			body:        `{"employees": [`,
+			expectedKey: "SyntaxError",
+		},
+		{
+			name:        "Empty body",
+			body:        `  `,
+			expectedKey: "EmptyBody",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var request global.DecodeEmployeesPOSTRequest
+			err := decodeStrictJSON(strings.NewReader(tc.body), &request)
+			if tc.expectedKey == "" {
+				assert.NoError(t, err)
+				assert.Len(t, request.Employees, 1)
+				return
+			}
+
+			httpErr, ok := errs.ErrorReqHandler(err).(*errs.HTTPErr)
+			assert.True(t, ok)
+			message := httpErr.Ms[0].(errs.ErrMessage)
+			assert.Equal(t, tc.expectedKey, message.Key)
+			assert.Contains(t, message.Detail, tc.expectedDetail)
+		})
+	}
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{
+			name:           "Body within the route limit",
+			method:         http.MethodPost,
+			body:           `{"level": "debug", "output": "console"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Declared length above the default limit",
+			method:         http.MethodPut,
+			body:           `{"level": "debug", "output": "console"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "Chunked body above the default limit",
+			method:         http.MethodPut,
+			body:           `{"level": "debug", "output": "console"}`,
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(config.BodyLimitConfig{
+		MaxBytes: 16,
+		Routes:   map[string]int64{"post /limited": 1024},
+	}))
+	handler := func(c *gin.Context) {
+		var request global.DecodeLogLevelPUTRequest
+		if err := decodeStrictJSON(c.Request.Body, &request); err != nil {
+			localizedErrorEncoder(c, errs.ErrorReqHandler(err), c.Writer)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	router.POST("/limited", handler)
+	router.PUT("/limited", handler)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(tc.method, "/limited", strings.NewReader(tc.body))
+			if tc.chunked {
+				request.ContentLength = -1
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/errs"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/jainabhishek5986/employee-records/pkg/zaplogger"
+	"go.uber.org/zap"
+)
+
+/*
+TenantMiddleware : resolves the tenant of the request and stores it in the
+request context, every repository query is scoped to it. The tenant comes
+from the credentials, anonymous callers and credentials without a tenant
+are rejected. A subdomain of Tenancy.BaseDomain naming another tenant than
+the credentials is rejected with 403, unknown tenants with 404. While
+tenancy is disabled every request acts for the default tenant.
+*/
+func TenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := resolveTenant(c)
+		if err != nil {
+			zaplogger.Warn(c, errs.TenantResolveError, zap.Error(err), zap.String("host", c.Request.Host))
+			localizedErrorEncoder(c, err, c.Writer)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AllTenantsMiddleware lets the handlers of the route group act for every
+// tenant, e.g. the admin routes
+func AllTenantsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.AllTenants(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// resolveTenant returns the tenant of the credentials, the host only has to
+// agree with it
+func resolveTenant(c *gin.Context) (string, error) {
+	if !tenant.Enabled() {
+		return tenant.Default(), nil
+	}
+
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		return "", errs.UnAuthorisedErr(errs.AuthenticationRequired)
+	}
+	// e.g. certificates not listed in any Tenants.<id>.ClientSubjects
+	if identity.Tenant == "" {
+		return "", errs.ForbiddenErr(errs.TenantRequiredError)
+	}
+	id := identity.Tenant
+	if fromHost, hasHost := tenant.FromHost(c.Request.Host); hasHost && fromHost != id {
+		return "", errs.ForbiddenErr(errs.TenantMismatchError)
+	}
+
+	if !tenant.Known(id) {
+		return "", errs.NotFoundErr(errs.TenantNotFoundError)
+	}
+	return id, nil
+}
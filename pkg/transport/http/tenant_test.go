@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jainabhishek5986/employee-records/config"
+	"github.com/jainabhishek5986/employee-records/pkg/auth"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
+	"github.com/jainabhishek5986/employee-records/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	tenancy := config.TenancyConfig{
+		Enabled:       true,
+		DefaultTenant: "default",
+		BaseDomain:    "example.com",
+		Tenants:       map[string]config.TenantConfig{"acme": {}, "globex": {}},
+	}
+	t.Cleanup(func() { tenant.Configure(config.TenancyConfig{DefaultTenant: "default"}) })
+
+	apiKey := func(id string) *auth.Identity {
+		return &auth.Identity{Subject: "apikey:1", Method: auth.MethodAPIKey, Tenant: id}
+	}
+
+	testCases := []struct {
+		name           string
+		disabled       bool
+		host           string
+		identity       *auth.Identity
+		expectedStatus int
+		expectedTenant string
+	}{
+		{
+			name:           "Tenancy disabled",
+			disabled:       true,
+			host:           "acme.example.com",
+			expectedStatus: http.StatusOK,
+			expectedTenant: "default",
+		},
+		{
+			name:           "Tenant of the credentials",
+			host:           "api.internal",
+			identity:       apiKey("acme"),
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			name:           "Subdomain of the credentials",
+			host:           "Globex.example.com:9876",
+			identity:       apiKey("globex"),
+			expectedStatus: http.StatusOK,
+			expectedTenant: "globex",
+		},
+		{
+			name:           "Subdomain without credentials",
+			host:           "globex.example.com",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Subdomain of another tenant",
+			host:           "globex.example.com",
+			identity:       apiKey("acme"),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Certificate without a tenant",
+			host:           "globex.example.com",
+			identity:       &auth.Identity{Subject: "mtls:CN=payroll", Method: auth.MethodMTLS},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Unknown tenant",
+			host:           "example.com",
+			identity:       apiKey("initech"),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tenancy.Enabled = !tc.disabled
+			tenant.Configure(tenancy)
+
+			router := gin.New()
+			router.ContextWithFallback = true
+			router.Use(func(c *gin.Context) {
+				if tc.identity != nil {
+					c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), *tc.identity))
+				}
+			})
+			router.Use(TenantMiddleware())
+			router.GET("/employee", func(c *gin.Context) {
+				c.String(http.StatusOK, reqctx.TenantID(c))
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/employee", nil)
+			request.Host = tc.host
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, tc.expectedTenant, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/hi"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+
+	"github.com/jainabhishek5986/employee-records/pkg/i18n"
+)
+
+// Translator holds one validation translator per supported locale. It is
+// built once together with Validate.
+var Translator *ut.UniversalTranslator
+
+// defaultTranslationTag is the key used when a tag has no translation
+const defaultTranslationTag = "default"
+
+// upstreamTranslations are the validator translation packages available for
+// our locales. Locales missing here rely on customTranslations only.
+var upstreamTranslations = map[string]func(*validator.Validate, ut.Translator) error{
+	"en": en_translations.RegisterDefaultTranslations,
+	"es": es_translations.RegisterDefaultTranslations,
+	"fr": fr_translations.RegisterDefaultTranslations,
+}
+
+// customTranslations holds the messages for our own validators and for the
+// locales that have no upstream translation package. {0} is the field name
+// and {1} the tag parameter.
+var customTranslations = map[string]map[string]string{
+	"en": {
+		defaultTranslationTag: "Field validation for '{0}' failed on the '{1}' tag",
+		"required":            "{0} is a required field",
+		"trimspace":           "{0} cannot be just spaces",
+		"employee_name":       "{0} must have a valid length and contain only letters, spaces, apostrophes, dots or hyphens",
+		"position":            "{0} must be one of the allowed positions",
+		"salary":              "{0} is outside the allowed range for the position",
+	},
+	"de": {
+		defaultTranslationTag: "Die Validierung des Feldes '{0}' ist an der Regel '{1}' gescheitert",
+		"required":            "{0} ist ein Pflichtfeld",
+		"trimspace":           "{0} darf nicht nur aus Leerzeichen bestehen",
+		"min":                 "{0} muss mindestens {1} sein",
+		"max":                 "{0} darf höchstens {1} sein",
+		"gt":                  "{0} muss größer als {1} sein",
+		"gte":                 "{0} muss größer oder gleich {1} sein",
+		"lt":                  "{0} muss kleiner als {1} sein",
+		"lte":                 "{0} muss kleiner oder gleich {1} sein",
+		"oneof":               "{0} muss einer der folgenden Werte sein: [{1}]",
+		"employee_name":       "{0} muss eine gültige Länge haben und darf nur Buchstaben, Leerzeichen, Apostrophe, Punkte oder Bindestriche enthalten",
+		"position":            "{0} muss eine der zulässigen Positionen sein",
+		"salary":              "{0} liegt außerhalb des zulässigen Bereichs für die Position",
+	},
+	"fr": {
+		defaultTranslationTag: "La validation du champ '{0}' a échoué sur la règle '{1}'",
+		"trimspace":           "{0} ne peut pas contenir uniquement des espaces",
+		"employee_name":       "{0} doit avoir une longueur valide et ne contenir que des lettres, espaces, apostrophes, points ou tirets",
+		"position":            "{0} doit être l'un des postes autorisés",
+		"salary":              "{0} est en dehors de la plage autorisée pour le poste",
+	},
+	"es": {
+		defaultTranslationTag: "La validación del campo '{0}' falló en la regla '{1}'",
+		"trimspace":           "{0} no puede contener solo espacios",
+		"employee_name":       "{0} debe tener una longitud válida y contener solo letras, espacios, apóstrofos, puntos o guiones",
+		"position":            "{0} debe ser uno de los puestos permitidos",
+		"salary":              "{0} está fuera del rango permitido para el puesto",
+	},
+	"hi": {
+		defaultTranslationTag: "'{0}' फ़ील्ड का सत्यापन '{1}' नियम पर विफल रहा",
+		"required":            "{0} एक आवश्यक फ़ील्ड है",
+		"trimspace":           "{0} केवल रिक्त स्थान नहीं हो सकता",
+		"min":                 "{0} कम से कम {1} होना चाहिए",
+		"max":                 "{0} अधिकतम {1} हो सकता है",
+		"gt":                  "{0} {1} से बड़ा होना चाहिए",
+		"gte":                 "{0} {1} या उससे बड़ा होना चाहिए",
+		"lt":                  "{0} {1} से छोटा होना चाहिए",
+		"lte":                 "{0} {1} या उससे छोटा होना चाहिए",
+		"oneof":               "{0} इनमें से एक होना चाहिए: [{1}]",
+		"employee_name":       "{0} की लंबाई मान्य होनी चाहिए और इसमें केवल अक्षर, रिक्त स्थान, एपॉस्ट्रॉफ़ी, बिंदु या हाइफ़न हो सकते हैं",
+		"position":            "{0} अनुमत पदों में से एक होना चाहिए",
+		"salary":              "{0} पद के लिए अनुमत सीमा से बाहर है",
+	},
+}
+
+// registerTranslations builds the universal translator for every supported
+// locale and registers the upstream and custom translations on validate
+func registerTranslations(validate *validator.Validate) (*ut.UniversalTranslator, error) {
+	fallback := en.New()
+	uni := ut.New(fallback, fallback, de.New(), es.New(), fr.New(), hi.New())
+
+	for _, locale := range i18n.SupportedLocales {
+		trans, found := uni.GetTranslator(locale)
+		if !found {
+			return nil, fmt.Errorf("no translator found for locale %q", locale)
+		}
+
+		if register, ok := upstreamTranslations[locale]; ok {
+			if err := register(validate, trans); err != nil {
+				return nil, err
+			}
+		}
+
+		for tag, message := range customTranslations[locale] {
+			if err := registerTranslation(validate, trans, tag, message); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return uni, nil
+}
+
+func registerTranslation(validate *validator.Validate, trans ut.Translator,
+	tag string, message string) error {
+
+	if tag == defaultTranslationTag {
+		return trans.Add(tag, message, true)
+	}
+
+	return validate.RegisterTranslation(tag, trans,
+		func(t ut.Translator) error {
+			return t.Add(tag, message, true)
+		},
+		func(t ut.Translator, fe validator.FieldError) string {
+			msg, err := t.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		})
+}
+
+// translatorFor returns the translator for the locale negotiated for the
+// request, falling back to english
+func translatorFor(ctx context.Context) ut.Translator {
+	trans, _ := Translator.FindTranslator(i18n.FromContext(ctx), i18n.DefaultLocale)
+	return trans
+}
+
+// translateFieldError translates a single validation error, using the
+// locale's generic message when the tag has no dedicated translation
+func translateFieldError(trans ut.Translator, e validator.FieldError) string {
+	msg := e.Translate(trans)
+	if msg != e.Error() {
+		return msg
+	}
+
+	if generic, err := trans.T(defaultTranslationTag, e.Field(), e.Tag()); err == nil {
+		return generic
+	}
+	return msg
+}
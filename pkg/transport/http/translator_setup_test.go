@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{
+			name:           "English by default",
+			acceptLanguage: "",
+			expected:       "name is a required field",
+		},
+		{
+			name:           "German from Accept-Language",
+			acceptLanguage: "de-DE,de;q=0.9,en;q=0.8",
+			expected:       "name ist ein Pflichtfeld",
+		},
+		{
+			name:           "French from Accept-Language",
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			expected:       "name est un champ obligatoire",
+		},
+		{
+			name:           "Hindi from Accept-Language",
+			acceptLanguage: "hi",
+			expected:       "name एक आवश्यक फ़ील्ड है",
+		},
+		{
+			name:           "Unsupported locale falls back to English",
+			acceptLanguage: "ja",
+			expected:       "name is a required field",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := i18n.WithLocale(context.Background(), i18n.Negotiate(tc.acceptLanguage))
+			err := Validate.Struct(global.DecodeEmployee{Position: "Engineer", Salary: 1})
+
+			messages, internalError := translateError(ctx, err)
+			assert.NoError(t, internalError)
+			assert.Equal(t, tc.expected, messages["name"])
+		})
+	}
+}
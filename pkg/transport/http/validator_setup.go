@@ -1,25 +1,78 @@
 package http
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/jainabhishek5986/employee-records/pkg/validation"
 )
 
 var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New()
-	err := Validate.RegisterValidation("trimspace", trimSpaceValidator)
+
+	// Report field errors with the json names clients actually send
+	Validate.RegisterTagNameFunc(jsonTagName)
+
+	validations := map[string]validator.Func{
+		"trimspace":     trimSpaceValidator,
+		"employee_name": employeeNameValidator,
+		"position":      positionValidator,
+		"salary":        salaryValidator,
+	}
+	for tag, fn := range validations {
+		if err := Validate.RegisterValidation(tag, fn); err != nil {
+			// Slack Alert
+			return
+		}
+	}
+
+	var err error
+
+	Translator, err = registerTranslations(Validate)
 	if err != nil {
 		// Slack Alert
 		return
 	}
 }
 
+func jsonTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" || name == "" {
+		return field.Name
+	}
+	return name
+}
+
 func trimSpaceValidator(fl validator.FieldLevel) bool {
 	if fl.Field().String() == "" {
 		return true
 	}
 	return strings.TrimSpace(fl.Field().String()) != ""
 }
+
+// employeeNameValidator applies the configured name length and character rules
+func employeeNameValidator(fl validator.FieldLevel) bool {
+	return validation.ValidName(fl.Field().String())
+}
+
+// positionValidator checks the position against the controlled vocabulary
+func positionValidator(fl validator.FieldLevel) bool {
+	return validation.ValidPosition(fl.Field().String())
+}
+
+// salaryValidator checks the salary against the range of the position given
+// in the same payload. Without a position the widest range applies, the
+// service layer then checks against the stored position.
+func salaryValidator(fl validator.FieldLevel) bool {
+	position := ""
+	if field := reflect.Indirect(fl.Parent()).FieldByName("Position"); field.IsValid() {
+		field = reflect.Indirect(field)
+		if field.IsValid() && field.Kind() == reflect.String {
+			position = field.String()
+		}
+	}
+	return validation.ValidSalary(position, fl.Field().Float())
+}
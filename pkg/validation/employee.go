@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/jainabhishek5986/employee-records/pkg/employment"
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+)
+
+// CheckEmployee returns the rule violations of a new employee keyed by the
+// json field name. An empty map means the employee is valid.
+func CheckEmployee(employee global.DecodeEmployee) map[string]string {
+	violations := make(map[string]string)
+
+	checkName(violations, employee.Name)
+	checkPosition(violations, employee.Position)
+	checkSalary(violations, employee.Position, employee.Salary)
+	for field, message := range employment.CheckNew(employee) {
+		violations[field] = message
+	}
+
+	return violations
+}
+
+// CheckEmployeeUpdate returns the rule violations of an update applied to
+// the stored employee keyed by the json field name
+func CheckEmployeeUpdate(request global.DecodeEmployeePUTRequest,
+	existing models.Employee) map[string]string {
+
+	violations := make(map[string]string)
+
+	if request.Name != nil {
+		checkName(violations, *request.Name)
+	}
+
+	position := existing.Position
+	if request.Position != nil {
+		position = *request.Position
+		checkPosition(violations, position)
+	}
+
+	salary := existing.Salary
+	if request.Salary != nil {
+		salary = *request.Salary
+	}
+	if request.Salary != nil || request.Position != nil {
+		checkSalary(violations, position, salary)
+	}
+
+	overrideReason := ""
+	if request.SalaryOverrideReason != nil {
+		overrideReason = *request.SalaryOverrideReason
+	}
+	if request.Salary != nil && !ValidSalaryChange(existing.Salary, salary, overrideReason) {
+		violations["salary"] = fmt.Sprintf(InvalidSalaryChangeMessage, "salary",
+			Rules().MaxSalaryChangePercent)
+	}
+
+	return violations
+}
+
+func checkName(violations map[string]string, name string) {
+	if !ValidName(name) {
+		rules := Rules()
+		violations["name"] = fmt.Sprintf(InvalidNameMessage, "name",
+			rules.NameMinLength, rules.NameMaxLength)
+	}
+}
+
+func checkPosition(violations map[string]string, position string) {
+	if !ValidPosition(position) {
+		violations["position"] = fmt.Sprintf(InvalidPositionMessage, "position")
+	}
+}
+
+func checkSalary(violations map[string]string, position string, salary float64) {
+	if !ValidSalary(position, salary) {
+		salaryRange := SalaryRangeFor(position)
+		violations["salary"] = fmt.Sprintf(InvalidSalaryMessage, "salary",
+			salaryRange.Min, salaryRange.Max)
+	}
+}
@@ -0,0 +1,95 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckEmployee(t *testing.T) {
+	testCases := []struct {
+		name           string
+		employee       global.DecodeEmployee
+		expectedFields []string
+	}{
+		{
+			name:     "Valid employee",
+			employee: global.DecodeEmployee{Name: "Anne-Marie O'Neil", Position: "engineer ", Salary: 70000},
+		},
+		{
+			name:           "Negative salary",
+			employee:       global.DecodeEmployee{Name: "Abhishek", Position: "Engineer", Salary: -5},
+			expectedFields: []string{"salary"},
+		},
+		{
+			name:           "Huge salary",
+			employee:       global.DecodeEmployee{Name: "Abhishek", Position: "Engineer", Salary: 1e308},
+			expectedFields: []string{"salary"},
+		},
+		{
+			name:           "Salary above position range",
+			employee:       global.DecodeEmployee{Name: "Abhishek", Position: "Intern", Salary: 200000},
+			expectedFields: []string{"salary"},
+		},
+		{
+			name:           "Invalid name and position",
+			employee:       global.DecodeEmployee{Name: "R2-D2", Position: "Wizard", Salary: 70000},
+			expectedFields: []string{"name", "position"},
+		},
+		{
+			name: "Created terminated without a valid hire date",
+			employee: global.DecodeEmployee{Name: "Abhishek", Position: "Engineer", Salary: 70000,
+				EmploymentStatus: "terminated", HireDate: "01/02/2024"},
+			expectedFields: []string{"employment_status", "hire_date"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := CheckEmployee(tc.employee)
+			fields := make([]string, 0, len(violations))
+			for field := range violations {
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestCheckEmployeeUpdate(t *testing.T) {
+	existing := models.Employee{ID: 1, Name: "Abhishek", Position: "Engineer", Salary: 70000}
+	raise := 140000.0
+	small := 75000.0
+	reason := "Promotion approved by HR"
+
+	testCases := []struct {
+		name        string
+		request     global.DecodeEmployeePUTRequest
+		expectValid bool
+	}{
+		{
+			name:        "Salary change within limit",
+			request:     global.DecodeEmployeePUTRequest{ID: 1, Salary: &small},
+			expectValid: true,
+		},
+		{
+			name:        "Salary change above limit",
+			request:     global.DecodeEmployeePUTRequest{ID: 1, Salary: &raise},
+			expectValid: false,
+		},
+		{
+			name:        "Salary change above limit with override reason",
+			request:     global.DecodeEmployeePUTRequest{ID: 1, Salary: &raise, SalaryOverrideReason: &reason},
+			expectValid: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations := CheckEmployeeUpdate(tc.request, existing)
+			assert.Equal(t, tc.expectValid, len(violations) == 0, violations)
+		})
+	}
+}
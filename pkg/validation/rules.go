@@ -0,0 +1,142 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/jainabhishek5986/employee-records/config"
+)
+
+// Rule messages, shared by the service layer checks
+const (
+	InvalidNameMessage         = "%s must be %d to %d characters long and contain only letters, spaces, apostrophes, dots or hyphens"
+	InvalidPositionMessage     = "%s must be one of the allowed positions"
+	InvalidSalaryMessage       = "%s must be between %.2f and %.2f for the position"
+	InvalidSalaryChangeMessage = "%s can change by at most %.2f%% unless salary_override_reason is supplied"
+)
+
+// compiledRules is the validation config with its derived lookups prepared
+// once, so the hot validation path does not recompile patterns
+type compiledRules struct {
+	config.ValidationConfig
+	namePattern *regexp.Regexp
+	positions   map[string]string
+	envelope    config.SalaryRange
+}
+
+var current atomic.Value
+
+func init() {
+	if err := SetRules(config.DefaultValidationConfig()); err != nil {
+		panic(err)
+	}
+}
+
+// SetRules atomically replaces the rules used by every validator
+func SetRules(cfg config.ValidationConfig) error {
+	rules := &compiledRules{
+		ValidationConfig: cfg,
+		positions:        make(map[string]string, len(cfg.Positions)),
+		envelope:         config.SalaryRange{Min: math.MaxFloat64, Max: 0},
+	}
+
+	if cfg.NamePattern != "" {
+		pattern, err := regexp.Compile(cfg.NamePattern)
+		if err != nil {
+			return fmt.Errorf("invalid Validation.NamePattern: %w", err)
+		}
+		rules.namePattern = pattern
+	}
+
+	for _, position := range cfg.Positions {
+		rules.positions[normalize(position)] = position
+	}
+
+	for _, salaryRange := range cfg.SalaryRanges {
+		rules.envelope.Min = math.Min(rules.envelope.Min, salaryRange.Min)
+		rules.envelope.Max = math.Max(rules.envelope.Max, salaryRange.Max)
+	}
+	if len(cfg.SalaryRanges) == 0 {
+		rules.envelope = config.SalaryRange{Min: 0, Max: math.MaxFloat64}
+	}
+
+	current.Store(rules)
+	return nil
+}
+
+// Rules returns the validation config currently in effect
+func Rules() config.ValidationConfig {
+	return load().ValidationConfig
+}
+
+func load() *compiledRules {
+	return current.Load().(*compiledRules)
+}
+
+func normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// ValidName reports whether name satisfies the length and character rules
+func ValidName(name string) bool {
+	rules := load()
+	length := utf8.RuneCountInString(strings.TrimSpace(name))
+	if rules.NameMinLength > 0 && length < rules.NameMinLength {
+		return false
+	}
+	if rules.NameMaxLength > 0 && length > rules.NameMaxLength {
+		return false
+	}
+	return rules.namePattern == nil || rules.namePattern.MatchString(strings.TrimSpace(name))
+}
+
+// ValidPosition reports whether position is part of the controlled
+// vocabulary. An empty vocabulary allows every position.
+func ValidPosition(position string) bool {
+	rules := load()
+	if len(rules.positions) == 0 {
+		return true
+	}
+	_, ok := rules.positions[normalize(position)]
+	return ok
+}
+
+// SalaryRangeFor returns the allowed salary range of a position. An empty
+// position yields the widest configured range.
+func SalaryRangeFor(position string) config.SalaryRange {
+	rules := load()
+	if strings.TrimSpace(position) == "" {
+		return rules.envelope
+	}
+	if salaryRange, ok := rules.SalaryRanges[normalize(position)]; ok {
+		return salaryRange
+	}
+	if salaryRange, ok := rules.SalaryRanges[config.DefaultSalaryRangeKey]; ok {
+		return salaryRange
+	}
+	return rules.envelope
+}
+
+// ValidSalary reports whether salary lies within the range of position
+func ValidSalary(position string, salary float64) bool {
+	if math.IsNaN(salary) || math.IsInf(salary, 0) {
+		return false
+	}
+	salaryRange := SalaryRangeFor(position)
+	return salary >= salaryRange.Min && salary <= salaryRange.Max
+}
+
+// ValidSalaryChange reports whether a salary update stays within the
+// configured percentage of the current salary. A non blank override reason
+// lifts the limit.
+func ValidSalaryChange(oldSalary float64, newSalary float64, overrideReason string) bool {
+	maxPercent := load().MaxSalaryChangePercent
+	if maxPercent <= 0 || oldSalary <= 0 || strings.TrimSpace(overrideReason) != "" {
+		return true
+	}
+	return math.Abs(newSalary-oldSalary)/oldSalary*100 <= maxPercent
+}
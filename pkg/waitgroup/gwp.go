@@ -1,6 +1,7 @@
 package waitgroup
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -24,6 +25,23 @@ func WaitWithTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
 	}
 }
 
+// WaitWithContext blocks until the WaitGroup counter is zero but unblocks once ctx is done.
+// Returns true if the wait group completes before ctx.
+func WaitWithContext(ctx context.Context, wg *sync.WaitGroup) bool {
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		wg.Wait()
+	}()
+
+	select {
+	case <-c:
+		return true // completed normally
+	case <-ctx.Done():
+		return false // deadline exceeded or cancelled
+	}
+}
+
 func Add(delta int) {
 	Gwg.Add(delta)
 }
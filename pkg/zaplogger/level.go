@@ -0,0 +1,54 @@
+package zaplogger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Outputs whose level can be changed at runtime
+const (
+	OutputConsole = "console"
+	OutputFile    = "file"
+)
+
+// SetLevel changes the level of an output at runtime, an empty output
+// changes both
+func SetLevel(output string, level string) error {
+	switch strings.ToLower(output) {
+	case OutputConsole:
+		return setLevel(consoleLevel, level)
+	case OutputFile:
+		return setLevel(fileLevel, level)
+	case "":
+		var l zapcore.Level
+		if err := l.Set(level); err != nil {
+			return err
+		}
+		consoleLevel.SetLevel(l)
+		fileLevel.SetLevel(l)
+		return nil
+	default:
+		return fmt.Errorf("unknown log output %q", output)
+	}
+}
+
+// Levels returns the current level of each output
+func Levels() map[string]string {
+	return map[string]string{
+		OutputConsole: consoleLevel.Level().String(),
+		OutputFile:    fileLevel.Level().String(),
+	}
+}
+
+func setLevel(atomicLevel zap.AtomicLevel, level string) error {
+	// string to level
+	var l zapcore.Level
+	if err := l.Set(level); err != nil {
+		return err
+	}
+	atomicLevel.SetLevel(l)
+	return nil
+}
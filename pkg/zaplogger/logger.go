@@ -2,110 +2,222 @@ package zaplogger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"github.com/jainabhishek5986/employee-records/pkg/global"
+	"github.com/jainabhishek5986/employee-records/pkg/reqctx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
-	logger        *zap.Logger
+	logger        atomic.Pointer[zap.Logger]
 	loggerChannel = make(chan *logItem, global.FiveThousand)
+	drainOnce     sync.Once
+
+	// levels of the console and file cores, changed at runtime by SetLevel
+	consoleLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	fileLevel    = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+	// fallbackLogger writes to stderr until the logger is configured
+	fallbackLogger = zap.New(zapcore.NewCore(newEncoder(true, false),
+		zapcore.Lock(os.Stderr), zapcore.DebugLevel))
 )
 
+// Options : logger settings, mirrors config.LogConfig
 type Options struct {
-	LogFileName string
-	MaxSize     int
-	MaxAge      int
-	MaxBackups  int
-	Level       string
+	EnableConsole     bool
+	ConsoleJSONFormat bool
+	ConsoleLevel      string
+	EnableFile        bool
+	FileJSONFormat    bool
+	FileLevel         string
+	FileLocation      string
+	MaxSize           int
+	MaxAge            int
+	MaxBackups        int
+	// OverflowPolicy is block, drop_oldest or drop_newest, empty blocks
+	OverflowPolicy string
+	// Development enables colored levels on a non JSON console and
+	// stack traces from warn level
+	Development bool
 }
 
-func InitLogger(fileName string) error {
-	options := Options{
-		LogFileName: fileName,
-		MaxSize:     global.Hundred,
-		MaxAge:      global.Ten,
-		MaxBackups:  global.One,
-		Level:       "Info",
-	}
-
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-	encoder := zapcore.NewJSONEncoder(encoderConfig)
-
-	logWriter := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   options.LogFileName,
-		MaxSize:    options.MaxSize,
-		MaxAge:     options.MaxAge,
-		MaxBackups: options.MaxBackups,
-		LocalTime:  false,
-		Compress:   false,
+/*
+InitLogger : starts the logger with console output only at info level. It
+is used before the config is loaded, Configure then applies the configured
+options, including the log file and its location.
+*/
+func InitLogger() error {
+	return Configure(Options{
+		EnableConsole:     true,
+		ConsoleJSONFormat: true,
+		ConsoleLevel:      "info",
 	})
+}
 
-	// string to level
-	var l zapcore.Level
-	if err := l.Set(options.Level); err != nil {
+/*
+Configure : builds the logger from options with separate console and file
+cores and levels, and swaps it in atomically. Entries already queued are
+written by the new logger.
+
+Parameters
+----------
+options: logger settings
+*/
+func Configure(options Options) error {
+	if err := SetOverflowPolicy(options.OverflowPolicy); err != nil {
 		return err
 	}
 
-	core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(zapcore.AddSync(os.Stdout), logWriter), zap.NewAtomicLevelAt(l))
-	logger = zap.New(core, zap.AddStacktrace(zap.ErrorLevel))
+	cores := make([]zapcore.Core, 0, 2)
+
+	if options.EnableConsole {
+		if err := setLevel(consoleLevel, options.ConsoleLevel); err != nil {
+			return err
+		}
+		encoder := newEncoder(options.ConsoleJSONFormat, options.Development)
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), consoleLevel))
+	}
 
-	go func() {
-		for log := range loggerChannel {
-			logByLevel(log.level, log.template, log.fields...)
+	if options.EnableFile {
+		if err := setLevel(fileLevel, options.FileLevel); err != nil {
+			return err
 		}
-	}()
+		logWriter := zapcore.AddSync(&lumberjack.Logger{
+			Filename:   options.FileLocation,
+			MaxSize:    options.MaxSize,
+			MaxAge:     options.MaxAge,
+			MaxBackups: options.MaxBackups,
+			LocalTime:  false,
+			Compress:   false,
+		})
+		encoder := newEncoder(options.FileJSONFormat, false)
+		cores = append(cores, zapcore.NewCore(encoder, logWriter, fileLevel))
+	}
 
-	Info(context.Background(), "Started Logger Instance")
+	zapOptions := []zap.Option{zap.AddStacktrace(zap.ErrorLevel)}
+	if options.Development {
+		zapOptions = []zap.Option{zap.Development(), zap.AddStacktrace(zap.WarnLevel)}
+	}
+	logger.Store(zap.New(zapcore.NewTee(cores...), zapOptions...))
+
+	drainOnce.Do(func() {
+		go drain()
+	})
+
+	Info(context.Background(), "Started Logger Instance",
+		zap.Bool("console", options.EnableConsole),
+		zap.Bool("file", options.EnableFile),
+		zap.String("overflow_policy", OverflowPolicy()),
+	)
 	return nil
 }
 
-type logItem struct {
-	template string
-	level    zapcore.Level
-	fields   []zapcore.Field
+func newEncoder(jsonFormat bool, development bool) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	if jsonFormat {
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	// human readable console output
+	if development {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	encoderConfig.EncodeDuration = zapcore.StringDurationEncoder
+	return zapcore.NewConsoleEncoder(encoderConfig)
 }
 
 func Error(ctx context.Context, template string, fields ...zapcore.Field) {
-	_, src, line, ok := runtime.Caller(1)
-	if ok {
-		fields = append(fields, zapcore.Field{Key: "caller", Type: zapcore.StringType, String: fmt.Sprintf("%s:%d", src, line)})
-	}
-	loggerChannel <- &logItem{level: zapcore.ErrorLevel, template: template, fields: fields}
+	enqueue(newItem(ctx, zapcore.ErrorLevel, template, fields))
 }
 
 func Info(ctx context.Context, template string, fields ...zapcore.Field) {
-	loggerChannel <- &logItem{level: zapcore.InfoLevel, template: template, fields: fields}
+	enqueue(newItem(ctx, zapcore.InfoLevel, template, fields))
 }
 
 func Debug(ctx context.Context, template string, fields ...zapcore.Field) {
-	loggerChannel <- &logItem{level: zapcore.DebugLevel, template: template, fields: fields}
+	enqueue(newItem(ctx, zapcore.DebugLevel, template, fields))
 }
 
 func Warn(ctx context.Context, template string, fields ...zapcore.Field) {
-	loggerChannel <- &logItem{level: zapcore.WarnLevel, template: template, fields: fields}
+	enqueue(newItem(ctx, zapcore.WarnLevel, template, fields))
 }
 
+// Fatal writes the queued entries and the fatal line before exiting the
+// process with status 1
 func Fatal(ctx context.Context, template string, fields ...zapcore.Field) {
-	loggerChannel <- &logItem{level: zapcore.FatalLevel, template: template, fields: fields}
+	item := newItem(ctx, zapcore.FatalLevel, template, fields)
+	flush()
+	logByLevel(item.level, item.template, item.fields...)
 }
 
+// Panic writes the queued entries and the panic line before panicking in
+// the calling goroutine
 func Panic(ctx context.Context, template string, fields ...zapcore.Field) {
-	_, src, line, ok := runtime.Caller(1)
+	item := newItem(ctx, zapcore.PanicLevel, template, fields)
+	flush()
+	logByLevel(item.level, item.template, item.fields...)
+}
+
+// newItem builds an entry with the request scoped values of ctx and the
+// caller of the exported logging function
+func newItem(ctx context.Context, level zapcore.Level, template string,
+	fields []zapcore.Field) *logItem {
+
+	fields = withContextFields(ctx, fields)
+	_, src, line, ok := runtime.Caller(2)
 	if ok {
-		fields = append(fields, zapcore.Field{Key: "caller", Type: zapcore.StringType, String: fmt.Sprintf("%s:%d", src, line)})
+		fields = append(fields, zap.String("caller", fmt.Sprintf("%s:%d", src, line)))
 	}
-	loggerChannel <- &logItem{level: zapcore.PanicLevel, template: template, fields: fields}
+	return &logItem{level: level, template: template, fields: fields}
+}
+
+// withContextFields prepends the request scoped values of ctx, so that all
+// lines of a request can be correlated
+func withContextFields(ctx context.Context, fields []zapcore.Field) []zapcore.Field {
+	if ctx == nil {
+		return fields
+	}
+
+	contextFields := make([]zapcore.Field, 0, len(fields)+5)
+	if requestID := reqctx.RequestID(ctx); requestID != "" {
+		contextFields = append(contextFields, zap.String("request_id", requestID))
+	}
+	if route := reqctx.Route(ctx); route != "" {
+		contextFields = append(contextFields, zap.String("route", route))
+	}
+	if userID := reqctx.UserID(ctx); userID != "" {
+		contextFields = append(contextFields, zap.String("user_id", userID))
+	}
+	if traceID := reqctx.TraceID(ctx); traceID != "" {
+		contextFields = append(contextFields, zap.String("trace_id", traceID))
+	}
+	if tenantID := reqctx.TenantID(ctx); tenantID != "" {
+		contextFields = append(contextFields, zap.String("tenant_id", tenantID))
+	}
+	if len(contextFields) == 0 {
+		return fields
+	}
+
+	return append(contextFields, fields...)
 }
 
 func logByLevel(level zapcore.Level, template string, fields ...zapcore.Field) {
+	logger := logger.Load()
+	if logger == nil {
+		// not configured yet, fatal and panic must still take effect
+		logger = fallbackLogger
+	}
+
 	switch level {
 	case zapcore.DebugLevel:
 		logger.Debug(template, fields...)
@@ -123,3 +235,21 @@ func logByLevel(level zapcore.Level, template string, fields ...zapcore.Field) {
 		logger.Fatal(template, fields...)
 	}
 }
+
+// QueueDepth returns the number of log entries waiting to be written
+func QueueDepth() int {
+	return len(loggerChannel)
+}
+
+// Healthy returns an error while the logger is not initialised or its
+// channel is full under the block policy, in which case callers are
+// blocked on logging
+func Healthy() error {
+	if logger.Load() == nil {
+		return errors.New("logger not initialised")
+	}
+	if OverflowPolicy() == OverflowBlock && len(loggerChannel) >= cap(loggerChannel) {
+		return errors.New("logger queue is full")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package zaplogger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Overflow policies applied when the log channel is full
+const (
+	// OverflowBlock makes the caller wait for room in the channel
+	OverflowBlock = "block"
+	// OverflowDropOldest discards the oldest queued entry to make room
+	OverflowDropOldest = "drop_oldest"
+	// OverflowDropNewest discards the entry being logged
+	OverflowDropNewest = "drop_newest"
+)
+
+var (
+	overflowPolicy atomic.Value
+	dropped        atomic.Uint64
+	// closed is set by Close, entries are then written synchronously
+	closed atomic.Bool
+)
+
+func init() {
+	overflowPolicy.Store(OverflowBlock)
+}
+
+type logItem struct {
+	template string
+	level    zapcore.Level
+	fields   []zapcore.Field
+	// flushed is closed by the drain goroutine once every entry queued
+	// before this marker has been written, it carries no log line
+	flushed chan struct{}
+}
+
+// SetOverflowPolicy changes the policy applied when the log channel is full
+func SetOverflowPolicy(policy string) error {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	switch policy {
+	case "":
+		policy = OverflowBlock
+	case OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+	default:
+		return fmt.Errorf("unknown log overflow policy %q", policy)
+	}
+	overflowPolicy.Store(policy)
+	return nil
+}
+
+// OverflowPolicy returns the policy applied when the log channel is full
+func OverflowPolicy() string {
+	return overflowPolicy.Load().(string)
+}
+
+// Dropped returns the number of entries discarded by the overflow policy
+func Dropped() uint64 {
+	return dropped.Load()
+}
+
+// enqueue hands an entry to the drain goroutine according to the overflow
+// policy. After Close the entry is written by the caller.
+func enqueue(item *logItem) {
+	if closed.Load() {
+		logByLevel(item.level, item.template, item.fields...)
+		return
+	}
+
+	switch OverflowPolicy() {
+	case OverflowDropNewest:
+		select {
+		case loggerChannel <- item:
+		default:
+			dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case loggerChannel <- item:
+				return
+			default:
+			}
+			// make room, another sender may win the slot so retry
+			select {
+			case oldest := <-loggerChannel:
+				if oldest.flushed != nil {
+					// never drop a flush marker, the entry taken before it
+					// may still be written. Queued again it only waits for
+					// the entries queued since as well.
+					loggerChannel <- oldest
+					continue
+				}
+				dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		loggerChannel <- item
+	}
+}
+
+// drain writes the queued entries until the channel is closed
+func drain() {
+	for item := range loggerChannel {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		logByLevel(item.level, item.template, item.fields...)
+	}
+}
+
+// flush waits until every entry queued so far has been written
+func flush() {
+	if closed.Load() || logger.Load() == nil {
+		return
+	}
+	marker := &logItem{flushed: make(chan struct{})}
+	// the marker is never dropped whatever the overflow policy
+	loggerChannel <- marker
+	<-marker.flushed
+}
+
+/*
+Sync : writes the queued entries and flushes the buffered output of the
+logger. Call it before the process exits.
+*/
+func Sync() error {
+	flush()
+	if l := logger.Load(); l != nil {
+		return l.Sync()
+	}
+	return nil
+}
+
+/*
+Close : flushes the logger and switches it to synchronous writes, so that
+lines logged while the process winds down are not lost. It is safe to call
+more than once.
+*/
+func Close() error {
+	err := Sync()
+	closed.Store(true)
+	return err
+}
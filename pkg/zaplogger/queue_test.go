@@ -0,0 +1,96 @@
+package zaplogger
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestOverflowPolicy(t *testing.T) {
+	testCases := []struct {
+		name            string
+		policy          string
+		expectedDropped uint64
+		expectedFirst   string
+	}{
+		{
+			name:            "Drop newest keeps the queued entries",
+			policy:          OverflowDropNewest,
+			expectedDropped: 2,
+			expectedFirst:   "entry 0",
+		},
+		{
+			name:            "Drop oldest keeps the latest entries",
+			policy:          OverflowDropOldest,
+			expectedDropped: 2,
+			expectedFirst:   "entry 2",
+		},
+	}
+
+	// the drain goroutine is only started at the end, so the channel fills up
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger.Store(zap.New(core))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, SetOverflowPolicy(tc.policy))
+			dropped.Store(0)
+			for len(loggerChannel) > 0 {
+				<-loggerChannel
+			}
+
+			for i := 0; i < cap(loggerChannel)+2; i++ {
+				Info(context.Background(), "entry "+strconv.Itoa(i))
+			}
+
+			assert.Equal(t, tc.expectedDropped, Dropped())
+			assert.Equal(t, cap(loggerChannel), QueueDepth())
+			assert.Equal(t, tc.expectedFirst, (<-loggerChannel).template)
+		})
+	}
+
+	// a flush marker at the head is queued again instead of being dropped
+	dropped.Store(0)
+	for len(loggerChannel) > 0 {
+		<-loggerChannel
+	}
+	marker := &logItem{flushed: make(chan struct{})}
+	loggerChannel <- marker
+	for i := 0; i < cap(loggerChannel); i++ {
+		Info(context.Background(), "entry "+strconv.Itoa(i))
+	}
+	select {
+	case <-marker.flushed:
+		t.Error("flush marker released before its entries were written")
+	default:
+	}
+	assert.Equal(t, uint64(1), Dropped())
+	queued := false
+	for len(loggerChannel) > 0 {
+		item := <-loggerChannel
+		queued = queued || item == marker
+		assert.NotEqual(t, "entry 0", item.template)
+	}
+	assert.True(t, queued)
+
+	// Sync writes every queued entry with its caller
+	drainOnce.Do(func() {
+		go drain()
+	})
+	assert.NoError(t, SetOverflowPolicy(OverflowBlock))
+	logs.TakeAll()
+	Warn(context.Background(), "last entry")
+	assert.NoError(t, Sync())
+	assert.Equal(t, 0, QueueDepth())
+
+	entries := logs.FilterMessage("last entry").All()
+	assert.Len(t, entries, 1)
+	assert.Contains(t, entries[0].ContextMap()["caller"], "queue_test.go")
+
+	assert.Error(t, SetOverflowPolicy("sometimes"))
+}